@@ -0,0 +1,91 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Response is a response whose JSON body was decoded.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Decode unmarshals the body into v.
+func (r Response) Decode(v any) error {
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		return fmt.Errorf("decode %s: %w", r.Body, err)
+	}
+
+	return nil
+}
+
+// Do sends a request with body encoded as JSON when not nil, header is added
+// to the request.
+func Do(ctx context.Context, method, url string, body any, header http.Header) (Response, error) {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return Response{}, fmt.Errorf("encode body: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return Response{}, fmt.Errorf("create request: %w", err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("%s %s: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("read body: %w", err)
+	}
+
+	return Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}, nil
+}
+
+// Eventually calls check until it returns nil and fails t with the last error
+// when timeout elapses first, for the state the projection reaches
+// asynchronously.
+func Eventually(t *testing.T, timeout time.Duration, check func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met after %s: %v", timeout, err)
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
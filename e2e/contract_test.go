@@ -0,0 +1,96 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shapeOf maps the fields of a JSON object to their JSON type.
+func shapeOf(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	var object map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(data, &object))
+
+	shape := make(map[string]string, len(object))
+
+	for field, value := range object {
+		switch value[0] {
+		case '{':
+			shape[field] = "object"
+		case '[':
+			shape[field] = "array"
+		case '"':
+			shape[field] = "string"
+		case 't', 'f':
+			shape[field] = "boolean"
+		case 'n':
+			shape[field] = "null"
+		default:
+			shape[field] = "number"
+		}
+	}
+
+	return shape
+}
+
+// latestFixture returns the golden payload of the newest version of subject.
+func latestFixture(t *testing.T, subject string) []byte {
+	t.Helper()
+
+	dir := filepath.Join(repoRoot(), "contracts", "events", subject)
+
+	for version := 1; ; version++ {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("v%d.json", version+1))); err == nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("v%d.json", version)))
+		assert.NoError(t, err)
+
+		return data
+	}
+}
+
+// The fake listing-service stands in for listing-service/main.py, which has
+// no tests, so its event must keep the shape of the listing.created fixture.
+func TestFakeListingService_EmitsContract(t *testing.T) {
+	form := url.Values{"user_id": {"42"}, "listing_type": {"sale"}, "price": {"350000"}}
+
+	resp, err := http.Post(harness.ListingServiceURL+"/listings", "application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx := context.Background()
+
+	streamName, err := harness.JS.StreamNameBySubject(ctx, listingCreatedSubject)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream, err := harness.JS.Stream(ctx, streamName)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	last, err := stream.GetLastMsgForSubject(ctx, listingCreatedSubject)
+	if assert.NoError(t, err) {
+		assert.Equal(t, shapeOf(t, latestFixture(t, listingCreatedSubject)), shapeOf(t, last.Data))
+	}
+}
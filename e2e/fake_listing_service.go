@@ -0,0 +1,141 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	listingCreatedSubject  = "listing.created"
+	transactionIDHeader    = "X-Transaction-Id"
+	consistencyTokenHeader = "X-Consistency-Token"
+)
+
+// Listing is a listing of the fake listing-service, serialized like
+// listing-service/main.py does.
+type Listing struct {
+	ID          int64  `json:"id"`
+	UserID      int64  `json:"user_id"`
+	ListingType string `json:"listing_type"`
+	Price       int64  `json:"price"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// FakeListingService stands in for the Python listing-service: POST
+// /listings validates the form like it, keeps the listing in memory and
+// publishes listing.created to JetStream with the transaction ID header, the
+// ack sequence is returned as consistency token.
+type FakeListingService struct {
+	js jetstream.JetStream
+
+	mu       sync.Mutex
+	listings []Listing
+}
+
+func NewFakeListingService(js jetstream.JetStream) *FakeListingService {
+	return &FakeListingService{js: js}
+}
+
+// Listings returns the listings created so far.
+func (s *FakeListingService) Listings() []Listing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Listing(nil), s.listings...)
+}
+
+func (s *FakeListingService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/listings/ping":
+		fmt.Fprint(w, "pong!")
+	case r.URL.Path == "/listings" && r.Method == http.MethodPost:
+		s.createListing(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *FakeListingService) createListing(w http.ResponseWriter, r *http.Request) {
+	var errs []string
+
+	userID, err := strconv.ParseInt(r.FormValue("user_id"), 10, 64)
+	if err != nil {
+		errs = append(errs, "invalid user_id")
+	}
+
+	listingType := r.FormValue("listing_type")
+	if listingType != "rent" && listingType != "sale" {
+		errs = append(errs, "invalid listing_type. Supported values: 'rent', 'sale'")
+	}
+
+	price, err := strconv.ParseInt(r.FormValue("price"), 10, 64)
+	if err != nil {
+		errs = append(errs, "invalid price. Must be an integer")
+	} else if price < 1 {
+		errs = append(errs, "price must be greater than 0")
+	}
+
+	if len(errs) > 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"result": false, "errors": errs})
+
+		return
+	}
+
+	now := time.Now().UnixMicro()
+
+	s.mu.Lock()
+	listing := Listing{
+		ID:          int64(len(s.listings) + 1),
+		UserID:      userID,
+		ListingType: listingType,
+		Price:       price,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.listings = append(s.listings, listing)
+	s.mu.Unlock()
+
+	// like main.py, a failed publication is logged and the listing returned
+	// without consistency token
+	if token, err := s.publish(r.Context(), listing, r.Header.Get(transactionIDHeader)); err == nil {
+		w.Header().Set(consistencyTokenHeader, token)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"result": true, "listing": listing})
+}
+
+func (s *FakeListingService) publish(ctx context.Context, listing Listing, requestID string) (string, error) {
+	data, err := json.Marshal(listing)
+	if err != nil {
+		return "", fmt.Errorf("encode listing: %w", err)
+	}
+
+	msg := nats.NewMsg(listingCreatedSubject)
+	msg.Data = data
+
+	if requestID != "" {
+		msg.Header.Set(transactionIDHeader, requestID)
+	}
+
+	ack, err := s.js.PublishMsg(ctx, msg)
+	if err != nil {
+		return "", fmt.Errorf("publish %s: %w", listingCreatedSubject, err)
+	}
+
+	return fmt.Sprintf("%s:%d", listingCreatedSubject, ack.Sequence), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body) //nolint:errcheck,errchkjson
+}
@@ -0,0 +1,159 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const projectionTimeout = 10 * time.Second
+
+var harness *Harness
+
+func TestMain(m *testing.M) {
+	var err error
+
+	harness, err = Start(context.Background())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	harness.Stop()
+	os.Exit(code)
+}
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type listing struct {
+	ID          int64  `json:"id"`
+	UserID      int64  `json:"user_id"`
+	ListingType string `json:"listing_type"`
+	Price       int64  `json:"price"`
+	User        *user  `json:"user"`
+}
+
+func createUser(t *testing.T, name string) (user, string) {
+	t.Helper()
+
+	resp, err := Do(context.Background(), http.MethodPost, harness.GatewayURL+"/public/users",
+		map[string]any{"name": name}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(resp.Body))
+
+	var body struct {
+		User user `json:"user"`
+	}
+	assert.NoError(t, resp.Decode(&body))
+
+	return body.User, resp.Header.Get("X-Consistency-Token")
+}
+
+func createListing(t *testing.T, userID int64, header http.Header) (listing, string) {
+	t.Helper()
+
+	resp, err := Do(context.Background(), http.MethodPost, harness.GatewayURL+"/public/listings",
+		map[string]any{"user_id": userID, "listing_type": "rent", "price": 2500}, header)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(resp.Body))
+
+	var body struct {
+		Listing listing `json:"listing"`
+	}
+	assert.NoError(t, resp.Decode(&body))
+
+	return body.Listing, resp.Header.Get("X-Consistency-Token")
+}
+
+func getListings(userID int64, header http.Header) ([]listing, Response, error) {
+	resp, err := Do(context.Background(), http.MethodGet,
+		fmt.Sprintf("%s/public/listings?user_id=%d", harness.GatewayURL, userID), nil, header)
+	if err != nil {
+		return nil, Response{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp, fmt.Errorf("status %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	var body struct {
+		Listings []listing `json:"listings"`
+	}
+
+	if err := resp.Decode(&body); err != nil {
+		return nil, resp, err
+	}
+
+	return body.Listings, resp, nil
+}
+
+func TestCreateUserAndListing_Projected(t *testing.T) {
+	created, _ := createUser(t, "Jane Doe")
+	assert.NotZero(t, created.ID)
+
+	header := http.Header{"X-Transaction-Id": []string{"e2e-create-listing"}}
+
+	createdListing, token := createListing(t, created.ID, header)
+	assert.Equal(t, created.ID, createdListing.UserID)
+	assert.Regexp(t, `^listing\.created:\d+$`, token)
+
+	// the fake listing-service publishes the request ID like main.py
+	ctx := context.Background()
+
+	streamName, err := harness.JS.StreamNameBySubject(ctx, "listing.created")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream, err := harness.JS.Stream(ctx, streamName)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	last, err := stream.GetLastMsgForSubject(ctx, "listing.created")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "e2e-create-listing", last.Header.Get("X-Transaction-Id"))
+	}
+
+	Eventually(t, projectionTimeout, func() error {
+		listings, _, err := getListings(created.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		if len(listings) != 1 || listings[0].User == nil {
+			return fmt.Errorf("listings not projected yet: %+v", listings)
+		}
+
+		assert.Equal(t, createdListing.ID, listings[0].ID)
+		assert.Equal(t, "Jane Doe", listings[0].User.Name)
+
+		return nil
+	})
+}
+
+func TestCreateListing_ReadYourWrites(t *testing.T) {
+	created, userToken := createUser(t, "John Doe")
+
+	_, listingToken := createListing(t, created.ID, nil)
+
+	// the gateway waits for the projection to reach both writes
+	listings, resp, err := getListings(created.ID, http.Header{
+		"X-Consistency-Token": []string{userToken, listingToken},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Header.Get("X-Consistency-Stale"))
+	assert.Len(t, listings, 1)
+}
@@ -0,0 +1,350 @@
+// Package e2e boots the Go services in one process against an embedded NATS
+// server, with the listing-service replaced by FakeListingService, to test the
+// flows crossing the services.
+//
+// The services keep their state in memory and listen on random ports. Their
+// commands are package globals, so a test binary boots them once, from
+// TestMain.
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	gatewayapp "github.com/ijalalfrz/event-driven-nats/gateway-service/cmd/app"
+	listingviewapp "github.com/ijalalfrz/event-driven-nats/listing-view-service/cmd/app"
+	userapp "github.com/ijalalfrz/event-driven-nats/user-service/cmd/app"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	startTimeout = 20 * time.Second
+	stopTimeout  = 10 * time.Second
+	pollInterval = 50 * time.Millisecond
+)
+
+var errNotReady = errors.New("service not ready")
+
+// Harness is the running system.
+type Harness struct {
+	GatewayURL        string
+	UserServiceURL    string
+	ListingViewURL    string
+	ListingServiceURL string
+
+	NATS     *server.Server
+	JS       jetstream.JetStream
+	Listings *FakeListingService
+
+	dir            string
+	nc             *nats.Conn
+	listingService *httptest.Server
+	cancel         context.CancelFunc
+	done           []chan error
+}
+
+// service is a Go service booted by its http command.
+type service struct {
+	name      string
+	run       func(ctx context.Context, args ...string) error
+	overrides map[string]string
+}
+
+// Start boots NATS, the fake listing-service, the listing-view-service, the
+// user-service and the gateway, in this order, each one once ready. The
+// services stop when ctx is done or on Stop.
+func Start(ctx context.Context) (*Harness, error) {
+	dir, err := os.MkdirTemp("", "e2e")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+
+	h := &Harness{dir: dir}
+
+	if err := h.start(ctx); err != nil {
+		h.Stop()
+
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (h *Harness) start(ctx context.Context) error {
+	if err := h.startNATS(); err != nil {
+		return err
+	}
+
+	h.Listings = NewFakeListingService(h.JS)
+	h.listingService = httptest.NewServer(h.Listings)
+	h.ListingServiceURL = h.listingService.URL
+
+	ctx, h.cancel = context.WithCancel(ctx)
+
+	listingViewPort, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	userPort, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	gatewayPort, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	h.ListingViewURL = fmt.Sprintf("http://127.0.0.1:%d", listingViewPort)
+	h.UserServiceURL = fmt.Sprintf("http://127.0.0.1:%d", userPort)
+	h.GatewayURL = fmt.Sprintf("http://127.0.0.1:%d", gatewayPort)
+
+	services := []service{
+		{
+			name: "listing-view-service",
+			run:  listingviewapp.Run,
+			overrides: map[string]string{
+				"HTTP_PORT": fmt.Sprint(listingViewPort),
+				"STORAGE":   "memory",
+			},
+		},
+		{
+			name: "user-service",
+			run:  userapp.Run,
+			overrides: map[string]string{
+				"HTTP_PORT": fmt.Sprint(userPort),
+				"STORAGE":   "memory",
+			},
+		},
+		{
+			name: "gateway-service",
+			run:  gatewayapp.Run,
+			overrides: map[string]string{
+				"HTTP_PORT":                fmt.Sprint(gatewayPort),
+				"USER_SERVICE_URL":         h.UserServiceURL,
+				"LISTING_SERVICE_URL":      h.ListingServiceURL,
+				"LISTING_VIEW_SERVICE_URL": h.ListingViewURL,
+				"CACHE_ENABLED":            "false",
+			},
+		},
+	}
+
+	urls := []string{h.ListingViewURL, h.UserServiceURL, h.GatewayURL}
+
+	for i, svc := range services {
+		if err := h.startService(ctx, svc, urls[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *Harness) startNATS() error {
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  filepath.Join(h.dir, "jetstream"),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		return fmt.Errorf("create NATS server: %w", err)
+	}
+
+	go ns.Start()
+
+	h.NATS = ns
+
+	if !ns.ReadyForConnections(startTimeout) {
+		return fmt.Errorf("NATS server: %w", errNotReady)
+	}
+
+	h.nc, err = nats.Connect(ns.ClientURL())
+	if err != nil {
+		return fmt.Errorf("connect to NATS: %w", err)
+	}
+
+	h.JS, err = jetstream.New(h.nc)
+	if err != nil {
+		return fmt.Errorf("create JetStream context: %w", err)
+	}
+
+	return nil
+}
+
+// startService runs the http command of svc with an env file derived from its
+// .env.sample and waits until url reports ready.
+func (h *Harness) startService(ctx context.Context, svc service, url string) error {
+	root := filepath.Join(repoRoot(), svc.name)
+
+	overrides := map[string]string{
+		"NATS_URL":              h.NATS.ClientURL(),
+		"LOG_LEVEL":             "error",
+		"METRICS_ENABLED":       "false",
+		"PPROF_ENABLED":         "false",
+		"TRACING_ENABLED":       "false",
+		"HEALTH_SHUTDOWN_DELAY": "0s",
+		"LOCALES_BASE_PATH":     filepath.Join(root, "resources", "locales"),
+	}
+
+	for key, value := range svc.overrides {
+		overrides[key] = value
+	}
+
+	envFile := filepath.Join(h.dir, svc.name+".env")
+
+	if err := writeEnvFile(envFile, filepath.Join(root, ".env.sample"), overrides); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	h.done = append(h.done, done)
+
+	go func() {
+		done <- svc.run(ctx, "-c", envFile, "http")
+	}()
+
+	if err := waitReady(ctx, url, done); err != nil {
+		return fmt.Errorf("start %s: %w", svc.name, err)
+	}
+
+	return nil
+}
+
+// Stop stops the services, the fake listing-service and NATS.
+func (h *Harness) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+
+	for _, done := range h.done {
+		select {
+		case <-done:
+		case <-time.After(stopTimeout):
+		}
+	}
+
+	if h.listingService != nil {
+		h.listingService.Close()
+	}
+
+	if h.nc != nil {
+		h.nc.Close()
+	}
+
+	if h.NATS != nil {
+		h.NATS.Shutdown()
+		h.NATS.WaitForShutdown()
+	}
+
+	os.RemoveAll(h.dir)
+}
+
+// waitReady polls the readiness probe at url until it responds 200, the
+// service returns or the start timeout elapses.
+func waitReady(ctx context.Context, url string, done <-chan error) error {
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/health/ready", nil)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case err := <-done:
+			return fmt.Errorf("service exited: %w", errors.Join(errNotReady, err))
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", errNotReady, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeEnvFile writes the env file at sample to path with the values of
+// overrides, the keys missing from sample are appended.
+func writeEnvFile(path, sample string, overrides map[string]string) error {
+	file, err := os.Open(sample)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", sample, err)
+	}
+	defer file.Close()
+
+	var (
+		lines    []string
+		replaced = make(map[string]bool, len(overrides))
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		key, _, ok := strings.Cut(line, "=")
+		if value, override := overrides[strings.TrimSpace(key)]; ok && override {
+			line = fmt.Sprintf("%s=%q", strings.TrimSpace(key), value)
+			replaced[strings.TrimSpace(key)] = true
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read %s: %w", sample, err)
+	}
+
+	for key, value := range overrides {
+		if !replaced[key] {
+			lines = append(lines, fmt.Sprintf("%s=%q", key, value))
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("find free port: %w", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// repoRoot returns the directory of the services.
+func repoRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+
+	return filepath.Dir(filepath.Dir(file))
+}
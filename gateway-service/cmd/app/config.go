@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/configcheck"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the config of the service",
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the config and print the effective values with the secrets masked",
+	Long: "Validate the config file and the environment, like the service does when it starts.\n" +
+		"Every problem is listed at once, the keys matching no setting are warned about,\n" +
+		"then the effective config is printed with the secrets masked.",
+	Example:      "  app config check -c .env",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, report, err := config.Load(cfgFilePath)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		for _, problem := range report.Errors {
+			fmt.Fprintln(cmd.ErrOrStderr(), "error:", problem)
+		}
+
+		for _, problem := range report.Warnings {
+			fmt.Fprintln(cmd.ErrOrStderr(), "warning:", problem)
+		}
+
+		for _, entry := range configcheck.Dump(cfg) {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", entry.Key, entry.Value)
+		}
+
+		if len(report.Errors) > 0 {
+			return fmt.Errorf("%w, %d problem(s)", configcheck.ErrInvalid, len(report.Errors))
+		}
+
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	configCmd.AddCommand(configCheckCmd)
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,31 +14,48 @@ import (
 	"time"
 
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/endpoint"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/router"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/service"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/cache"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/health"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/lang"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/logger"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/metrics"
+	natstransport "github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/transport/nats"
+	"github.com/nats-io/nats.go"
 	"github.com/spf13/cobra"
 )
 
 var timeout = 30 * time.Second
 
+var errNATSUnavailable = errors.New("not connected to NATS since startup")
+
+var (
+	listingCreatedSubject = "listing.created"
+)
+
 var httpServerCmd = &cobra.Command{
 	Use:   "http",
 	Short: "Serve incoming requests from REST HTTP/JSON API",
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
 		slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
 		cfg := config.MustInitConfig(cfgFilePath)
 
 		logger.InitStructuredLogger(cfg.LogLevel)
+		reloadLogLevel()
 
-		runHTTPServer(cfg)
+		shutdownTracing := initTracing(cfg)
+		defer shutdownTracing()
+
+		runHTTPServer(cmd.Context(), cfg)
 	},
 }
 
-func runHTTPServer(cfg config.Config) {
-	ctx, cancel := context.WithCancel(context.Background())
+// runHTTPServer serves until a signal is received or parent is done.
+func runHTTPServer(parent context.Context, cfg config.Config) {
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 
 	var waitGroup sync.WaitGroup
@@ -54,13 +72,16 @@ func runHTTPServer(cfg config.Config) {
 
 	sigChannel := make(chan os.Signal, 1)
 	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigChannel)
 
 	select {
 	case sig := <-sigChannel:
 		cancel()
 		slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
 	case <-ctx.Done():
-		slog.ErrorContext(ctx, "failed to start HTTP server")
+		if parent.Err() == nil {
+			slog.ErrorContext(ctx, "failed to start HTTP server")
+		}
 	}
 
 	waitGroup.Wait()
@@ -78,12 +99,12 @@ func startHTTPServer(ctx context.Context, cfg config.Config) {
 		startMainApp(ctx, cfg)
 	}()
 
-	if cfg.HTTP.PprofEnabled {
+	if cfg.HTTP.PprofEnabled || cfg.Metrics.Enabled {
 		waitGroup.Add(1)
 
 		go func() {
 			defer waitGroup.Done()
-			startPprof(ctx, cfg)
+			startInternalServer(ctx, cfg, nil)
 		}()
 	}
 
@@ -94,11 +115,45 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 	lang.SetSupportedLanguages(cfg.Locales.SupportedLanguages)
 	lang.SetBasePath(cfg.Locales.BasePath)
 
-	endpts := makeEndpoints(cfg)
+	listingCache := makeListingCache(cfg)
+
+	natsConn, err := connectNATS(cfg)
+	if err != nil {
+		// cached listings still expire by TTL and listings are created
+		// synchronously, so serve without NATS
+		slog.Error("failed to connect to NATS", slog.String("error", err.Error()))
+	} else {
+		defer natsConn.Close()
+	}
+
+	var bus *commandBus
+
+	if natsConn != nil {
+		bus, err = makeCommandBus(ctx, cfg, natsConn)
+		if err != nil {
+			slog.Error("failed to set up command bus, async commands are disabled",
+				slog.String("error", err.Error()))
+		}
+	}
+
+	endpts := makeEndpoints(cfg, listingCache, bus)
+
+	if listingCache != nil && natsConn != nil {
+		stop, err := startCacheInvalidation(ctx, natsConn, cfg.NATS.HandlerTimeThreshold, endpts.ListingCache)
+		if err != nil {
+			// entries still expire by TTL, so serve without early invalidation
+			slog.Error("failed to start listing cache invalidation", slog.String("error", err.Error()))
+		} else {
+			defer stop()
+		}
+	}
+
+	checks := makeHealthChecks(cfg, natsConn, bus)
 
 	router := router.MakeHTTPRouter(
 		endpts,
 		cfg,
+		checks,
 	)
 
 	server := &http.Server{
@@ -118,6 +173,8 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 
 	<-ctx.Done()
 
+	drainReadiness(checks, cfg.Health.ShutdownDelay)
+
 	// shutdown ctx
 	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -129,32 +186,121 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 	slog.Info("HTTP server gracefully stopped")
 }
 
-func makeEndpoints(cfg config.Config) endpoint.Endpoint {
-	// init all service clients
-	userServiceClient := service.NewUserServiceClient(cfg.UserService.URL,
-		service.WithMaxRetries(cfg.UserService.MaxRetry),
-		service.WithTimeout(cfg.UserService.Timeout),
+// makeHealthChecks registers every dependency as non critical, the gateway
+// still serves part of its API without any of them.
+func makeHealthChecks(cfg config.Config, natsConn *nats.Conn, bus *commandBus) *health.Health {
+	checks := health.New(cfg.Health.CheckTimeout)
+
+	if natsConn != nil {
+		checks.RegisterNonCritical("nats", health.NATSChecker(natsConn))
+	} else {
+		checks.RegisterNonCritical("nats", health.CheckerFunc(func(context.Context) error {
+			return errNATSUnavailable
+		}))
+	}
+
+	if bus != nil {
+		checks.RegisterNonCritical("jetstream", health.JetStreamChecker(bus.js))
+	}
+
+	// the checks are bounded by the health check timeout
+	client := &http.Client{}
+
+	checks.RegisterNonCritical("user-service", health.HTTPChecker(client, cfg.UserService.URL+"/health"))
+	checks.RegisterNonCritical("listing-service", health.HTTPChecker(client, cfg.ListingService.URL+"/health"))
+	checks.RegisterNonCritical("listing-view-service",
+		health.HTTPChecker(client, cfg.ListingViewService.URL+"/health"))
+
+	return checks
+}
+
+func makeListingCache(cfg config.Config) *cache.Cache[dto.GetAllListingsResponse] {
+	if !cfg.Cache.Enabled {
+		return nil
+	}
+
+	listingCache := cache.New(cfg.Cache.TTL,
+		cache.WithStaleTTL[dto.GetAllListingsResponse](cfg.Cache.StaleTTL),
+		cache.WithMaxEntries[dto.GetAllListingsResponse](cfg.Cache.MaxEntries),
 	)
-	listingViewServiceClient := service.NewListingViewServiceClient(cfg.ListingViewService.URL,
-		service.WithMaxRetries(cfg.ListingViewService.MaxRetry),
-		service.WithTimeout(cfg.ListingViewService.Timeout),
+
+	cache.RegisterMetrics(metrics.Default, "listing_cache", listingCache)
+
+	return listingCache
+}
+
+// startCacheInvalidation subscribes to the events that make cached listing
+// pages outdated and returns a function that closes the subscriptions.
+func startCacheInvalidation(ctx context.Context, natsConn *nats.Conn,
+	handlerTimeThreshold time.Duration, endpts endpoint.ListingCache,
+) (func(), error) {
+	listingCreatedSubscriber := natstransport.NewSubscriber(
+		natsConn,
+		listingCreatedSubject,
+		handlerTimeThreshold,
+		endpts.OnListingCreated,
+		natstransport.NewDecoder[dto.ListingCreated](),
 	)
-	listingServiceClient := service.NewListingServiceClient(cfg.ListingService.URL,
-		service.WithMaxRetries(cfg.ListingService.MaxRetry),
-		service.WithTimeout(cfg.ListingService.Timeout),
+
+	if err := listingCreatedSubscriber.Start(ctx); err != nil {
+		return nil, fmt.Errorf("subscribe %s: %w", listingCreatedSubject, err)
+	}
+
+	return listingCreatedSubscriber.Stop, nil
+}
+
+// makeEndpoints wires the HTTP endpoints, bus is optional and disables async
+// commands when nil.
+func makeEndpoints(cfg config.Config,
+	listingCache *cache.Cache[dto.GetAllListingsResponse],
+	bus *commandBus,
+) endpoint.Endpoint {
+	userServiceClient, listingViewServiceClient, listingServiceClient := makeServiceClients(cfg)
+
+	projectionWaiter := service.NewProjectionWaiter(listingViewServiceClient,
+		cfg.Consistency.WaitTimeout, cfg.Consistency.PollInterval)
+
+	var (
+		operationSvc *service.OperationService
+		// keep the interface nil, not a typed nil, when async commands are disabled
+		listingCommandSvc endpoint.ListingCommandService
 	)
 
-	return endpoint.Endpoint{
-		PublicUser: makePublicUserEndpoints(userServiceClient),
+	if bus != nil {
+		operationSvc = service.NewOperationService(bus.operationRepository, bus.publisher,
+			cfg.Operation.PendingTimeout)
+		listingCommandSvc = operationSvc
+	}
+
+	endpts := endpoint.Endpoint{
+		PublicUser: makePublicUserEndpoints(userServiceClient, listingViewServiceClient,
+			projectionWaiter),
 		PublicListing: makePublicListingEndpoints(listingViewServiceClient,
-			listingServiceClient, userServiceClient),
+			listingServiceClient, userServiceClient, listingCache, projectionWaiter,
+			listingCommandSvc),
+		LogLevel: endpoint.NewLogLevelEndpoint(service.NewLogLevelService(logger.DefaultLevel)),
+		Audit:    endpoint.NewAuditEndpoint(service.NewAuditService(userServiceClient)),
 	}
+
+	if operationSvc != nil {
+		endpts.Operation = endpoint.NewOperationEndpoint(operationSvc)
+	}
+
+	if listingCache != nil {
+		endpts.ListingCache = endpoint.NewListingCacheEndpoint(
+			service.NewListingCacheInvalidator(listingCache))
+	}
+
+	return endpts
 }
 
 func makePublicUserEndpoints(
 	userServiceClient *service.UserServiceClient,
+	listingViewServiceClient *service.ListingViewServiceClient,
+	projectionWaiter *service.ProjectionWaiter,
 ) endpoint.PublicUser {
-	userSvc := service.NewPublicUserService(userServiceClient)
+	userSvc := service.NewPublicUserService(userServiceClient, listingViewServiceClient,
+		projectionWaiter)
 
 	return endpoint.NewPublicUserEndpoint(userSvc)
 }
@@ -163,21 +309,81 @@ func makePublicListingEndpoints(
 	listingViewServiceClient *service.ListingViewServiceClient,
 	listingServiceClient *service.ListingServiceClient,
 	userServiceClient *service.UserServiceClient,
+	listingCache *cache.Cache[dto.GetAllListingsResponse],
+	projectionWaiter *service.ProjectionWaiter,
+	listingCommandSvc endpoint.ListingCommandService,
 ) endpoint.PublicListing {
 	listingSvc := service.NewPublicListingService(listingViewServiceClient,
-		listingServiceClient, userServiceClient)
+		listingServiceClient, userServiceClient, listingCache, projectionWaiter)
+
+	return endpoint.NewPublicListingEndpoint(listingSvc, listingCommandSvc)
+}
+
+func makeServiceClients(cfg config.Config) (
+	*service.UserServiceClient,
+	*service.ListingViewServiceClient,
+	*service.ListingServiceClient,
+) {
+	userServiceClient := service.NewUserServiceClient(cfg.UserService.URL, cfg.UserService.AdminToken,
+		service.WithMaxRetries(cfg.UserService.MaxRetry),
+		service.WithTimeout(cfg.UserService.Timeout),
+	)
+	listingViewServiceClient := service.NewListingViewServiceClient(cfg.ListingViewService.URL,
+		service.WithMaxRetries(cfg.ListingViewService.MaxRetry),
+		service.WithTimeout(cfg.ListingViewService.Timeout),
+	)
+	listingServiceClient := service.NewListingServiceClient(cfg.ListingService.URL,
+		service.WithMaxRetries(cfg.ListingService.MaxRetry),
+		service.WithTimeout(cfg.ListingService.Timeout),
+	)
 
-	return endpoint.NewPublicListingEndpoint(listingSvc)
+	// timeouts, retries and the admin token follow the config file without
+	// restart
+	config.OnChange(func(cfg config.Config) {
+		userServiceClient.Configure(
+			service.WithMaxRetries(cfg.UserService.MaxRetry),
+			service.WithTimeout(cfg.UserService.Timeout),
+		)
+		userServiceClient.SetAdminToken(cfg.UserService.AdminToken)
+		listingViewServiceClient.Configure(
+			service.WithMaxRetries(cfg.ListingViewService.MaxRetry),
+			service.WithTimeout(cfg.ListingViewService.Timeout),
+		)
+		listingServiceClient.Configure(
+			service.WithMaxRetries(cfg.ListingService.MaxRetry),
+			service.WithTimeout(cfg.ListingService.Timeout),
+		)
+	})
+
+	return userServiceClient, listingViewServiceClient, listingServiceClient
 }
 
-func startPprof(ctx context.Context, cfg config.Config) {
-	// manually register pprof handlers with custom path.
-	http.HandleFunc("/internal/pprof/", pprof.Index)
-	http.HandleFunc("/internal/pprof/cmdline", pprof.Cmdline)
-	http.HandleFunc("/internal/pprof/profile", pprof.Profile)
-	http.HandleFunc("/internal/pprof/symbol", pprof.Symbol)
-	http.HandleFunc("/internal/pprof/trace", pprof.Trace)
-	slog.Info("running pprof...", slog.Int("port", cfg.HTTP.PprofPort))
+// startInternalServer serves pprof, the Prometheus metrics and, when routes is
+// not nil, the routes of a process without public HTTP server on the internal
+// port.
+func startInternalServer(ctx context.Context, cfg config.Config, routes http.Handler) {
+	if cfg.HTTP.PprofEnabled {
+		// manually register pprof handlers with custom path.
+		http.HandleFunc("/internal/pprof/", pprof.Index)
+		http.HandleFunc("/internal/pprof/cmdline", pprof.Cmdline)
+		http.HandleFunc("/internal/pprof/profile", pprof.Profile)
+		http.HandleFunc("/internal/pprof/symbol", pprof.Symbol)
+		http.HandleFunc("/internal/pprof/trace", pprof.Trace)
+	}
+
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Addr != "" {
+			go startMetricsServer(ctx, cfg)
+		} else {
+			http.Handle("/metrics", metrics.Default.Handler())
+		}
+	}
+
+	if routes != nil {
+		http.Handle("/", routes)
+	}
+
+	slog.Info("running internal server...", slog.Int("port", cfg.HTTP.PprofPort))
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf("localhost:%d", cfg.HTTP.PprofPort),
@@ -187,7 +393,7 @@ func startPprof(ctx context.Context, cfg config.Config) {
 	// limit access by binding port to localhost only.
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("pprof server error", slog.String("error", err.Error()))
+			slog.Error("internal server error", slog.String("error", err.Error()))
 		}
 	}()
 
@@ -197,8 +403,41 @@ func startPprof(ctx context.Context, cfg config.Config) {
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		slog.Error("failed to shutdown pprof server", slog.String("error", err.Error()))
+		slog.Error("failed to shutdown internal server", slog.String("error", err.Error()))
+	}
+
+	slog.Info("internal server stopped")
+}
+
+// startMetricsServer serves the Prometheus metrics on METRICS_ADDR, unlike
+// the internal port it is reachable from other hosts, pprof isn't served.
+func startMetricsServer(ctx context.Context, cfg config.Config) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+
+	slog.Info("running metrics server...", slog.String("addr", cfg.Metrics.Addr))
+
+	server := &http.Server{
+		Addr:              cfg.Metrics.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.HTTP.Timeout,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server error", slog.String("error", err.Error()))
+		}
+	}()
+
+	<-ctx.Done()
+
+	// ctx is done, the shutdown gets its own deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shutdown metrics server", slog.String("error", err.Error()))
 	}
 
-	slog.Info("pprof server stopped")
+	slog.Info("metrics server stopped")
 }
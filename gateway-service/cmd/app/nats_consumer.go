@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/repository"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/router"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/service"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/health"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/lang"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/logger"
+	natstransport "github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/transport/nats"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/spf13/cobra"
+)
+
+var (
+	commandStreamName       = "gateway_command"
+	commandSubjects         = "command.>"
+	operationBucket         = "operations"
+	createListingConsumer   = "gateway_create_listing"
+	commandResultConsumer   = "gateway_command_result"
+	createListingMaxDeliver = 1
+	commandResultMaxDeliver = 5
+)
+
+var natsConsumerCmd = &cobra.Command{
+	Use:   "consumer",
+	Short: "Execute async commands from NATS",
+	Run: func(_ *cobra.Command, _ []string) {
+		slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
+		cfg := config.MustInitConfig(cfgFilePath)
+
+		logger.InitStructuredLogger(cfg.LogLevel)
+		reloadLogLevel()
+
+		shutdownTracing := initTracing(cfg)
+		defer shutdownTracing()
+
+		runNATS(cfg)
+	},
+}
+
+// commandBus holds what both the HTTP server and the command worker need to
+// exchange async commands.
+type commandBus struct {
+	js                  jetstream.JetStream
+	stream              jetstream.Stream
+	operationRepository *repository.OperationRepository
+	publisher           *natstransport.Publisher
+}
+
+func connectNATS(cfg config.Config) (*nats.Conn, error) {
+	natsConn, err := natstransport.Connect(natstransport.ConnOptions{
+		URL:           cfg.NATS.URL,
+		Name:          "gateway-service",
+		MaxReconnects: cfg.NATS.MaxReconnects,
+		ReconnectWait: cfg.NATS.ReconnectWait,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+
+	return natsConn, nil
+}
+
+// makeCommandBus creates the command stream and the operation status bucket
+// if they don't exist yet.
+func makeCommandBus(ctx context.Context, cfg config.Config, natsConn *nats.Conn) (*commandBus, error) {
+	js, err := jetstream.New(natsConn)
+	if err != nil {
+		return nil, fmt.Errorf("create NATS JetStream: %w", err)
+	}
+
+	// commands and results are removed once acked, the operation bucket keeps
+	// the outcome
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      commandStreamName,
+		Subjects:  []string{commandSubjects},
+		Retention: jetstream.WorkQueuePolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create stream: %w", err)
+	}
+
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket: operationBucket,
+		TTL:    cfg.Operation.TTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create key value bucket: %w", err)
+	}
+
+	return &commandBus{
+		js:                  js,
+		stream:              stream,
+		operationRepository: repository.NewOperationRepository(kv),
+		publisher:           natstransport.NewPublisher(js, natstransport.JSONEncoder),
+	}, nil
+}
+
+func runNATS(cfg config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lang.SetSupportedLanguages(cfg.Locales.SupportedLanguages)
+	lang.SetBasePath(cfg.Locales.BasePath)
+
+	natsConn, err := connectNATS(cfg)
+	if err != nil {
+		slog.Error("failed to connect to NATS", "error", err)
+
+		return
+	}
+	defer natsConn.Close()
+
+	bus, err := makeCommandBus(ctx, cfg, natsConn)
+	if err != nil {
+		slog.Error("failed to set up command bus", "error", err)
+
+		return
+	}
+
+	endpoints := makeCommandEndpoints(cfg, bus)
+
+	checks := health.New(cfg.Health.CheckTimeout)
+	checks.Register("nats", health.NATSChecker(natsConn))
+	checks.Register("jetstream", health.JetStreamChecker(bus.js))
+
+	// consumers have no HTTP server, the internal port serves their probes
+	// and metrics
+	go startInternalServer(ctx, cfg, router.MakeInternalRouter(checks))
+
+	// creating a listing is not idempotent, a command is never redelivered
+	createListingCommandConsumer, err := natstransport.NewConsumer(
+		ctx,
+		bus.stream,
+		createListingConsumer,
+		model.CreateListingCommand,
+		createListingMaxDeliver,
+		cfg.NATS.HandlerTimeThreshold,
+		endpoints.Command.CreateListing,
+		natstransport.NewDecoder[dto.CreateListingCommand](),
+	)
+	if err != nil {
+		slog.Error("failed to create create listing command consumer", "error", err)
+
+		return
+	}
+
+	commandResultEventConsumer, err := natstransport.NewConsumer(
+		ctx,
+		bus.stream,
+		commandResultConsumer,
+		model.CommandResultEvent,
+		commandResultMaxDeliver,
+		cfg.NATS.HandlerTimeThreshold,
+		endpoints.Operation.OnResult,
+		natstransport.NewDecoder[dto.CommandResult](),
+	)
+	if err != nil {
+		slog.Error("failed to create command result consumer", "error", err)
+
+		return
+	}
+
+	if err := createListingCommandConsumer.Start(ctx); err != nil {
+		slog.Error("failed to start create listing command consumer", "error", err)
+
+		return
+	}
+	defer createListingCommandConsumer.Stop()
+
+	if err := commandResultEventConsumer.Start(ctx); err != nil {
+		slog.Error("failed to start command result consumer", "error", err)
+
+		return
+	}
+	defer commandResultEventConsumer.Stop()
+
+	sigChannel := make(chan os.Signal, 1)
+	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+
+	select {
+	case sig := <-sigChannel:
+		cancel()
+		slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
+	case <-ctx.Done():
+		slog.Info("context done. Exiting...", "error", ctx.Err())
+	}
+
+	checks.Shutdown()
+
+	slog.Info("nats consumer stopped")
+}
+
+func makeCommandEndpoints(cfg config.Config, bus *commandBus) endpoint.Endpoint {
+	userServiceClient, listingViewServiceClient, listingServiceClient := makeServiceClients(cfg)
+
+	// the worker only creates listings, so neither cache nor projection waiter
+	listingSvc := service.NewPublicListingService(listingViewServiceClient,
+		listingServiceClient, userServiceClient, nil, nil)
+	commandSvc := service.NewCommandService(listingSvc, bus.operationRepository, bus.publisher)
+	operationSvc := service.NewOperationService(bus.operationRepository, bus.publisher,
+		cfg.Operation.PendingTimeout)
+
+	return endpoint.Endpoint{
+		Command:   endpoint.NewCommandEndpoint(commandSvc),
+		Operation: endpoint.NewOperationEndpoint(operationSvc),
+	}
+}
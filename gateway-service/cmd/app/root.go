@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"log/slog"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -18,11 +20,23 @@ func init() { //nolint:gochecknoinits
 	rootCmd.PersistentFlags().StringVarP(&cfgFilePath, "config", "c", ".env", "")
 	rootCmd.AddCommand(
 		httpServerCmd,
+		natsConsumerCmd,
+		seedCmd,
+		configCmd,
 	)
 }
 
+// Run executes the command line args until ctx is done, for the tests booting
+// the service in process.
+func Run(ctx context.Context, args ...string) error {
+	rootCmd.SetArgs(args)
+
+	return rootCmd.ExecuteContext(ctx) //nolint:wrapcheck
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		slog.Error("error executing root command", slog.String("error", err.Error()))
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/service"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// seedActor is the actor of the seeded writes in the audit trail.
+	seedActor = "seed"
+	// seedMaxRetries is the number of attempts of every gateway request.
+	seedMaxRetries = 3
+
+	defaultSeedUsers           = 10
+	defaultSeedListingsPerUser = 3
+	defaultSeedConcurrency     = 4
+)
+
+// Where the seed command creates the data.
+const (
+	seedViaAPI     = "api"
+	seedViaService = "service"
+)
+
+var (
+	seedUsers            int
+	seedListingsPerUser  int
+	seedRandom           uint64
+	seedConcurrency      int
+	seedMaxFailures      int
+	seedVia              string
+	seedGatewayURL       string
+	seedWait             time.Duration
+	seedProgressInterval time.Duration
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Create generated users and listings for demos and load tests",
+	Long: "Create generated users with listings, with realistic names and prices per listing type.\n" +
+		"The same --seed generates the same data. The data goes through the public API of a running\n" +
+		"gateway, or with --via=service through the gateway service layer straight to the services.",
+	Example: "  app seed --users 100 --listings 5 --seed 42 --wait 30s\n" +
+		"  app seed --via service --users 1000 --concurrency 16",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg := config.MustInitConfig(cfgFilePath)
+		logger.InitStructuredLogger(cfg.LogLevel)
+
+		target, err := makeSeedTarget(cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ctx = dto.ContextWithActor(dto.ContextWithRequestID(ctx, dto.NewRequestID()), seedActor)
+
+		out := cmd.OutOrStdout()
+		data := service.GenerateSeedData(seedRandom, seedUsers, seedListingsPerUser)
+		seeder := service.NewSeedService(target, seedConcurrency, seedMaxFailures, seedProgressInterval)
+
+		fmt.Fprintf(out, "seeding %d users with %d listings each via %s\n", seedUsers, seedListingsPerUser, seedVia)
+
+		result, err := seeder.Seed(ctx, data, func(progress service.SeedProgress) {
+			fmt.Fprintf(out, "%d users, %d listings, %d failed in %s, %.1f/s\n", progress.Users,
+				progress.Listings, progress.Failed, progress.Elapsed.Round(time.Millisecond), progress.Rate())
+		})
+		if err != nil {
+			return fmt.Errorf("seed: %w", err)
+		}
+
+		if seedWait <= 0 {
+			return nil
+		}
+
+		return waitForSeedProjection(ctx, cmd, cfg, result.Tokens)
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	seedCmd.Flags().IntVar(&seedUsers, "users", defaultSeedUsers, "number of users to create")
+	seedCmd.Flags().IntVar(&seedListingsPerUser, "listings", defaultSeedListingsPerUser,
+		"number of listings of every user")
+	seedCmd.Flags().Uint64Var(&seedRandom, "seed", 1, "seed of the generated data")
+	seedCmd.Flags().IntVar(&seedConcurrency, "concurrency", defaultSeedConcurrency,
+		"number of users created in parallel")
+	seedCmd.Flags().IntVar(&seedMaxFailures, "max-failures", 0, "failed requests tolerated before giving up")
+	seedCmd.Flags().StringVar(&seedVia, "via", seedViaAPI,
+		"create the data through the gateway public API (api) or its service layer (service)")
+	seedCmd.Flags().StringVar(&seedGatewayURL, "gateway-url", "",
+		"URL of the gateway with --via=api, http://localhost:HTTP_PORT by default")
+	seedCmd.Flags().DurationVar(&seedWait, "wait", 0,
+		"wait up to this long for listing-view to project the seeded data, 0 doesn't wait")
+	seedCmd.Flags().DurationVar(&seedProgressInterval, "progress-interval", time.Second,
+		"how often the progress is printed, 0 only prints it at the end")
+}
+
+func makeSeedTarget(cfg config.Config) (service.SeedTarget, error) {
+	switch seedVia {
+	case seedViaAPI:
+		gatewayURL := seedGatewayURL
+		if gatewayURL == "" {
+			gatewayURL = fmt.Sprintf("http://localhost:%d", cfg.HTTP.Port)
+		}
+
+		return service.NewPublicAPIClient(gatewayURL,
+			service.WithMaxRetries(seedMaxRetries),
+			service.WithTimeout(cfg.HTTP.Timeout),
+		), nil
+	case seedViaService:
+		userServiceClient, listingViewServiceClient, listingServiceClient := makeServiceClients(cfg)
+
+		return struct {
+			*service.PublicUserService
+			*service.PublicListingService
+		}{
+			service.NewPublicUserService(userServiceClient, listingViewServiceClient, nil),
+			service.NewPublicListingService(listingViewServiceClient, listingServiceClient,
+				userServiceClient, nil, nil),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown --via %q, want %s or %s", seedVia, seedViaAPI, seedViaService)
+	}
+}
+
+// waitForSeedProjection polls the listing-view offsets until they reach the
+// last seeded writes.
+func waitForSeedProjection(ctx context.Context, cmd *cobra.Command, cfg config.Config,
+	tokens []dto.ConsistencyToken,
+) error {
+	_, listingViewServiceClient, _ := makeServiceClients(cfg)
+	waiter := service.NewProjectionWaiter(listingViewServiceClient, seedWait, cfg.Consistency.PollInterval)
+
+	start := time.Now()
+
+	if !waiter.Wait(ctx, tokens) {
+		return fmt.Errorf("listing-view didn't project the seeded data within %s", seedWait)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "listing-view converged in %s\n", time.Since(start).Round(time.Millisecond))
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/tracing"
+)
+
+const (
+	serviceName            = "gateway-service"
+	tracingExportTimeout   = 10 * time.Second
+	tracingShutdownTimeout = 15 * time.Second
+)
+
+// initTracing installs the global tracer when tracing is enabled, the
+// returned function flushes the spans still queued.
+func initTracing(cfg config.Config) func() {
+	if !cfg.TracingEnabled {
+		return func() {}
+	}
+
+	exporter, err := makeSpanExporter(cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to create span exporter, tracing disabled", slog.String("error", err.Error()))
+
+		return func() {}
+	}
+
+	tracer := tracing.NewTracer(serviceName, exporter)
+	tracing.SetTracer(tracer)
+
+	slog.Info("tracing enabled", slog.String("exporter", cfg.Tracing.Exporter))
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := tracer.Shutdown(ctx); err != nil {
+			slog.Error("failed to shutdown tracer", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func makeSpanExporter(cfg config.Tracing) (tracing.Exporter, error) {
+	switch cfg.Exporter {
+	case tracing.ExporterStdout, "":
+		return tracing.NewWriterExporter(os.Stdout) //nolint:wrapcheck
+	case tracing.ExporterFile:
+		exporter, err := tracing.NewFileExporter(cfg.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("create file exporter: %w", err)
+		}
+
+		return exporter, nil
+	case tracing.ExporterOTLP:
+		return tracing.NewOTLPExporter(context.Background(), cfg.OTLPEndpoint, tracingExportTimeout) //nolint:wrapcheck
+	default:
+		return nil, fmt.Errorf("unknown span exporter: %s", cfg.Exporter)
+	}
+}
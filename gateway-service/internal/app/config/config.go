@@ -4,6 +4,8 @@ package config
 import (
 	"log/slog"
 	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/configcheck"
 )
 
 type LogLeveler string
@@ -16,61 +18,171 @@ func (l LogLeveler) Level() slog.Level {
 	return level
 }
 
-// Config holds the server configuration.
+// reloadableKeys are the lower case keys applied without restart by the
+// OnChange listeners.
+var reloadableKeys = map[string]bool{
+	"log_level":                      true,
+	"allowed_origin":                 true,
+	"user_service_max_retry":         true,
+	"user_service_timeout":           true,
+	"listing_service_max_retry":      true,
+	"listing_service_timeout":        true,
+	"listing_view_service_max_retry": true,
+	"listing_view_service_timeout":   true,
+	"service_tokens":                 true,
+	"user_service_admin_token":       true,
+}
+
+// Config holds the server configuration. The validate tags are checked by
+// configcheck when it is loaded, secret:"true" masks a field when printed or
+// logged and lets it be read from the file named by its key suffixed by _FILE.
 type Config struct {
-	LogLevel             LogLeveler         `mapstructure:"LOG_LEVEL"`
-	ServiceTokens        string             `mapstructure:"SERVICE_TOKENS"`
+	LogLevel             LogLeveler         `mapstructure:"LOG_LEVEL" validate:"log_level"`
+	ServiceTokens        string             `mapstructure:"SERVICE_TOKENS" secret:"true"`
 	TracingEnabled       bool               `mapstructure:"TRACING_ENABLED"`
 	ProfilingEnabled     bool               `mapstructure:"PROFILING_ENABLED"`
-	RequestTimeThreshold time.Duration      `mapstructure:"REQUEST_TIME_THRESHOLD"`
+	RequestTimeThreshold time.Duration      `mapstructure:"REQUEST_TIME_THRESHOLD" validate:"gte=0"`
 	DB                   DB                 `mapstructure:",squash"`
 	HTTP                 HTTP               `mapstructure:",squash"`
+	Metrics              Metrics            `mapstructure:",squash"`
+	Tracing              Tracing            `mapstructure:",squash"`
+	Health               Health             `mapstructure:",squash"`
+	Logging              Logging            `mapstructure:",squash"`
 	HTTPCaller           HTTPCaller         `mapstructure:",squash"`
 	Locales              Locales            `mapstructure:",squash"`
 	UserService          UserService        `mapstructure:",squash"`
 	ListingService       ListingService     `mapstructure:",squash"`
 	ListingViewService   ListingViewService `mapstructure:",squash"`
+	NATS                 NATS               `mapstructure:",squash"`
+	Cache                Cache              `mapstructure:",squash"`
+	Consistency          Consistency        `mapstructure:",squash"`
+	Operation            Operation          `mapstructure:",squash"`
+}
+
+// LogValue logs the config with its secrets masked.
+func (c Config) LogValue() slog.Value {
+	return configcheck.LogValue(c)
+}
+
+// String formats the config with its secrets masked, so printing it with %v
+// doesn't leak them either.
+func (c Config) String() string {
+	return configcheck.String(c)
 }
 
 type UserService struct {
-	URL      string        `mapstructure:"USER_SERVICE_URL"`
-	MaxRetry int           `mapstructure:"USER_SERVICE_MAX_RETRY"`
-	Timeout  time.Duration `mapstructure:"USER_SERVICE_TIMEOUT"`
+	URL      string        `mapstructure:"USER_SERVICE_URL" validate:"required,http_url"`
+	MaxRetry int           `mapstructure:"USER_SERVICE_MAX_RETRY" validate:"min=1,max=10"`
+	Timeout  time.Duration `mapstructure:"USER_SERVICE_TIMEOUT" validate:"gte=1ms"`
+	// AdminToken is one of the SERVICE_TOKENS of user-service, sent on the
+	// proxied admin requests.
+	AdminToken string `mapstructure:"USER_SERVICE_ADMIN_TOKEN" secret:"true"`
 }
 
 type ListingService struct {
-	URL      string        `mapstructure:"LISTING_SERVICE_URL"`
-	MaxRetry int           `mapstructure:"LISTING_SERVICE_MAX_RETRY"`
-	Timeout  time.Duration `mapstructure:"LISTING_SERVICE_TIMEOUT"`
+	URL      string        `mapstructure:"LISTING_SERVICE_URL" validate:"required,http_url"`
+	MaxRetry int           `mapstructure:"LISTING_SERVICE_MAX_RETRY" validate:"min=1,max=10"`
+	Timeout  time.Duration `mapstructure:"LISTING_SERVICE_TIMEOUT" validate:"gte=1ms"`
 }
 
 type ListingViewService struct {
-	URL      string        `mapstructure:"LISTING_VIEW_SERVICE_URL"`
-	MaxRetry int           `mapstructure:"LISTING_VIEW_SERVICE_MAX_RETRY"`
-	Timeout  time.Duration `mapstructure:"LISTING_VIEW_SERVICE_TIMEOUT"`
+	URL      string        `mapstructure:"LISTING_VIEW_SERVICE_URL" validate:"required,http_url"`
+	MaxRetry int           `mapstructure:"LISTING_VIEW_SERVICE_MAX_RETRY" validate:"min=1,max=10"`
+	Timeout  time.Duration `mapstructure:"LISTING_VIEW_SERVICE_TIMEOUT" validate:"gte=1ms"`
 }
 
 type DB struct {
-	DSN                   string        `mapstructure:"DB_DSN"`
-	MaxOpenConnections    int           `mapstructure:"DB_MAX_OPEN_CONNECTIONS"`
-	MaxIdleConnections    int           `mapstructure:"DB_MAX_IDLE_CONNECTIONS"`
+	DSN                   string        `mapstructure:"DB_DSN" secret:"true"`
+	MaxOpenConnections    int           `mapstructure:"DB_MAX_OPEN_CONNECTIONS" validate:"gte=0"`
+	MaxIdleConnections    int           `mapstructure:"DB_MAX_IDLE_CONNECTIONS" validate:"gte=0"`
 	MaxConnectionLifetime time.Duration `mapstructure:"DB_MAX_CONNECTIONS_LIFETIME"`
 	MaxIdleConnectionTime time.Duration `mapstructure:"DB_MAX_IDLE_CONNECTIONS_TIME"`
 }
 
 type HTTP struct {
-	Port          int           `mapstructure:"HTTP_PORT"`
-	Timeout       time.Duration `mapstructure:"HTTP_TIMEOUT"`
+	Port          int           `mapstructure:"HTTP_PORT" validate:"min=1,max=65535"`
+	Timeout       time.Duration `mapstructure:"HTTP_TIMEOUT" validate:"omitempty,gte=1ms"`
 	PprofEnabled  bool          `mapstructure:"PPROF_ENABLED"`
-	PprofPort     int           `mapstructure:"PPROF_PORT"`
+	PprofPort     int           `mapstructure:"PPROF_PORT" validate:"gte=0,max=65535"`
 	AllowedOrigin []string      `mapstructure:"ALLOWED_ORIGIN"`
 }
 
+type Metrics struct {
+	// Enabled serves /metrics, on the internal (pprof) port unless Addr is set.
+	Enabled bool `mapstructure:"METRICS_ENABLED"`
+	// Addr is the address of a listener of its own for /metrics, like :9090
+	// for Prometheus to scrape it from another host.
+	Addr string `mapstructure:"METRICS_ADDR" validate:"omitempty,hostname_port"`
+}
+
+// Health configures the readiness probe.
+type Health struct {
+	CheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT" validate:"gte=1ms"`
+	// ShutdownDelay is how long the readiness probe fails before the server
+	// stops accepting requests, so load balancers drain the traffic first.
+	ShutdownDelay time.Duration `mapstructure:"HEALTH_SHUTDOWN_DELAY" validate:"gte=0"`
+}
+
+// Logging configures the request and response bodies logged by the HTTP
+// logging middleware.
+type Logging struct {
+	// BodyLimit is the number of bytes of a body logged before truncating it.
+	BodyLimit int `mapstructure:"LOG_BODY_LIMIT" validate:"gte=0"`
+	// RedactFields are the dot separated paths of the fields masked in the
+	// logged bodies, * matches any key or array element.
+	RedactFields []string `mapstructure:"LOG_REDACT_FIELDS"`
+	// ContentTypes are the media types whose bodies are logged, empty logs
+	// all of them.
+	ContentTypes []string `mapstructure:"LOG_CONTENT_TYPES"`
+	// SuccessSampleRate is the fraction of the successful requests logged,
+	// error responses are always logged.
+	SuccessSampleRate float64 `mapstructure:"LOG_SUCCESS_SAMPLE_RATE" validate:"gte=0,lte=1"`
+}
+
+// Tracing configures where spans go once TRACING_ENABLED is set.
+type Tracing struct {
+	// Exporter is one of stdout, file or otlp.
+	Exporter string `mapstructure:"TRACING_EXPORTER" validate:"omitempty,oneof=stdout file otlp"`
+	// FilePath is the JSON lines file of the file exporter.
+	FilePath string `mapstructure:"TRACING_FILE_PATH"`
+	// OTLPEndpoint is the OTLP/HTTP traces URL of the otlp exporter.
+	OTLPEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT" validate:"omitempty,url"`
+}
+
 type HTTPCaller struct {
 	Timeout time.Duration `mapstructure:"HTTP_CALLER_TIMEOUT"`
 }
 
 type Locales struct {
-	BasePath           string `mapstructure:"LOCALES_BASE_PATH"`
-	SupportedLanguages string `mapstructure:"LOCALES_SUPPORTED_LANGUAGES"`
+	BasePath           string `mapstructure:"LOCALES_BASE_PATH" validate:"required"`
+	SupportedLanguages string `mapstructure:"LOCALES_SUPPORTED_LANGUAGES" validate:"required"`
+}
+
+type NATS struct {
+	URL           string        `mapstructure:"NATS_URL" validate:"required,urls"`
+	MaxReconnects int           `mapstructure:"NATS_MAX_RECONNECTS" validate:"gte=-1"`
+	ReconnectWait time.Duration `mapstructure:"NATS_RECONNECT_WAIT" validate:"gte=0"`
+	// HandlerTimeThreshold logs message handlers slower than it, zero
+	// disables it.
+	HandlerTimeThreshold time.Duration `mapstructure:"NATS_HANDLER_TIME_THRESHOLD" validate:"gte=0"`
+}
+
+type Cache struct {
+	Enabled    bool          `mapstructure:"CACHE_ENABLED"`
+	TTL        time.Duration `mapstructure:"CACHE_TTL" validate:"required_if=Enabled true,gte=0"`
+	StaleTTL   time.Duration `mapstructure:"CACHE_STALE_TTL" validate:"gte=0"`
+	MaxEntries int           `mapstructure:"CACHE_MAX_ENTRIES" validate:"required_if=Enabled true,gte=0"`
+}
+
+type Consistency struct {
+	WaitTimeout  time.Duration `mapstructure:"CONSISTENCY_WAIT_TIMEOUT" validate:"gte=0"`
+	PollInterval time.Duration `mapstructure:"CONSISTENCY_POLL_INTERVAL" validate:"gte=0"`
+}
+
+type Operation struct {
+	// TTL is how long the status of an async operation can be polled.
+	TTL time.Duration `mapstructure:"OPERATION_TTL" validate:"gte=0"`
+	// PendingTimeout is how long an operation can stay pending before it is
+	// reported as failed, zero disables it.
+	PendingTimeout time.Duration `mapstructure:"OPERATION_PENDING_TIMEOUT" validate:"gte=0"`
 }
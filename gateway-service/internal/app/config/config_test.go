@@ -4,6 +4,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -14,6 +15,11 @@ func TestLoadConfig(t *testing.T) {
 
 		assert.Equal(t, LogLeveler("info"), config.LogLevel)
 		assert.Equal(t, false, config.TracingEnabled)
+		assert.Equal(t, "stdout", config.Tracing.Exporter)
+		assert.Equal(t, 2*time.Second, config.Health.CheckTimeout)
+		assert.Equal(t, 65536, config.Logging.BodyLimit)
+		assert.Equal(t, []string{"name", "user.name", "users.*.name", "listing.user.name", "listings.*.user.name"}, config.Logging.RedactFields)
+		assert.Equal(t, 1.0, config.Logging.SuccessSampleRate)
 		assert.Equal(t, 3001, config.HTTP.Port)
 		assert.Equal(t, false, config.HTTP.PprofEnabled)
 		assert.Equal(t, 3002, config.HTTP.PprofPort)
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/configcheck"
+	"github.com/spf13/viper"
+)
+
+var (
+	listenersMu sync.Mutex
+	listeners   []func(Config)
+)
+
+// OnChange registers fn to be called with the new config when one of the
+// reloadableKeys changes in the config file, fn must only apply those keys.
+func OnChange(fn func(Config)) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	listeners = append(listeners, fn)
+}
+
+func notify(cfg Config) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+}
+
+// watcher reloads the config file when it or a secret file changes, the keys
+// that can't be applied at runtime are only reported.
+type watcher struct {
+	vpr *viper.Viper
+
+	mu       sync.Mutex
+	settings map[string]any
+	files    *fsnotify.Watcher
+}
+
+func watch(vpr *viper.Viper, cfg Config) {
+	w := &watcher{
+		vpr:      vpr,
+		settings: settingsOf(vpr, cfg),
+	}
+
+	vpr.OnConfigChange(func(_ fsnotify.Event) {
+		w.reload()
+	})
+	vpr.WatchConfig()
+
+	w.watchSecretFiles()
+}
+
+// watchSecretFiles reloads the config when a file in the directory of a
+// secret file changes, the directory is watched because Kubernetes updates a
+// secret by swapping a symlink.
+func (w *watcher) watchSecretFiles() {
+	files, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("cannot watch the secret files", slog.String("error", err.Error()))
+
+		return
+	}
+
+	w.files = files
+	w.addSecretFiles()
+
+	go func() {
+		for {
+			select {
+			case _, ok := <-files.Events:
+				if !ok {
+					return
+				}
+
+				w.reload()
+			case err, ok := <-files.Errors:
+				if !ok {
+					return
+				}
+
+				slog.Error("secret files watcher error", slog.String("error", err.Error()))
+			}
+		}
+	}()
+}
+
+// addSecretFiles watches the directories of the secret files named by the
+// config, adding a directory already watched does nothing.
+func (w *watcher) addSecretFiles() {
+	if w.files == nil {
+		return
+	}
+
+	for _, file := range configcheck.SecretFiles(Config{}, w.vpr.GetString) {
+		if err := w.files.Add(filepath.Dir(file)); err != nil {
+			slog.Error("cannot watch the secret file", slog.String("file", file), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (w *watcher) reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg, report := decode(w.vpr)
+	settings := settingsOf(w.vpr, cfg)
+
+	changed := changedKeys(w.settings, settings)
+	if len(changed) == 0 {
+		return
+	}
+
+	w.addSecretFiles()
+
+	if err := report.Err(); err != nil {
+		slog.Error("invalid changed config file, keeping the current config",
+			slog.String("error", err.Error()))
+
+		return
+	}
+
+	w.settings = settings
+
+	var reloaded, restart []string
+
+	for _, key := range changed {
+		if reloadableKeys[key] {
+			reloaded = append(reloaded, strings.ToUpper(key))
+		} else {
+			restart = append(restart, strings.ToUpper(key))
+		}
+	}
+
+	if len(restart) > 0 {
+		slog.Warn("config changed, restart to apply", slog.Any("keys", restart))
+	}
+
+	if len(reloaded) > 0 {
+		slog.Info("config changed, reloading", slog.Any("keys", reloaded))
+		notify(cfg)
+	}
+}
+
+// settingsOf returns the settings of vpr with the secrets of cfg, so that a
+// rotated secret file changes them.
+func settingsOf(vpr *viper.Viper, cfg Config) map[string]any {
+	settings := vpr.AllSettings()
+
+	for key, value := range configcheck.Secrets(cfg) {
+		settings[strings.ToLower(key)] = value
+	}
+
+	return settings
+}
+
+// changedKeys returns the sorted keys added, removed or changed in next.
+func changedKeys(prev, next map[string]any) []string {
+	var keys []string
+
+	for key, value := range next {
+		if old, ok := prev[key]; !ok || fmt.Sprint(old) != fmt.Sprint(value) {
+			keys = append(keys, key)
+		}
+	}
+
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
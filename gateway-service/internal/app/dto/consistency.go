@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ConsistencyTokenHeader carries the position of a write in the event
+	// stream, clients send it back on reads to see their own writes.
+	ConsistencyTokenHeader = "X-Consistency-Token"
+	// ConsistencyStaleHeader flags a read served before the projection caught
+	// up with the requested consistency tokens.
+	ConsistencyStaleHeader = "X-Consistency-Stale"
+)
+
+// ConsistencyToken is a write position formatted as <subject>:<stream sequence>.
+type ConsistencyToken struct {
+	Subject  string
+	Sequence uint64
+}
+
+func (t ConsistencyToken) String() string {
+	return fmt.Sprintf("%s:%d", t.Subject, t.Sequence)
+}
+
+func ParseConsistencyToken(value string) (ConsistencyToken, error) {
+	idx := strings.LastIndex(value, ":")
+	if idx <= 0 {
+		return ConsistencyToken{}, fmt.Errorf("invalid consistency token %q", value)
+	}
+
+	sequence, err := strconv.ParseUint(value[idx+1:], 10, 64)
+	if err != nil {
+		return ConsistencyToken{}, fmt.Errorf("invalid consistency token %q: %w", value, err)
+	}
+
+	return ConsistencyToken{Subject: value[:idx], Sequence: sequence}, nil
+}
+
+// consistencyTokensFromRequest parses every token of the request, the header
+// may be repeated or hold a comma separated list.
+func consistencyTokensFromRequest(req *http.Request) ([]ConsistencyToken, error) {
+	var tokens []ConsistencyToken
+
+	for _, header := range req.Header.Values(ConsistencyTokenHeader) {
+		for _, value := range strings.Split(header, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			token, err := ParseConsistencyToken(value)
+			if err != nil {
+				return nil, NewInvalidRequestError(err)
+			}
+
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// GetProjectionOffsetsResponse has the offset of every subject and the recent
+// sequences below it that listing-view failed to apply.
+type GetProjectionOffsetsResponse struct {
+	Result  bool                `json:"result"`
+	Offsets map[string]uint64   `json:"offsets"`
+	Failed  map[string][]uint64 `json:"failed,omitempty"`
+}
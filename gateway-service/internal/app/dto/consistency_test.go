@@ -0,0 +1,42 @@
+//go:build unit
+
+package dto
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConsistencyToken(t *testing.T) {
+	token, err := ParseConsistencyToken("listing.created:42")
+	assert.NoError(t, err)
+	assert.Equal(t, ConsistencyToken{Subject: "listing.created", Sequence: 42}, token)
+	assert.Equal(t, "listing.created:42", token.String())
+
+	for _, value := range []string{"listing.created", ":42", "listing.created:abc", "listing.created:-1"} {
+		_, err := ParseConsistencyToken(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestGetAllListingsRequest_BindConsistencyTokens(t *testing.T) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/public/listings", nil)
+	assert.NoError(t, err)
+
+	req.Header.Add(ConsistencyTokenHeader, "user.created:3, listing.created:7")
+	req.Header.Add(ConsistencyTokenHeader, "listing.created:8")
+
+	var request GetAllListingsRequest
+	assert.NoError(t, request.Bind(req))
+	assert.Equal(t, []ConsistencyToken{
+		{Subject: "user.created", Sequence: 3},
+		{Subject: "listing.created", Sequence: 7},
+		{Subject: "listing.created", Sequence: 8},
+	}, request.ConsistencyTokens)
+
+	req.Header.Set(ConsistencyTokenHeader, "garbage")
+	assert.Error(t, request.Bind(req))
+}
@@ -6,10 +6,18 @@ import (
 	"strconv"
 )
 
+// Listing types accepted by listing-service.
+const (
+	ListingTypeRent = "rent"
+	ListingTypeSale = "sale"
+)
+
 type CreateListingRequest struct {
 	UserID      int64  `json:"user_id" validate:"required"`
 	ListingType string `json:"listing_type" validate:"required,oneof=rent sale"`
 	Price       int64  `json:"price" validate:"required,min=1"`
+	// RespondAsync is set by the Prefer: respond-async header.
+	RespondAsync bool `json:"-"`
 }
 
 func (r *CreateListingRequest) Bind(req *http.Request) error {
@@ -17,11 +25,25 @@ func (r *CreateListingRequest) Bind(req *http.Request) error {
 		return NewInvalidRequestError(fmt.Errorf("invalid request: %w", err))
 	}
 
+	r.RespondAsync = prefersRespondAsync(req)
+
 	return nil
 }
 
 type CreateListingResponse struct {
-	ListingResponse `json:"listing"`
+	ListingResponse  `json:"listing"`
+	ConsistencyToken string `json:"-"`
+}
+
+// Headers implements kithttp.Headerer.
+func (r CreateListingResponse) Headers() http.Header {
+	headers := http.Header{}
+
+	if r.ConsistencyToken != "" {
+		headers.Set(ConsistencyTokenHeader, r.ConsistencyToken)
+	}
+
+	return headers
 }
 
 type ListingResponse struct {
@@ -35,9 +57,10 @@ type ListingResponse struct {
 }
 
 type GetAllListingsRequest struct {
-	PageNumber int    `json:"page_number" validate:"required,min=1"`
-	PageSize   int    `json:"page_size" validate:"required,min=1"`
-	UserID     *int64 `json:"user_id" validate:"required"`
+	PageNumber        int                `json:"page_number" validate:"required,min=1"`
+	PageSize          int                `json:"page_size" validate:"required,min=1"`
+	UserID            *int64             `json:"user_id" validate:"required"`
+	ConsistencyTokens []ConsistencyToken `json:"-"`
 }
 
 func (r *GetAllListingsRequest) Bind(req *http.Request) error {
@@ -73,10 +96,69 @@ func (r *GetAllListingsRequest) Bind(req *http.Request) error {
 		r.UserID = &userID
 	}
 
+	r.ConsistencyTokens, err = consistencyTokensFromRequest(req)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// CacheKey normalizes the request into a response cache key.
+func (r GetAllListingsRequest) CacheKey() string {
+	user := "all"
+	if r.UserID != nil {
+		user = strconv.FormatInt(*r.UserID, 10)
+	}
+
+	return fmt.Sprintf("listings:user=%s:page=%d:size=%d", user, r.PageNumber, r.PageSize)
+}
+
+// CacheTags returns the invalidation tags of the cached response.
+func (r GetAllListingsRequest) CacheTags() []string {
+	if r.UserID == nil {
+		return []string{ListingCacheTagAll}
+	}
+
+	return []string{ListingCacheTagUser(*r.UserID)}
+}
+
+// ListingCacheTagAll tags the unfiltered listing pages, any listing or user
+// change can show up in them.
+const ListingCacheTagAll = "listings:all"
+
+func ListingCacheTagUser(userID int64) string {
+	return fmt.Sprintf("listings:user=%d", userID)
+}
+
 type GetAllListingsResponse struct {
-	Result   bool              `json:"result"`
-	Listings []ListingResponse `json:"listings"`
+	Result      bool              `json:"result"`
+	Listings    []ListingResponse `json:"listings"`
+	CacheStatus string            `json:"-"`
+	Stale       bool              `json:"-"`
+}
+
+// Headers implements kithttp.Headerer.
+func (r GetAllListingsResponse) Headers() http.Header {
+	headers := http.Header{}
+
+	if r.CacheStatus != "" {
+		headers.Set("X-Cache", r.CacheStatus)
+	}
+
+	if r.Stale {
+		headers.Set(ConsistencyStaleHeader, "true")
+	}
+
+	return headers
+}
+
+// ListingCreated is the payload of the listing.created event.
+type ListingCreated struct {
+	ID          int64  `json:"id"`
+	UserID      int64  `json:"user_id"`
+	ListingType string `json:"listing_type"`
+	Price       int64  `json:"price"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
 }
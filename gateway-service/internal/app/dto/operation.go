@@ -0,0 +1,130 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// PreferHeader lets clients opt in to asynchronous processing (RFC 7240).
+	PreferHeader = "Prefer"
+	// PreferenceAppliedHeader tells the client which preference was honored.
+	PreferenceAppliedHeader = "Preference-Applied"
+	// PreferRespondAsync asks the gateway to accept the command and respond
+	// before it is executed.
+	PreferRespondAsync = "respond-async"
+)
+
+const (
+	OperationStatusPending   = "pending"
+	OperationStatusSucceeded = "succeeded"
+	OperationStatusFailed    = "failed"
+)
+
+// OperationTypeCreateListing is the type of async listing creations.
+const OperationTypeCreateListing = "listing.create"
+
+// OperationLocation is the status URL of an async operation.
+func OperationLocation(id string) string {
+	return fmt.Sprintf("/public/operations/%s", id)
+}
+
+// prefersRespondAsync reports whether the Prefer header of the request holds
+// the respond-async preference, other preferences are ignored.
+func prefersRespondAsync(req *http.Request) bool {
+	for _, header := range req.Header.Values(PreferHeader) {
+		for _, preference := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(token), PreferRespondAsync) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type OperationResponse struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Status string          `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	// ConsistencyToken lets the client read its own write once it succeeded.
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+	CreatedAt        int64  `json:"created_at"`
+	UpdatedAt        int64  `json:"updated_at"`
+}
+
+// OperationAcceptedResponse is returned when a command was queued instead of
+// executed, the client polls the Location header for the outcome.
+type OperationAcceptedResponse struct {
+	Result    bool              `json:"result"`
+	Operation OperationResponse `json:"operation"`
+}
+
+// StatusCode implements kithttp.StatusCoder.
+func (r OperationAcceptedResponse) StatusCode() int {
+	return http.StatusAccepted
+}
+
+// Headers implements kithttp.Headerer.
+func (r OperationAcceptedResponse) Headers() http.Header {
+	headers := http.Header{}
+	headers.Set("Location", OperationLocation(r.Operation.ID))
+	headers.Set(PreferenceAppliedHeader, PreferRespondAsync)
+
+	return headers
+}
+
+type GetOperationRequest struct {
+	ID string `json:"-" validate:"required"`
+}
+
+func (r *GetOperationRequest) Bind(req *http.Request) error {
+	r.ID = chi.URLParam(req, "id")
+
+	if err := validate.Struct(r); err != nil {
+		return NewInvalidRequestError(fmt.Errorf("invalid request: %w", err))
+	}
+
+	return nil
+}
+
+type GetOperationResponse struct {
+	Result    bool              `json:"result"`
+	Operation OperationResponse `json:"operation"`
+}
+
+// Headers implements kithttp.Headerer.
+func (r GetOperationResponse) Headers() http.Header {
+	headers := http.Header{}
+
+	if r.Operation.ConsistencyToken != "" {
+		headers.Set(ConsistencyTokenHeader, r.Operation.ConsistencyToken)
+	}
+
+	return headers
+}
+
+// CreateListingCommand is published on command.listing.create by async
+// listing creations.
+type CreateListingCommand struct {
+	OperationID string               `json:"operation_id"`
+	Language    string               `json:"language,omitempty"`
+	Listing     CreateListingRequest `json:"listing"`
+}
+
+// CommandResult is published on command.result once a command was executed.
+type CommandResult struct {
+	OperationID string          `json:"operation_id"`
+	Status      string          `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	// ConsistencyToken of the write done by the command, if any.
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package dto
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateListingRequest_BindRespondAsync(t *testing.T) {
+	testCases := []struct {
+		name   string
+		prefer []string
+		async  bool
+	}{
+		{name: "no preference", async: false},
+		{name: "respond-async", prefer: []string{"respond-async"}, async: true},
+		{name: "case insensitive with params", prefer: []string{"Respond-Async; foo=bar"}, async: true},
+		{name: "in a list", prefer: []string{"return=minimal, respond-async, wait=10"}, async: true},
+		{name: "repeated header", prefer: []string{"return=minimal", "respond-async"}, async: true},
+		{name: "other preference", prefer: []string{"return=representation"}, async: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/public/listings", nil)
+			assert.NoError(t, err)
+
+			for _, prefer := range testCase.prefer {
+				req.Header.Add(PreferHeader, prefer)
+			}
+
+			request := CreateListingRequest{UserID: 1, ListingType: "rent", Price: 100}
+			assert.NoError(t, request.Bind(req))
+			assert.Equal(t, testCase.async, request.RespondAsync)
+		})
+	}
+}
+
+func TestOperationAcceptedResponse_Headers(t *testing.T) {
+	response := OperationAcceptedResponse{Operation: OperationResponse{ID: "abc"}}
+
+	assert.Equal(t, http.StatusAccepted, response.StatusCode())
+	assert.Equal(t, "/public/operations/abc", response.Headers().Get("Location"))
+	assert.Equal(t, PreferRespondAsync, response.Headers().Get(PreferenceAppliedHeader))
+}
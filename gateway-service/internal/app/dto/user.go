@@ -3,6 +3,9 @@ package dto
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type CreateUserRequest struct {
@@ -18,7 +21,19 @@ func (r *CreateUserRequest) Bind(req *http.Request) error {
 }
 
 type CreateUserResponse struct {
-	UserResponse `json:"user"`
+	UserResponse     `json:"user"`
+	ConsistencyToken string `json:"-"`
+}
+
+// Headers implements kithttp.Headerer.
+func (r CreateUserResponse) Headers() http.Header {
+	headers := http.Header{}
+
+	if r.ConsistencyToken != "" {
+		headers.Set(ConsistencyTokenHeader, r.ConsistencyToken)
+	}
+
+	return headers
 }
 
 type UserResponse struct {
@@ -27,3 +42,90 @@ type UserResponse struct {
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
 }
+
+type GetUserRequest struct {
+	ID                int64              `json:"id" validate:"required,min=1"`
+	ConsistencyTokens []ConsistencyToken `json:"-"`
+}
+
+func (r *GetUserRequest) Bind(req *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(req, "id"), 10, 64)
+	if err != nil {
+		return NewInvalidRequestError(fmt.Errorf("invalid user id: %w", err))
+	}
+
+	r.ID = id
+
+	if err := validate.Struct(r); err != nil {
+		return NewInvalidRequestError(fmt.Errorf("invalid request: %w", err))
+	}
+
+	r.ConsistencyTokens, err = consistencyTokensFromRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetUserResponse is the public user profile, the user together with their
+// latest listings. ListingsUnavailable is set when the listings could not be
+// fetched and Listings is left empty.
+type GetUserResponse struct {
+	Result              bool              `json:"result"`
+	User                UserResponse      `json:"user"`
+	Listings            []ListingResponse `json:"listings"`
+	ListingsUnavailable bool              `json:"listings_unavailable"`
+	Stale               bool              `json:"-"`
+}
+
+// Headers implements kithttp.Headerer.
+func (r GetUserResponse) Headers() http.Header {
+	headers := http.Header{}
+
+	if r.Stale {
+		headers.Set(ConsistencyStaleHeader, "true")
+	}
+
+	return headers
+}
+
+type GetAllUsersRequest struct {
+	PageNumber int `json:"page_number" validate:"required,min=1"`
+	PageSize   int `json:"page_size" validate:"required,min=1"`
+}
+
+func (r *GetAllUsersRequest) Bind(req *http.Request) error {
+	var err error
+
+	pageNumberStr := req.URL.Query().Get("page_num")
+	pageSizeStr := req.URL.Query().Get("page_size")
+
+	if pageNumberStr == "" {
+		pageNumberStr = "1"
+	}
+	if pageSizeStr == "" {
+		pageSizeStr = "10"
+	}
+
+	r.PageNumber, err = strconv.Atoi(pageNumberStr)
+	if err != nil {
+		return NewInvalidRequestError(fmt.Errorf("invalid page number: %w", err))
+	}
+
+	r.PageSize, err = strconv.Atoi(pageSizeStr)
+	if err != nil {
+		return NewInvalidRequestError(fmt.Errorf("invalid page size: %w", err))
+	}
+
+	if err := validate.Struct(r); err != nil {
+		return NewInvalidRequestError(fmt.Errorf("invalid request: %w", err))
+	}
+
+	return nil
+}
+
+type GetAllUsersResponse struct {
+	Result bool           `json:"result"`
+	Users  []UserResponse `json:"users"`
+}
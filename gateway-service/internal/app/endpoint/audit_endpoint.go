@@ -0,0 +1,34 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+type AuditService interface {
+	GetAuditLogs(ctx context.Context, request dto.GetAuditLogsRequest) (dto.GetAuditLogsResponse, error)
+}
+
+func NewAuditEndpoint(service AuditService) Audit {
+	return Audit{
+		GetAll: makeGetAuditLogsEndpoint(service),
+	}
+}
+
+func makeGetAuditLogsEndpoint(service AuditService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetAuditLogsRequest)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		response, err := service.GetAuditLogs(ctx, *req)
+		if err != nil {
+			return nil, err
+		}
+
+		return response, nil
+	}
+}
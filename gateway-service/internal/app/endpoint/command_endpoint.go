@@ -0,0 +1,34 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+type CommandService interface {
+	ExecuteCreateListing(ctx context.Context, command dto.CreateListingCommand) error
+}
+
+func NewCommandEndpoint(service CommandService) Command {
+	return Command{
+		CreateListing: makeCreateListingCommandEndpoint(service),
+	}
+}
+
+func makeCreateListingCommandEndpoint(service CommandService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.CreateListingCommand)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		if err := service.ExecuteCreateListing(ctx, *req); err != nil {
+			return nil, fmt.Errorf("command service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
@@ -21,9 +21,39 @@ type PublicListing struct {
 
 type PublicUser struct {
 	Create endpoint.Endpoint
+	Get    endpoint.Endpoint
+	GetAll endpoint.Endpoint
+}
+
+type ListingCache struct {
+	OnListingCreated endpoint.Endpoint
+}
+
+type Operation struct {
+	Get      endpoint.Endpoint
+	OnResult endpoint.Endpoint
+}
+
+type Command struct {
+	CreateListing endpoint.Endpoint
+}
+
+type LogLevel struct {
+	Get   endpoint.Endpoint
+	Set   endpoint.Endpoint
+	Reset endpoint.Endpoint
+}
+
+type Audit struct {
+	GetAll endpoint.Endpoint
 }
 
 type Endpoint struct {
 	PublicListing
 	PublicUser
+	ListingCache
+	Operation
+	Command
+	LogLevel
+	Audit
 }
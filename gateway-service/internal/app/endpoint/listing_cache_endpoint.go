@@ -0,0 +1,34 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+type ListingCacheInvalidator interface {
+	OnListingCreated(ctx context.Context, req dto.ListingCreated) error
+}
+
+func NewListingCacheEndpoint(invalidator ListingCacheInvalidator) ListingCache {
+	return ListingCache{
+		OnListingCreated: makeOnListingCreatedEndpoint(invalidator),
+	}
+}
+
+func makeOnListingCreatedEndpoint(invalidator ListingCacheInvalidator) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ListingCreated)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		if err := invalidator.OnListingCreated(ctx, *req); err != nil {
+			return nil, fmt.Errorf("listing cache invalidator: %w", err)
+		}
+
+		return nil, nil
+	}
+}
@@ -0,0 +1,52 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+type OperationService interface {
+	GetOperation(ctx context.Context, request dto.GetOperationRequest) (dto.GetOperationResponse, error)
+	CompleteOperation(ctx context.Context, result dto.CommandResult) error
+}
+
+func NewOperationEndpoint(service OperationService) Operation {
+	return Operation{
+		Get:      makeGetOperationEndpoint(service),
+		OnResult: makeOnCommandResultEndpoint(service),
+	}
+}
+
+func makeGetOperationEndpoint(service OperationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetOperationRequest)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		response, err := service.GetOperation(ctx, *req)
+		if err != nil {
+			return nil, err
+		}
+
+		return response, nil
+	}
+}
+
+func makeOnCommandResultEndpoint(service OperationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.CommandResult)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		if err := service.CompleteOperation(ctx, *req); err != nil {
+			return nil, fmt.Errorf("operation service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
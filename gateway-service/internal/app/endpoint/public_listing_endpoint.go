@@ -12,22 +12,44 @@ type PublicListingService interface {
 	GetAllListings(ctx context.Context, request dto.GetAllListingsRequest) (dto.GetAllListingsResponse, error)
 }
 
+// ListingCommandService queues listing commands for clients preferring an
+// asynchronous response.
+type ListingCommandService interface {
+	SubmitCreateListing(ctx context.Context, request dto.CreateListingRequest) (dto.OperationAcceptedResponse, error)
+}
+
+// NewPublicListingEndpoint creates the listing endpoints, commandService is
+// optional and every request is processed synchronously when nil.
 func NewPublicListingEndpoint(
 	service PublicListingService,
+	commandService ListingCommandService,
 ) PublicListing {
 	return PublicListing{
-		Create: makeCreateListingEndpoint(service),
+		Create: makeCreateListingEndpoint(service, commandService),
 		GetAll: makeGetAllListingsEndpoint(service),
 	}
 }
 
-func makeCreateListingEndpoint(service PublicListingService) endpoint.Endpoint {
+func makeCreateListingEndpoint(service PublicListingService,
+	commandService ListingCommandService,
+) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req, ok := request.(*dto.CreateListingRequest)
 		if !ok {
 			return nil, ErrInvalidType
 		}
 
+		// Prefer is only a hint, without a command bus the request is served
+		// synchronously and no Preference-Applied header is sent
+		if req.RespondAsync && commandService != nil {
+			response, err := commandService.SubmitCreateListing(ctx, *req)
+			if err != nil {
+				return nil, err
+			}
+
+			return response, nil
+		}
+
 		response, err := service.CreateListing(ctx, *req)
 		if err != nil {
 			return nil, err
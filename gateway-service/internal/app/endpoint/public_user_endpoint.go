@@ -9,6 +9,8 @@ import (
 
 type PublicUserService interface {
 	CreateUser(ctx context.Context, request dto.CreateUserRequest) (dto.CreateUserResponse, error)
+	GetUser(ctx context.Context, request dto.GetUserRequest) (dto.GetUserResponse, error)
+	GetAllUsers(ctx context.Context, request dto.GetAllUsersRequest) (dto.GetAllUsersResponse, error)
 }
 
 func NewPublicUserEndpoint(
@@ -16,6 +18,8 @@ func NewPublicUserEndpoint(
 ) PublicUser {
 	return PublicUser{
 		Create: makeCreateUserEndpoint(service),
+		Get:    makeGetUserEndpoint(service),
+		GetAll: makeGetAllUsersEndpoint(service),
 	}
 }
 
@@ -34,3 +38,35 @@ func makeCreateUserEndpoint(service PublicUserService) endpoint.Endpoint {
 		return response, nil
 	}
 }
+
+func makeGetUserEndpoint(service PublicUserService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetUserRequest)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		response, err := service.GetUser(ctx, *req)
+		if err != nil {
+			return nil, err
+		}
+
+		return response, nil
+	}
+}
+
+func makeGetAllUsersEndpoint(service PublicUserService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetAllUsersRequest)
+		if !ok {
+			return nil, ErrInvalidType
+		}
+
+		response, err := service.GetAllUsers(ctx, *req)
+		if err != nil {
+			return nil, err
+		}
+
+		return response, nil
+	}
+}
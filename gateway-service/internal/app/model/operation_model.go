@@ -0,0 +1,22 @@
+package model
+
+import "encoding/json"
+
+const (
+	CreateListingCommand = "command.listing.create"
+	CommandResultEvent   = "command.result"
+)
+
+// Operation tracks a command accepted with Prefer: respond-async until its
+// result event arrives.
+type Operation struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Status string          `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	// ConsistencyToken of the write done by the command, if any.
+	ConsistencyToken string `json:"consistency_token,omitempty"`
+	CreatedAt        int64  `json:"created_at"`
+	UpdatedAt        int64  `json:"updated_at"`
+}
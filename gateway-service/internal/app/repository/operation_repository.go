@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// OperationRepository stores operations in a JetStream key value bucket, so
+// every gateway instance and command worker shares the same status records.
+// Records expire with the TTL of the bucket.
+type OperationRepository struct {
+	kv jetstream.KeyValue
+}
+
+func NewOperationRepository(kv jetstream.KeyValue) *OperationRepository {
+	return &OperationRepository{
+		kv: kv,
+	}
+}
+
+func (r *OperationRepository) Create(ctx context.Context, operation *model.Operation) error {
+	data, err := json.Marshal(operation)
+	if err != nil {
+		return fmt.Errorf("marshal operation: %w", err)
+	}
+
+	if _, err := r.kv.Create(ctx, operation.ID, data); err != nil {
+		if errors.Is(err, jetstream.ErrKeyExists) {
+			return exception.ErrConflict
+		}
+
+		return fmt.Errorf("create operation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OperationRepository) GetByID(ctx context.Context, id string) (model.Operation, error) {
+	entry, err := r.kv.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return model.Operation{}, exception.ErrRecordNotFound
+		}
+
+		return model.Operation{}, fmt.Errorf("get operation: %w", err)
+	}
+
+	var operation model.Operation
+	if err := json.Unmarshal(entry.Value(), &operation); err != nil {
+		return model.Operation{}, fmt.Errorf("unmarshal operation: %w", err)
+	}
+
+	return operation, nil
+}
+
+func (r *OperationRepository) Update(ctx context.Context, operation *model.Operation) error {
+	data, err := json.Marshal(operation)
+	if err != nil {
+		return fmt.Errorf("marshal operation: %w", err)
+	}
+
+	if _, err := r.kv.Put(ctx, operation.ID, data); err != nil {
+		return fmt.Errorf("update operation: %w", err)
+	}
+
+	return nil
+}
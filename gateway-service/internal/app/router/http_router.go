@@ -2,13 +2,14 @@ package router
 
 import (
 	"log/slog"
-	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/health"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/metrics"
 	httptransport "github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/transport/http"
 )
 
@@ -16,18 +17,36 @@ import (
 func MakeHTTPRouter(
 	endpts endpoint.Endpoint,
 	cfg config.Config,
+	checks *health.Health,
 ) *chi.Mux {
 	// Initialize Router
 	router := chi.NewRouter()
 
-	router.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusNoContent)
+	// /health is kept for the probes configured before /health/live existed
+	router.Get("/health", checks.LiveHandler())
+	router.Get("/health/live", checks.LiveHandler())
+	router.Get("/health/ready", checks.ReadyHandler())
+
+	corsMiddleware := httptransport.NewCORS(cfg.HTTP.AllowedOrigin)
+	serviceTokens := httptransport.NewServiceTokens(cfg.ServiceTokens)
+	config.OnChange(func(cfg config.Config) {
+		corsMiddleware.SetAllowedOrigins(cfg.HTTP.AllowedOrigin)
+		serviceTokens.SetTokens(cfg.ServiceTokens)
 	})
 
 	router.Route("/", func(router chi.Router) {
 		router.Use(
-			httptransport.LoggingMiddleware(slog.Default()),
-			httptransport.CORSMiddleware(cfg.HTTP.AllowedOrigin),
+			httptransport.HeaderMiddleware(),
+			httptransport.TracingMiddleware(),
+			httptransport.SlowRequestMiddleware(slog.Default(), cfg.RequestTimeThreshold),
+			httptransport.LoggingMiddleware(slog.Default(),
+				httptransport.WithBodyLimit(cfg.Logging.BodyLimit),
+				httptransport.WithRedactedFields(cfg.Logging.RedactFields...),
+				httptransport.WithContentTypes(cfg.Logging.ContentTypes...),
+				httptransport.WithSuccessSampleRate(cfg.Logging.SuccessSampleRate),
+			),
+			httptransport.MetricsMiddleware(metrics.Default),
+			corsMiddleware.Handler,
 			httptransport.Recoverer(slog.Default()),
 			render.SetContentType(render.ContentTypeJSON),
 		)
@@ -53,6 +72,58 @@ func MakeHTTPRouter(
 					httptransport.DecodeRequest[dto.CreateUserRequest],
 					httptransport.ResponseWithBody,
 				))
+
+				router.Get("/", httptransport.MakeHandlerFunc(
+					endpts.PublicUser.GetAll,
+					httptransport.DecodeRequest[dto.GetAllUsersRequest],
+					httptransport.ResponseWithBody,
+				))
+
+				router.Get("/{id}", httptransport.MakeHandlerFunc(
+					endpts.PublicUser.Get,
+					httptransport.DecodeRequest[dto.GetUserRequest],
+					httptransport.ResponseWithBody,
+				))
+			})
+
+			// only served when the command bus is available
+			if endpts.Operation.Get != nil {
+				router.Get("/operations/{id}", httptransport.MakeHandlerFunc(
+					endpts.Operation.Get,
+					httptransport.DecodeRequest[dto.GetOperationRequest],
+					httptransport.ResponseWithBody,
+				))
+			}
+		})
+
+		router.Route("/admin", func(router chi.Router) {
+			router.Use(serviceTokens.Handler)
+
+			// user-service keeps the audit trail, its admin API isn't public
+			router.Get("/audit", httptransport.MakeHandlerFunc(
+				endpts.Audit.GetAll,
+				httptransport.DecodeRequest[dto.GetAuditLogsRequest],
+				httptransport.ResponseWithBody,
+			))
+
+			router.Route("/log-level", func(router chi.Router) {
+				router.Get("/", httptransport.MakeHandlerFunc(
+					endpts.LogLevel.Get,
+					httptransport.DecodeRequest[dto.GetLogLevelRequest],
+					httptransport.ResponseWithBody,
+				))
+
+				router.Put("/", httptransport.MakeHandlerFunc(
+					endpts.LogLevel.Set,
+					httptransport.DecodeRequest[dto.SetLogLevelRequest],
+					httptransport.ResponseWithBody,
+				))
+
+				router.Delete("/", httptransport.MakeHandlerFunc(
+					endpts.LogLevel.Reset,
+					httptransport.DecodeRequest[dto.ResetLogLevelRequest],
+					httptransport.ResponseWithBody,
+				))
 			})
 		})
 	})
@@ -5,10 +5,13 @@ package router
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	gokitendpoint "github.com/go-kit/kit/endpoint"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/health"
 )
 
 func TestConfigRoute(t *testing.T) {
@@ -26,8 +29,10 @@ func TestConfigRoute(t *testing.T) {
 		endpoint.Endpoint{
 			PublicListing: endpoint.PublicListing{},
 			PublicUser:    endpoint.PublicUser{},
+			Operation:     endpoint.Operation{Get: gokitendpoint.Nop},
 		},
 		cfg,
+		health.New(time.Second),
 	)
 
 	testCases := []struct {
@@ -42,6 +47,42 @@ func TestConfigRoute(t *testing.T) {
 			path:        "/health",
 			shouldMatch: true,
 		},
+		{
+			name:        "Liveness",
+			method:      http.MethodGet,
+			path:        "/health/live",
+			shouldMatch: true,
+		},
+		{
+			name:        "Readiness",
+			method:      http.MethodGet,
+			path:        "/health/ready",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Audit Logs",
+			method:      http.MethodGet,
+			path:        "/admin/audit",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Log Level",
+			method:      http.MethodGet,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Set Log Level",
+			method:      http.MethodPut,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Reset Log Level",
+			method:      http.MethodDelete,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
 		{
 			name:        "Create Listing",
 			method:      http.MethodPost,
@@ -60,6 +101,24 @@ func TestConfigRoute(t *testing.T) {
 			path:        "/public/users",
 			shouldMatch: true,
 		},
+		{
+			name:        "Get All Users",
+			method:      http.MethodGet,
+			path:        "/public/users",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get User",
+			method:      http.MethodGet,
+			path:        "/public/users/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Operation",
+			method:      http.MethodGet,
+			path:        "/public/operations/0b9f4c1e-7c43-4f5e-9a51-2f6e3d1c8a90",
+			shouldMatch: true,
+		},
 	}
 
 	chiCtx := chi.NewRouteContext()
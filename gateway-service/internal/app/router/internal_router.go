@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/health"
+)
+
+// MakeInternalRouter builds the router of the probes of the processes without
+// a public HTTP server, it is only served on the internal port.
+func MakeInternalRouter(checks *health.Health) *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Get("/health/live", checks.LiveHandler())
+	router.Get("/health/ready", checks.ReadyHandler())
+
+	return router
+}
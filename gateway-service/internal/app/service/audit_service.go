@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+type AuditLogClient interface {
+	GetAuditLogs(ctx context.Context, request dto.GetAuditLogsRequest) (dto.GetAuditLogsResponse, error)
+}
+
+// AuditService serves the audit trail kept by user-service.
+type AuditService struct {
+	auditLogClient AuditLogClient
+}
+
+func NewAuditService(auditLogClient AuditLogClient) *AuditService {
+	return &AuditService{auditLogClient: auditLogClient}
+}
+
+// GetAuditLogs godoc
+// @Summary      Get Audit Logs
+// @Description  Get the changes made to the users, latest first
+// @Tags         Admin
+// @ID           getAuditLogs
+// @Produce      json
+// @Param        entity	query	string	false	"Entity"
+// @Param        actor	query	string	false	"Actor"
+// @Param        page_num	query	int	false	"Page Number"
+// @Param        page_size	query	int	false	"Page Size"
+// @Success      200  {object}  dto.GetAuditLogsResponse	"Audit Logs"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      401  {object}  dto.ErrorResponse	"Unauthorized"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/audit [get].
+func (s *AuditService) GetAuditLogs(ctx context.Context,
+	request dto.GetAuditLogsRequest,
+) (dto.GetAuditLogsResponse, error) {
+	response, err := s.auditLogClient.GetAuditLogs(ctx, request)
+	if err != nil {
+		return dto.GetAuditLogsResponse{}, fmt.Errorf("get audit logs: %w", err)
+	}
+
+	return response, nil
+}
@@ -6,11 +6,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/lang"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/metrics"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/timing"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/tracing"
+)
+
+var (
+	clientRequests = metrics.Default.NewCounterVec("http_client_requests_total",
+		"Total number of downstream HTTP requests by response status, error when no response.",
+		"host", "method", "status")
+	clientDuration = metrics.Default.NewHistogramVec("http_client_request_duration_seconds",
+		"Downstream HTTP request latency in seconds, per attempt.", metrics.DefBuckets, "host", "method")
+	clientRetries = metrics.Default.NewCounterVec("http_client_retries_total",
+		"Total number of retried downstream HTTP requests.", "host", "method")
+	clientErrors = metrics.Default.NewCounterVec("http_client_errors_total",
+		"Total number of downstream HTTP calls failing without response or with a 5xx.", "host", "method")
 )
 
 type ErrorResponse struct {
@@ -19,22 +37,39 @@ type ErrorResponse struct {
 
 type ClientOption func(*HTTPClient)
 
+// WithTimeout bounds each attempt of a request.
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *HTTPClient) {
-		c.client.Timeout = timeout
+		c.client.Store(&http.Client{Timeout: timeout})
 	}
 }
 
 func WithMaxRetries(maxRetries int) ClientOption {
 	return func(c *HTTPClient) {
-		c.maxRetries = maxRetries
+		c.maxRetries.Store(int64(maxRetries))
 	}
 }
 
+// HTTPClient calls a downstream service, its options can change while
+// requests are in flight, each request uses the options it started with.
 type HTTPClient struct {
-	client     *http.Client
+	client     atomic.Pointer[http.Client]
 	url        string
-	maxRetries int
+	maxRetries atomic.Int64
+}
+
+func (hc *HTTPClient) Configure(opts ...ClientOption) {
+	for _, opt := range opts {
+		opt(hc)
+	}
+}
+
+func (hc *HTTPClient) httpClient() *http.Client {
+	if client := hc.client.Load(); client != nil {
+		return client
+	}
+
+	return http.DefaultClient
 }
 
 func (hc *HTTPClient) doRequestWithResponse(
@@ -62,30 +97,78 @@ func (hc *HTTPClient) doRequestWithResponse(
 
 	headerFunc(httpReq)
 
+	if reqContext, ok := dto.RequestFromContext(ctx); ok {
+		if reqContext.RequestID != "" {
+			httpReq.Header.Set(dto.RequestIDHeader, reqContext.RequestID)
+		}
+
+		if reqContext.Actor != "" {
+			httpReq.Header.Set(dto.ActorHeader, reqContext.Actor)
+		}
+	}
+
 	backOffTime := 100
-	maxRetries := hc.maxRetries
+	client := hc.httpClient()
+	maxRetries := int(hc.maxRetries.Load())
 	backoff := time.Duration(backOffTime) * time.Millisecond
+	host := httpReq.URL.Host
+
+	// one span covers every attempt, the downstream service continues it
+	ctx, span := tracing.Start(ctx, method+" "+host, tracing.SpanKindClient)
+	defer span.End()
+
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("http.url", httpReq.URL.String())
+	tracing.Inject(ctx, httpReq.Header)
+
+	// retries and their backoff are part of the time the caller waited
+	callStart := time.Now()
+	defer func() {
+		timing.AddDownstream(ctx, time.Since(callStart))
+	}()
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		resp, err := hc.client.Do(httpReq)
+		start := time.Now()
+		resp, err := client.Do(httpReq)
+
+		clientDuration.WithLabelValues(host, method).Observe(time.Since(start).Seconds())
+
 		if err != nil {
+			clientRequests.WithLabelValues(host, method, "error").Inc()
+
 			// Check if context was canceled or deadline exceeded
 			if ctx.Err() != nil {
+				clientErrors.WithLabelValues(host, method).Inc()
+				span.RecordError(ctx.Err())
+
 				return nil, fmt.Errorf("context error: %w", ctx.Err())
 			}
 
 			// Wait with exponential backoff before retrying
 			if attempt < maxRetries-1 {
+				clientRetries.WithLabelValues(host, method).Inc()
 				time.Sleep(backoff)
 				backoff *= 2
 
 				continue
 			}
 
+			clientErrors.WithLabelValues(host, method).Inc()
+			span.RecordError(err)
+
 			return nil, fmt.Errorf("do request: %w", err)
 		}
 
+		clientRequests.WithLabelValues(host, method, strconv.Itoa(resp.StatusCode)).Inc()
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		span.SetAttribute("http.attempts", attempt+1)
+
 		if resp.StatusCode >= http.StatusBadRequest {
+			if resp.StatusCode >= http.StatusInternalServerError {
+				clientErrors.WithLabelValues(host, method).Inc()
+				span.RecordError(fmt.Errorf("status code: %d", resp.StatusCode))
+			}
+
 			return nil, errorResponseFunc(resp)
 		}
 
@@ -98,6 +181,18 @@ func (hc *HTTPClient) doRequestWithResponse(
 func defaultErrorResponseFunc(resp *http.Response) error { //nolint:unused
 	var errorResp ErrorResponse
 
+	host := strings.Split(resp.Request.URL.Host, ":")[0]
+
+	// the client can retry later, unlike after other server errors
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		err := exception.ErrServiceUnavailable
+		err.MessageVars = map[string]interface{}{
+			"service": host,
+		}
+
+		return err
+	}
+
 	err := json.NewDecoder(resp.Body).Decode(&errorResp)
 	if err != nil {
 		return fmt.Errorf("decode error response: %w", err)
@@ -108,8 +203,6 @@ func defaultErrorResponseFunc(resp *http.Response) error { //nolint:unused
 		return fmt.Errorf("returned status code: %d", resp.StatusCode)
 	}
 
-	host := strings.Split(resp.Request.URL.Host, ":")[0]
-
 	return exception.ApplicationError{
 		StatusCode: resp.StatusCode,
 		Localizable: lang.Localizable{
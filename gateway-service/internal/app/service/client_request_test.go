@@ -0,0 +1,102 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/timing"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHTTPClient_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "boom"}`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	host := serverURL.Host
+
+	_, err := NewUserServiceClient(server.URL, "", WithMaxRetries(1)).GetUserByID(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(clientRequests.WithLabelValues(host, http.MethodGet, "500")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(clientErrors.WithLabelValues(host, http.MethodGet)))
+
+	// nothing listens anymore, every attempt fails without response
+	server.Close()
+
+	_, err = NewUserServiceClient(server.URL, "", WithMaxRetries(2)).GetUserByID(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(clientRequests.WithLabelValues(host, http.MethodGet, "error")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(clientRetries.WithLabelValues(host, http.MethodGet)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(clientErrors.WithLabelValues(host, http.MethodGet)))
+}
+
+func TestHTTPClient_Tracing(t *testing.T) {
+	tracing.SetTracer(tracing.NewTracer("gateway-service", tracetest.NewInMemoryExporter()))
+	defer tracing.SetTracer(nil)
+
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get(tracing.TraceparentHeader)
+		w.Write([]byte(`{"result": true, "user": {"id": 1, "name": "john"}}`))
+	}))
+	defer server.Close()
+
+	ctx, span := tracing.Start(context.Background(), "GET /public/users", tracing.SpanKindServer)
+	defer span.End()
+
+	_, err := NewUserServiceClient(server.URL, "", WithMaxRetries(1)).GetUserByID(ctx, 1)
+	assert.NoError(t, err)
+
+	// the downstream parent is the client span, not the server span
+	assert.Contains(t, traceparent, "00-"+span.SpanContext().TraceID().String()+"-")
+	assert.NotContains(t, traceparent, span.SpanContext().SpanID().String())
+}
+
+func TestHTTPClient_Timing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"result": true, "user": {"id": 1, "name": "john"}}`))
+	}))
+	defer server.Close()
+
+	ctx, breakdown := timing.ContextWithBreakdown(context.Background())
+
+	_, err := NewUserServiceClient(server.URL, "", WithMaxRetries(1)).GetUserByID(ctx, 1)
+	assert.NoError(t, err)
+
+	assert.Positive(t, breakdown.Downstream())
+	assert.Zero(t, breakdown.DB())
+}
+
+func TestHTTPClient_Configure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"result": true, "user": {"id": 1, "name": "john"}}`))
+	}))
+	defer server.Close()
+
+	client := NewUserServiceClient(server.URL, "", WithMaxRetries(1), WithTimeout(time.Second))
+
+	// a reload only changes the options it sets
+	client.Configure(WithMaxRetries(3))
+
+	assert.Equal(t, int64(3), client.httpClient.maxRetries.Load())
+	assert.Equal(t, time.Second, client.httpClient.httpClient().Timeout)
+
+	_, err := client.GetUserByID(context.Background(), 1)
+	assert.NoError(t, err)
+
+	// without timeout the default client is used
+	assert.Same(t, http.DefaultClient, NewUserServiceClient(server.URL, "").httpClient.httpClient())
+}
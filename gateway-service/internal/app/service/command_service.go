@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
+)
+
+type ListingCreator interface {
+	CreateListing(ctx context.Context, request dto.CreateListingRequest) (dto.CreateListingResponse, error)
+}
+
+// CommandService executes the commands queued by OperationService and
+// publishes their outcome as a result event.
+type CommandService struct {
+	listingCreator      ListingCreator
+	operationRepository OperationRepository
+	publisher           Publisher
+}
+
+func NewCommandService(listingCreator ListingCreator,
+	operationRepository OperationRepository,
+	publisher Publisher,
+) *CommandService {
+	return &CommandService{
+		listingCreator:      listingCreator,
+		operationRepository: operationRepository,
+		publisher:           publisher,
+	}
+}
+
+// ExecuteCreateListing creates the listing of the command. A failed creation
+// is reported in the result event. The command is never redelivered, so when
+// the result can't be published it is written to the operation directly, and
+// when that fails too the operation is marked as failed.
+func (s *CommandService) ExecuteCreateListing(ctx context.Context, command dto.CreateListingCommand) error {
+	operation, err := s.operationRepository.GetByID(ctx, command.OperationID)
+	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
+		s.failOperation(ctx, command.OperationID, "command could not be executed")
+
+		return fmt.Errorf("get operation: %w", err)
+	}
+
+	if err == nil && operation.Status != dto.OperationStatusPending {
+		// creating a listing is not idempotent, never run a command twice
+		slog.WarnContext(ctx, "skipping completed operation",
+			slog.String("operation_id", operation.ID), slog.String("status", operation.Status))
+
+		return nil
+	}
+
+	result := dto.CommandResult{
+		OperationID: command.OperationID,
+		Status:      dto.OperationStatusSucceeded,
+	}
+
+	response, err := s.listingCreator.CreateListing(ctx, command.Listing)
+	if err != nil {
+		slog.WarnContext(ctx, "create listing command failed",
+			slog.String("operation_id", command.OperationID), slog.String("error", err.Error()))
+
+		result.Status = dto.OperationStatusFailed
+		result.Error = commandErrorMessage(err, command.Language)
+	} else {
+		result.ConsistencyToken = response.ConsistencyToken
+
+		result.Result, err = json.Marshal(response)
+		if err != nil {
+			// the listing exists, only its body is missing from the result
+			slog.ErrorContext(ctx, "failed to marshal create listing result",
+				slog.String("operation_id", command.OperationID), slog.String("error", err.Error()))
+		}
+	}
+
+	if _, err := s.publisher.Publish(ctx, model.CommandResultEvent, result); err != nil {
+		slog.WarnContext(ctx, "failed to publish command result, completing the operation directly",
+			slog.String("operation_id", command.OperationID), slog.String("error", err.Error()))
+
+		if err := completeOperation(ctx, s.operationRepository, result); err != nil {
+			if !errors.Is(err, exception.ErrRecordNotFound) {
+				s.failOperation(ctx, command.OperationID, "command outcome could not be recorded")
+			}
+
+			return fmt.Errorf("complete operation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// failOperation marks the operation of a command that can't be completed as
+// failed, best effort, so its client doesn't poll a pending operation until
+// it expires.
+func (s *CommandService) failOperation(ctx context.Context, operationID, message string) {
+	operation, err := s.operationRepository.GetByID(ctx, operationID)
+	if err != nil {
+		operation = model.Operation{
+			ID:        operationID,
+			Type:      dto.OperationTypeCreateListing,
+			CreatedAt: time.Now().UnixMicro(),
+		}
+	}
+
+	operation.Status = dto.OperationStatusFailed
+	operation.Error = message
+	operation.UpdatedAt = time.Now().UnixMicro()
+
+	if err := s.operationRepository.Update(ctx, &operation); err != nil {
+		slog.ErrorContext(ctx, "failed to mark operation as failed",
+			slog.String("operation_id", operationID), slog.String("error", err.Error()))
+	}
+}
+
+// commandErrorMessage mirrors the message ErrorResponse would have sent to a
+// synchronous client.
+func commandErrorMessage(err error, language string) string {
+	var appErr exception.ApplicationError
+	if errors.As(err, &appErr) {
+		return appErr.Localize(language)
+	}
+
+	return err.Error()
+}
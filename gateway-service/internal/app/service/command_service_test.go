@@ -0,0 +1,159 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/lang"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeListingCreator struct {
+	calls    int
+	response dto.CreateListingResponse
+	err      error
+}
+
+func (c *fakeListingCreator) CreateListing(_ context.Context,
+	_ dto.CreateListingRequest,
+) (dto.CreateListingResponse, error) {
+	c.calls++
+
+	return c.response, c.err
+}
+
+func TestCommandService_ExecuteCreateListing(t *testing.T) {
+	repo := newFakeOperationRepository()
+	repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusPending}
+
+	publisher := &fakePublisher{}
+	creator := &fakeListingCreator{response: dto.CreateListingResponse{
+		ListingResponse:  dto.ListingResponse{ID: 7, UserID: 1, ListingType: "rent", Price: 100},
+		ConsistencyToken: "listing.created:12",
+	}}
+	subject := NewCommandService(creator, repo, publisher)
+
+	err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, creator.calls)
+
+	assert.Len(t, publisher.published, 1)
+	assert.Equal(t, model.CommandResultEvent, publisher.published[0].subject)
+
+	result, ok := publisher.published[0].request.(dto.CommandResult)
+	assert.True(t, ok)
+	assert.Equal(t, dto.OperationStatusSucceeded, result.Status)
+	assert.Equal(t, "listing.created:12", result.ConsistencyToken)
+
+	var created dto.CreateListingResponse
+	assert.NoError(t, json.Unmarshal(result.Result, &created))
+	assert.Equal(t, int64(7), created.ID)
+}
+
+func TestCommandService_ExecuteCreateListingFailed(t *testing.T) {
+	lang.SetBasePath("../../../resources/locales")
+
+	repo := newFakeOperationRepository()
+	repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusPending}
+
+	publisher := &fakePublisher{}
+	creator := &fakeListingCreator{err: exception.ApplicationError{
+		Localizable: lang.Localizable{Message: "user not found"},
+		StatusCode:  http.StatusNotFound,
+	}}
+	subject := NewCommandService(creator, repo, publisher)
+
+	err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+	assert.NoError(t, err)
+
+	result, ok := publisher.published[0].request.(dto.CommandResult)
+	assert.True(t, ok)
+	assert.Equal(t, dto.OperationStatusFailed, result.Status)
+	assert.Equal(t, "user not found", result.Error)
+	assert.Empty(t, result.Result)
+}
+
+func TestCommandService_ExecuteCreateListingCompleted(t *testing.T) {
+	repo := newFakeOperationRepository()
+	repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusSucceeded}
+
+	publisher := &fakePublisher{}
+	creator := &fakeListingCreator{}
+	subject := NewCommandService(creator, repo, publisher)
+
+	err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, creator.calls)
+	assert.Empty(t, publisher.published)
+}
+
+func TestCommandService_ExecuteCreateListingPublishError(t *testing.T) {
+	repo := newFakeOperationRepository()
+	repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusPending}
+
+	creator := &fakeListingCreator{response: dto.CreateListingResponse{
+		ListingResponse:  dto.ListingResponse{ID: 7, UserID: 1, ListingType: "rent", Price: 100},
+		ConsistencyToken: "listing.created:12",
+	}}
+	subject := NewCommandService(creator, repo, &fakePublisher{err: errors.New("nats down")})
+
+	// the listing is created, the operation can't stay pending
+	err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, creator.calls)
+
+	operation := repo.operations["abc"]
+	assert.Equal(t, dto.OperationStatusSucceeded, operation.Status)
+	assert.Equal(t, "listing.created:12", operation.ConsistencyToken)
+
+	var created dto.CreateListingResponse
+	assert.NoError(t, json.Unmarshal(operation.Result, &created))
+	assert.Equal(t, int64(7), created.ID)
+
+	t.Run("operation not updated", func(t *testing.T) {
+		repo := newFakeOperationRepository()
+		repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusPending}
+		repo.updateErrs = []error{errors.New("kv timeout")}
+
+		subject := NewCommandService(creator, repo, &fakePublisher{err: errors.New("nats down")})
+
+		err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+		assert.Error(t, err)
+		assert.Equal(t, dto.OperationStatusFailed, repo.operations["abc"].Status)
+		assert.Equal(t, "command outcome could not be recorded", repo.operations["abc"].Error)
+	})
+
+	t.Run("operation missing", func(t *testing.T) {
+		repo := newFakeOperationRepository()
+		subject := NewCommandService(&fakeListingCreator{}, repo, &fakePublisher{err: errors.New("nats down")})
+
+		err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCommandService_ExecuteCreateListingGetError(t *testing.T) {
+	repo := newFakeOperationRepository()
+	repo.getErr = errors.New("kv timeout")
+
+	creator := &fakeListingCreator{}
+	subject := NewCommandService(creator, repo, &fakePublisher{})
+
+	// the command is never redelivered, the operation can't stay pending
+	err := subject.ExecuteCreateListing(context.Background(), dto.CreateListingCommand{OperationID: "abc"})
+	assert.Error(t, err)
+	assert.Equal(t, 0, creator.calls)
+
+	operation := repo.operations["abc"]
+	assert.Equal(t, dto.OperationStatusFailed, operation.Status)
+	assert.Equal(t, "command could not be executed", operation.Error)
+	assert.Equal(t, dto.OperationTypeCreateListing, operation.Type)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/cache"
+)
+
+// ListingCacheInvalidator evicts cached listing pages when the underlying
+// data changes, so entries don't have to wait for their TTL.
+type ListingCacheInvalidator struct {
+	listingCache *cache.Cache[dto.GetAllListingsResponse]
+}
+
+func NewListingCacheInvalidator(
+	listingCache *cache.Cache[dto.GetAllListingsResponse],
+) *ListingCacheInvalidator {
+	return &ListingCacheInvalidator{listingCache: listingCache}
+}
+
+// OnListingCreated drops the pages the new listing can appear in, the user's
+// own pages and every unfiltered page.
+func (s *ListingCacheInvalidator) OnListingCreated(ctx context.Context, req dto.ListingCreated) error {
+	slog.DebugContext(ctx, "invalidating listing cache",
+		slog.String("reason", "listing created"), slog.Int64("user_id", req.UserID))
+
+	s.listingCache.InvalidateTag(dto.ListingCacheTagUser(req.UserID))
+	s.listingCache.InvalidateTag(dto.ListingCacheTagAll)
+
+	return nil
+}
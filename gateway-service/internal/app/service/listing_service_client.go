@@ -27,18 +27,20 @@ func NewListingServiceClient(
 ) *ListingServiceClient {
 	client := &ListingServiceClient{
 		httpClient: HTTPClient{
-			client: http.DefaultClient,
-			url:    serviceURL,
+			url: serviceURL,
 		},
 	}
 
-	for _, opt := range opts {
-		opt(&client.httpClient)
-	}
+	client.httpClient.Configure(opts...)
 
 	return client
 }
 
+// Configure changes the options of the client, for instance on config reload.
+func (c *ListingServiceClient) Configure(opts ...ClientOption) {
+	c.httpClient.Configure(opts...)
+}
+
 func (c *ListingServiceClient) CreateListing(ctx context.Context,
 	request dto.CreateListingRequest,
 ) (dto.CreateListingResponse, error) {
@@ -67,6 +69,8 @@ func (c *ListingServiceClient) CreateListing(ctx context.Context,
 		return dto.CreateListingResponse{}, fmt.Errorf("decode response: %w", err)
 	}
 
+	response.ConsistencyToken = resp.Header.Get(dto.ConsistencyTokenHeader)
+
 	return response, nil
 }
 
@@ -20,18 +20,20 @@ func NewListingViewServiceClient(
 ) *ListingViewServiceClient {
 	client := &ListingViewServiceClient{
 		httpClient: HTTPClient{
-			client: http.DefaultClient,
-			url:    serviceURL,
+			url: serviceURL,
 		},
 	}
 
-	for _, opt := range opts {
-		opt(&client.httpClient)
-	}
+	client.httpClient.Configure(opts...)
 
 	return client
 }
 
+// Configure changes the options of the client, for instance on config reload.
+func (c *ListingViewServiceClient) Configure(opts ...ClientOption) {
+	c.httpClient.Configure(opts...)
+}
+
 func (c *ListingViewServiceClient) GetAllListings(ctx context.Context,
 	request dto.GetAllListingsRequest,
 ) (dto.GetAllListingsResponse, error) {
@@ -63,3 +65,28 @@ func (c *ListingViewServiceClient) GetAllListings(ctx context.Context,
 
 	return response, nil
 }
+
+func (c *ListingViewServiceClient) GetProjectionOffsets(ctx context.Context,
+) (dto.GetProjectionOffsetsResponse, error) {
+	var response dto.GetProjectionOffsetsResponse
+
+	path := "/projection/offsets"
+
+	headerFunc := func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.doRequestWithResponse(ctx, http.MethodGet, path, headerFunc,
+		nil, defaultErrorResponseFunc)
+	if err != nil {
+		return dto.GetProjectionOffsetsResponse{}, fmt.Errorf("get projection offsets request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return dto.GetProjectionOffsetsResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	return response, nil
+}
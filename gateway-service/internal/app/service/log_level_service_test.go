@@ -0,0 +1,36 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelService(t *testing.T) {
+	levels := logger.NewLevelController(slog.LevelInfo)
+	svc := NewLogLevelService(levels)
+
+	got, err := svc.SetLogLevel(context.Background(), dto.SetLogLevelRequest{Level: "debug"})
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", got.Level)
+	assert.Equal(t, "info", got.ConfiguredLevel)
+	assert.WithinDuration(t, time.Now().Add(defaultLogLevelDuration), time.UnixMicro(got.RevertAt), time.Second)
+
+	_, err = svc.SetLogLevel(context.Background(), dto.SetLogLevelRequest{Level: "warn", Duration: 48 * time.Hour})
+
+	var appErr exception.ApplicationError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, exception.CodeBadRequest, appErr.StatusCode)
+
+	got, err = svc.ResetLogLevel(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, dto.LogLevelResponse{Result: true, Level: "info", ConfiguredLevel: "info"}, got)
+}
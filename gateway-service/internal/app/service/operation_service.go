@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/model"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+type OperationRepository interface {
+	Create(ctx context.Context, operation *model.Operation) error
+	GetByID(ctx context.Context, id string) (model.Operation, error)
+	Update(ctx context.Context, operation *model.Operation) error
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, subject string, request interface{}) (*jetstream.PubAck, error)
+}
+
+// OperationService queues commands of clients that prefer an asynchronous
+// response and tracks their status until the result event comes back. An
+// operation pending for longer than pendingTimeout is reported as failed, its
+// command was lost.
+type OperationService struct {
+	operationRepository OperationRepository
+	publisher           Publisher
+	pendingTimeout      time.Duration
+}
+
+// NewOperationService tracks operations, a zero pendingTimeout lets them stay
+// pending until they expire.
+func NewOperationService(operationRepository OperationRepository,
+	publisher Publisher,
+	pendingTimeout time.Duration,
+) *OperationService {
+	return &OperationService{
+		operationRepository: operationRepository,
+		publisher:           publisher,
+		pendingTimeout:      pendingTimeout,
+	}
+}
+
+// SubmitCreateListing stores a pending operation and publishes the create
+// listing command, the listing is created later by the command worker.
+func (s *OperationService) SubmitCreateListing(ctx context.Context,
+	request dto.CreateListingRequest,
+) (dto.OperationAcceptedResponse, error) {
+	now := time.Now().UnixMicro()
+	operation := model.Operation{
+		ID:        dto.NewRequestID(),
+		Type:      dto.OperationTypeCreateListing,
+		Status:    dto.OperationStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.operationRepository.Create(ctx, &operation); err != nil {
+		return dto.OperationAcceptedResponse{}, fmt.Errorf("create operation: %w", err)
+	}
+
+	reqContext, _ := dto.RequestFromContext(ctx)
+
+	_, err := s.publisher.Publish(ctx, model.CreateListingCommand, dto.CreateListingCommand{
+		OperationID: operation.ID,
+		Language:    reqContext.Language,
+		Listing:     request,
+	})
+	if err != nil {
+		// nobody will ever complete the operation, don't leave it pending
+		s.fail(ctx, &operation, "command could not be queued")
+
+		return dto.OperationAcceptedResponse{}, fmt.Errorf("publish command: %w", err)
+	}
+
+	return dto.OperationAcceptedResponse{
+		Result:    true,
+		Operation: operationResponse(operation),
+	}, nil
+}
+
+// GetOperation godoc
+// @Summary      Get Operation
+// @Description  Get the status of an asynchronous operation
+// @Tags         Operation
+// @ID           getOperation
+// @Produce      json
+// @Param        id	path	string	true	"Operation ID"
+// @Success      200  {object}  dto.GetOperationResponse	"Operation"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /public/operations/{id} [get].
+func (s *OperationService) GetOperation(ctx context.Context,
+	request dto.GetOperationRequest,
+) (dto.GetOperationResponse, error) {
+	operation, err := s.operationRepository.GetByID(ctx, request.ID)
+	if err != nil {
+		return dto.GetOperationResponse{}, err
+	}
+
+	if s.timedOut(operation) {
+		// a command that can't be decoded or whose worker died never reports
+		// back, the failure is stored so a late result doesn't overwrite it
+		s.fail(ctx, &operation, "operation timed out")
+	}
+
+	return dto.GetOperationResponse{
+		Result:    true,
+		Operation: operationResponse(operation),
+	}, nil
+}
+
+// CompleteOperation records the outcome of a command.
+func (s *OperationService) CompleteOperation(ctx context.Context, result dto.CommandResult) error {
+	return completeOperation(ctx, s.operationRepository, result)
+}
+
+// completeOperation stores result in the operation it belongs to, unless the
+// operation is already completed.
+func completeOperation(ctx context.Context, operationRepository OperationRepository,
+	result dto.CommandResult,
+) error {
+	operation, err := operationRepository.GetByID(ctx, result.OperationID)
+	if err != nil {
+		return fmt.Errorf("get operation: %w", err)
+	}
+
+	if operation.Status != dto.OperationStatusPending {
+		slog.WarnContext(ctx, "operation already completed",
+			slog.String("operation_id", operation.ID), slog.String("status", operation.Status))
+
+		return nil
+	}
+
+	operation.Status = result.Status
+	operation.Result = result.Result
+	operation.Error = result.Error
+	operation.ConsistencyToken = result.ConsistencyToken
+	operation.UpdatedAt = time.Now().UnixMicro()
+
+	if err := operationRepository.Update(ctx, &operation); err != nil {
+		return fmt.Errorf("update operation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OperationService) timedOut(operation model.Operation) bool {
+	if s.pendingTimeout <= 0 || operation.Status != dto.OperationStatusPending {
+		return false
+	}
+
+	return time.Since(time.UnixMicro(operation.CreatedAt)) > s.pendingTimeout
+}
+
+func (s *OperationService) fail(ctx context.Context, operation *model.Operation, message string) {
+	operation.Status = dto.OperationStatusFailed
+	operation.Error = message
+	operation.UpdatedAt = time.Now().UnixMicro()
+
+	if err := s.operationRepository.Update(ctx, operation); err != nil {
+		slog.ErrorContext(ctx, "failed to mark operation as failed",
+			slog.String("operation_id", operation.ID), slog.String("error", err.Error()))
+	}
+}
+
+func operationResponse(operation model.Operation) dto.OperationResponse {
+	return dto.OperationResponse{
+		ID:               operation.ID,
+		Type:             operation.Type,
+		Status:           operation.Status,
+		Result:           operation.Result,
+		Error:            operation.Error,
+		ConsistencyToken: operation.ConsistencyToken,
+		CreatedAt:        operation.CreatedAt,
+		UpdatedAt:        operation.UpdatedAt,
+	}
+}
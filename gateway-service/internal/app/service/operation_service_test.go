@@ -0,0 +1,207 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOperationRepository struct {
+	mu         sync.Mutex
+	operations map[string]model.Operation
+	getErr     error
+	// updateErrs fail the next updates, one each
+	updateErrs []error
+}
+
+func newFakeOperationRepository() *fakeOperationRepository {
+	return &fakeOperationRepository{operations: make(map[string]model.Operation)}
+}
+
+func (r *fakeOperationRepository) Create(_ context.Context, operation *model.Operation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.operations[operation.ID]; ok {
+		return exception.ErrConflict
+	}
+
+	r.operations[operation.ID] = *operation
+
+	return nil
+}
+
+func (r *fakeOperationRepository) GetByID(_ context.Context, id string) (model.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.getErr != nil {
+		return model.Operation{}, r.getErr
+	}
+
+	operation, ok := r.operations[id]
+	if !ok {
+		return model.Operation{}, exception.ErrRecordNotFound
+	}
+
+	return operation, nil
+}
+
+func (r *fakeOperationRepository) Update(_ context.Context, operation *model.Operation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.updateErrs) > 0 {
+		err := r.updateErrs[0]
+		r.updateErrs = r.updateErrs[1:]
+
+		return err
+	}
+
+	r.operations[operation.ID] = *operation
+
+	return nil
+}
+
+type publishedMessage struct {
+	subject string
+	request interface{}
+}
+
+type fakePublisher struct {
+	err       error
+	published []publishedMessage
+}
+
+func (p *fakePublisher) Publish(_ context.Context, subject string,
+	request interface{},
+) (*jetstream.PubAck, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	p.published = append(p.published, publishedMessage{subject: subject, request: request})
+
+	return &jetstream.PubAck{Sequence: uint64(len(p.published))}, nil
+}
+
+func TestOperationService_SubmitCreateListing(t *testing.T) {
+	repo := newFakeOperationRepository()
+	publisher := &fakePublisher{}
+	subject := NewOperationService(repo, publisher, 0)
+
+	listing := dto.CreateListingRequest{UserID: 1, ListingType: "rent", Price: 100}
+
+	response, err := subject.SubmitCreateListing(context.Background(), listing)
+	assert.NoError(t, err)
+	assert.True(t, response.Result)
+	assert.Equal(t, dto.OperationStatusPending, response.Operation.Status)
+	assert.Equal(t, dto.OperationTypeCreateListing, response.Operation.Type)
+
+	stored, err := repo.GetByID(context.Background(), response.Operation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusPending, stored.Status)
+
+	assert.Len(t, publisher.published, 1)
+	assert.Equal(t, model.CreateListingCommand, publisher.published[0].subject)
+	assert.Equal(t, dto.CreateListingCommand{
+		OperationID: response.Operation.ID,
+		Listing:     listing,
+	}, publisher.published[0].request)
+}
+
+func TestOperationService_SubmitCreateListingPublishError(t *testing.T) {
+	repo := newFakeOperationRepository()
+	subject := NewOperationService(repo, &fakePublisher{err: errors.New("nats down")}, 0)
+
+	_, err := subject.SubmitCreateListing(context.Background(), dto.CreateListingRequest{UserID: 1})
+	assert.Error(t, err)
+
+	// the operation is not left pending forever
+	assert.Len(t, repo.operations, 1)
+
+	for _, operation := range repo.operations {
+		assert.Equal(t, dto.OperationStatusFailed, operation.Status)
+	}
+}
+
+func TestOperationService_GetOperation(t *testing.T) {
+	repo := newFakeOperationRepository()
+	subject := NewOperationService(repo, &fakePublisher{}, 0)
+
+	_, err := subject.GetOperation(context.Background(), dto.GetOperationRequest{ID: "missing"})
+	assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+
+	repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusSucceeded,
+		ConsistencyToken: "listing.created:4"}
+
+	response, err := subject.GetOperation(context.Background(), dto.GetOperationRequest{ID: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusSucceeded, response.Operation.Status)
+	assert.Equal(t, "listing.created:4", response.Headers().Get(dto.ConsistencyTokenHeader))
+}
+
+func TestOperationService_GetOperationTimedOut(t *testing.T) {
+	repo := newFakeOperationRepository()
+	subject := NewOperationService(repo, &fakePublisher{}, time.Minute)
+
+	repo.operations["lost"] = model.Operation{ID: "lost", Status: dto.OperationStatusPending,
+		CreatedAt: time.Now().Add(-2 * time.Minute).UnixMicro()}
+	repo.operations["running"] = model.Operation{ID: "running", Status: dto.OperationStatusPending,
+		CreatedAt: time.Now().UnixMicro()}
+
+	response, err := subject.GetOperation(context.Background(), dto.GetOperationRequest{ID: "lost"})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusFailed, response.Operation.Status)
+	assert.Equal(t, "operation timed out", response.Operation.Error)
+	assert.Equal(t, dto.OperationStatusFailed, repo.operations["lost"].Status)
+
+	// a result coming after the timeout doesn't overwrite it
+	err = subject.CompleteOperation(context.Background(), dto.CommandResult{
+		OperationID: "lost",
+		Status:      dto.OperationStatusSucceeded,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusFailed, repo.operations["lost"].Status)
+
+	response, err = subject.GetOperation(context.Background(), dto.GetOperationRequest{ID: "running"})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusPending, response.Operation.Status)
+}
+
+func TestOperationService_CompleteOperation(t *testing.T) {
+	repo := newFakeOperationRepository()
+	subject := NewOperationService(repo, &fakePublisher{}, 0)
+
+	repo.operations["abc"] = model.Operation{ID: "abc", Status: dto.OperationStatusPending}
+
+	err := subject.CompleteOperation(context.Background(), dto.CommandResult{
+		OperationID: "abc",
+		Status:      dto.OperationStatusFailed,
+		Error:       "user record not found!",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusFailed, repo.operations["abc"].Status)
+	assert.Equal(t, "user record not found!", repo.operations["abc"].Error)
+
+	// a redelivered result doesn't overwrite the outcome
+	err = subject.CompleteOperation(context.Background(), dto.CommandResult{
+		OperationID: "abc",
+		Status:      dto.OperationStatusSucceeded,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.OperationStatusFailed, repo.operations["abc"].Status)
+
+	err = subject.CompleteOperation(context.Background(), dto.CommandResult{OperationID: "missing"})
+	assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+}
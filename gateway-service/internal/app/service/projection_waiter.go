@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+// defaultProjectionPollInterval is used when no poll interval is configured.
+const defaultProjectionPollInterval = 100 * time.Millisecond
+
+// ProjectionWaiter blocks reads until listing-view has applied the writes a
+// client asked to see, bounded by timeout.
+type ProjectionWaiter struct {
+	listingViewServiceClient *ListingViewServiceClient
+	timeout                  time.Duration
+	pollInterval             time.Duration
+}
+
+func NewProjectionWaiter(
+	listingViewServiceClient *ListingViewServiceClient,
+	timeout time.Duration,
+	pollInterval time.Duration,
+) *ProjectionWaiter {
+	if pollInterval <= 0 {
+		pollInterval = defaultProjectionPollInterval
+	}
+
+	return &ProjectionWaiter{
+		listingViewServiceClient: listingViewServiceClient,
+		timeout:                  timeout,
+		pollInterval:             pollInterval,
+	}
+}
+
+// Wait reports whether every token was applied to the projection before the
+// timeout, a nil waiter never waits and reports false. A token listing-view
+// failed to apply is never reached, Wait reports false as soon as it sees it.
+func (w *ProjectionWaiter) Wait(ctx context.Context, tokens []dto.ConsistencyToken) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+
+	if w == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		response, err := w.listingViewServiceClient.GetProjectionOffsets(ctx)
+		if err == nil {
+			if token, ok := failedToken(response.Failed, tokens); ok {
+				slog.WarnContext(ctx, "consistency token failed to be projected", "token", token.String())
+
+				return false
+			}
+
+			if offsetsReached(response.Offsets, tokens) {
+				return true
+			}
+		}
+
+		if err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "get projection offsets", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+func offsetsReached(offsets map[string]uint64, tokens []dto.ConsistencyToken) bool {
+	for _, token := range tokens {
+		if offsets[token.Subject] < token.Sequence {
+			return false
+		}
+	}
+
+	return true
+}
+
+func failedToken(failed map[string][]uint64, tokens []dto.ConsistencyToken) (dto.ConsistencyToken, bool) {
+	for _, token := range tokens {
+		if slices.Contains(failed[token.Subject], token.Sequence) {
+			return token, true
+		}
+	}
+
+	return dto.ConsistencyToken{}, false
+}
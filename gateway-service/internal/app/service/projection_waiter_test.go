@@ -0,0 +1,97 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newOffsetsServer serves listing-view offsets where the listing.created
+// sequence grows by one on every poll, starting at start.
+func newOffsetsServer(start uint64, polls *atomic.Uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sequence := start + polls.Add(1) - 1
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, fmt.Sprintf(`{"result": true, "offsets": {"listing.created": %d}}`, sequence))
+	}))
+}
+
+func TestProjectionWaiter_Wait(t *testing.T) {
+	var polls atomic.Uint64
+
+	server := newOffsetsServer(1, &polls)
+	defer server.Close()
+
+	subject := NewProjectionWaiter(NewListingViewServiceClient(server.URL, WithMaxRetries(1)),
+		time.Second, time.Millisecond)
+
+	reached := subject.Wait(context.Background(), []dto.ConsistencyToken{
+		{Subject: "listing.created", Sequence: 3},
+	})
+
+	assert.True(t, reached)
+	assert.Equal(t, uint64(3), polls.Load())
+}
+
+func TestProjectionWaiter_WaitTimeout(t *testing.T) {
+	var polls atomic.Uint64
+
+	server := newOffsetsServer(1, &polls)
+	defer server.Close()
+
+	subject := NewProjectionWaiter(NewListingViewServiceClient(server.URL, WithMaxRetries(1)),
+		50*time.Millisecond, 10*time.Millisecond)
+
+	reached := subject.Wait(context.Background(), []dto.ConsistencyToken{
+		{Subject: "user.created", Sequence: 1},
+	})
+
+	assert.False(t, reached)
+}
+
+func TestProjectionWaiter_WaitFailedSequence(t *testing.T) {
+	var polls atomic.Uint64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{"result": true, "offsets": {"listing.created": 9}, "failed": {"listing.created": [8]}}`)
+	}))
+	defer server.Close()
+
+	subject := NewProjectionWaiter(NewListingViewServiceClient(server.URL, WithMaxRetries(1)),
+		time.Second, time.Millisecond)
+
+	// the offset moved past the failed sequence, the write is still missing
+	assert.False(t, subject.Wait(context.Background(), []dto.ConsistencyToken{
+		{Subject: "listing.created", Sequence: 8},
+	}))
+	assert.Equal(t, uint64(1), polls.Load())
+
+	assert.True(t, subject.Wait(context.Background(), []dto.ConsistencyToken{
+		{Subject: "listing.created", Sequence: 9},
+	}))
+}
+
+func TestProjectionWaiter_NoTokens(t *testing.T) {
+	var subject *ProjectionWaiter
+
+	assert.True(t, subject.Wait(context.Background(), nil))
+	assert.False(t, subject.Wait(context.Background(), []dto.ConsistencyToken{
+		{Subject: "user.created", Sequence: 1},
+	}))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+// PublicAPIClient calls the public API of a running gateway, for the tools
+// driving the whole system like the seed command.
+type PublicAPIClient struct {
+	httpClient HTTPClient
+}
+
+func NewPublicAPIClient(
+	gatewayURL string,
+	opts ...ClientOption,
+) *PublicAPIClient {
+	client := &PublicAPIClient{
+		httpClient: HTTPClient{
+			url: gatewayURL,
+		},
+	}
+
+	client.httpClient.Configure(opts...)
+
+	return client
+}
+
+func (c *PublicAPIClient) CreateUser(ctx context.Context,
+	request dto.CreateUserRequest,
+) (dto.CreateUserResponse, error) {
+	var response dto.CreateUserResponse
+
+	path := "/public/users"
+
+	headerFunc := func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.doRequestWithResponse(ctx, http.MethodPost, path, headerFunc,
+		request, defaultErrorResponseFunc)
+	if err != nil {
+		return dto.CreateUserResponse{}, fmt.Errorf("create user request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return dto.CreateUserResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	response.ConsistencyToken = resp.Header.Get(dto.ConsistencyTokenHeader)
+
+	return response, nil
+}
+
+func (c *PublicAPIClient) CreateListing(ctx context.Context,
+	request dto.CreateListingRequest,
+) (dto.CreateListingResponse, error) {
+	var response dto.CreateListingResponse
+
+	path := "/public/listings"
+
+	headerFunc := func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.doRequestWithResponse(ctx, http.MethodPost, path, headerFunc,
+		request, defaultErrorResponseFunc)
+	if err != nil {
+		return dto.CreateListingResponse{}, fmt.Errorf("create listing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return dto.CreateListingResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	response.ConsistencyToken = resp.Header.Get(dto.ConsistencyTokenHeader)
+
+	return response, nil
+}
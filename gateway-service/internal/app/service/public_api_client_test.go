@@ -0,0 +1,63 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicAPIClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "seed", r.Header.Get(dto.ActorHeader))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/public/users":
+			assert.Equal(t, map[string]any{"name": "Budi Santoso"}, body)
+
+			w.Header().Set(dto.ConsistencyTokenHeader, "user.created:3")
+			io.WriteString(w, `{"user": {"id": 7, "name": "Budi Santoso"}}`)
+		case "/public/listings":
+			assert.Equal(t, map[string]any{"user_id": 7.0, "listing_type": "rent", "price": 2500.0}, body)
+
+			w.Header().Set(dto.ConsistencyTokenHeader, "listing.created:4")
+			io.WriteString(w, `{"listing": {"id": 9, "listing_type": "rent", "price": 2500}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error": "invalid request", "result": false}`)
+		}
+	}))
+	defer server.Close()
+
+	ctx := dto.ContextWithActor(context.Background(), "seed")
+	client := NewPublicAPIClient(server.URL, WithMaxRetries(1))
+
+	user, err := client.CreateUser(ctx, dto.CreateUserRequest{Name: "Budi Santoso"})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.CreateUserResponse{
+		UserResponse:     dto.UserResponse{ID: 7, Name: "Budi Santoso"},
+		ConsistencyToken: "user.created:3",
+	}, user)
+
+	listing, err := client.CreateListing(ctx, dto.CreateListingRequest{
+		UserID: 7, ListingType: dto.ListingTypeRent, Price: 2500,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.CreateListingResponse{
+		ListingResponse:  dto.ListingResponse{ID: 9, ListingType: "rent", Price: 2500},
+		ConsistencyToken: "listing.created:4",
+	}, listing)
+}
@@ -5,23 +5,32 @@ import (
 	"fmt"
 
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/cache"
 )
 
 type PublicListingService struct {
 	listingViewServiceClient *ListingViewServiceClient
 	listingServiceClient     *ListingServiceClient
 	userServiceClient        *UserServiceClient
+	listingCache             *cache.Cache[dto.GetAllListingsResponse]
+	projectionWaiter         *ProjectionWaiter
 }
 
+// NewPublicListingService creates the listing BFF service, listingCache is
+// optional and disables response caching when nil.
 func NewPublicListingService(
 	listingViewServiceClient *ListingViewServiceClient,
 	listingServiceClient *ListingServiceClient,
 	userServiceClient *UserServiceClient,
+	listingCache *cache.Cache[dto.GetAllListingsResponse],
+	projectionWaiter *ProjectionWaiter,
 ) *PublicListingService {
 	return &PublicListingService{
 		listingViewServiceClient: listingViewServiceClient,
 		listingServiceClient:     listingServiceClient,
 		userServiceClient:        userServiceClient,
+		listingCache:             listingCache,
+		projectionWaiter:         projectionWaiter,
 	}
 }
 
@@ -32,7 +41,9 @@ func NewPublicListingService(
 // @ID           createListing
 // @Produce      json
 // @Param        req body create listing	body		dto.CreateListingRequest	true	"Listing"
+// @Param        Prefer	header	string	false	"respond-async to queue the creation and poll its operation"
 // @Success      200  {object}  dto.CreateListingResponse	"Created"
+// @Success      202  {object}  dto.OperationAcceptedResponse	"Accepted"
 // @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
 // @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
 // @Router       /public/listings [post].
@@ -59,17 +70,76 @@ func (s *PublicListingService) CreateListing(ctx context.Context,
 // @ID           getAllListings
 // @Produce      json
 // @Param        req body get all listings	body		dto.GetAllListingsRequest	true	"Listing"
+// @Param        X-Consistency-Token	header	string	false	"Consistency tokens of previous writes"
 // @Success      200  {object}  dto.GetAllListingsResponse	"Listings"
 // @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
 // @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
 // @Router       /public/listings [get].
 func (s *PublicListingService) GetAllListings(ctx context.Context,
 	request dto.GetAllListingsRequest,
+) (dto.GetAllListingsResponse, error) {
+	stale := false
+
+	// clients reading their own writes skip the cache once the projection
+	// caught up, or get whatever is there flagged as stale
+	if len(request.ConsistencyTokens) > 0 {
+		if s.projectionWaiter.Wait(ctx, request.ConsistencyTokens) {
+			return s.getFreshListings(ctx, request)
+		}
+
+		stale = true
+	}
+
+	response, err := s.getListings(ctx, request)
+	if err != nil {
+		return dto.GetAllListingsResponse{}, err
+	}
+
+	response.Stale = stale
+
+	return response, nil
+}
+
+func (s *PublicListingService) getListings(ctx context.Context,
+	request dto.GetAllListingsRequest,
+) (dto.GetAllListingsResponse, error) {
+	if s.listingCache == nil {
+		response, err := s.listingViewServiceClient.GetAllListings(ctx, request)
+		if err != nil {
+			return dto.GetAllListingsResponse{}, fmt.Errorf("get all listings: %w", err)
+		}
+
+		return response, nil
+	}
+
+	loader := func(ctx context.Context) (dto.GetAllListingsResponse, error) {
+		return s.listingViewServiceClient.GetAllListings(ctx, request)
+	}
+
+	response, status, err := s.listingCache.GetOrLoad(ctx, request.CacheKey(), loader,
+		request.CacheTags()...)
+	if err != nil {
+		return dto.GetAllListingsResponse{}, fmt.Errorf("get all listings: %w", err)
+	}
+
+	response.CacheStatus = string(status)
+
+	return response, nil
+}
+
+// getFreshListings reads listing-view directly and refreshes the cached page.
+func (s *PublicListingService) getFreshListings(ctx context.Context,
+	request dto.GetAllListingsRequest,
 ) (dto.GetAllListingsResponse, error) {
 	response, err := s.listingViewServiceClient.GetAllListings(ctx, request)
 	if err != nil {
 		return dto.GetAllListingsResponse{}, fmt.Errorf("get all listings: %w", err)
 	}
 
+	if s.listingCache != nil {
+		s.listingCache.Set(request.CacheKey(), response, request.CacheTags()...)
+		response.CacheStatus = string(cache.StatusBypass)
+	}
+
 	return response, nil
 }
@@ -3,18 +3,31 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sync"
 
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
 )
 
+// profileListingsSize is the number of latest listings shown on a user profile.
+const profileListingsSize = 5
+
 type PublicUserService struct {
-	userServiceClient *UserServiceClient
+	userServiceClient        *UserServiceClient
+	listingViewServiceClient *ListingViewServiceClient
+	projectionWaiter         *ProjectionWaiter
 }
 
 func NewPublicUserService(
 	userServiceClient *UserServiceClient,
+	listingViewServiceClient *ListingViewServiceClient,
+	projectionWaiter *ProjectionWaiter,
 ) *PublicUserService {
-	return &PublicUserService{userServiceClient: userServiceClient}
+	return &PublicUserService{
+		userServiceClient:        userServiceClient,
+		listingViewServiceClient: listingViewServiceClient,
+		projectionWaiter:         projectionWaiter,
+	}
 }
 
 // CreateUser godoc
@@ -38,3 +51,103 @@ func (s *PublicUserService) CreateUser(ctx context.Context,
 
 	return response, nil
 }
+
+// GetUser godoc
+// @Summary      Get User
+// @Description  Get a User profile with the latest listings
+// @Tags         User
+// @ID           getUser
+// @Produce      json
+// @Param        id	path		int	true	"User ID"
+// @Param        X-Consistency-Token	header	string	false	"Consistency tokens of previous writes"
+// @Success      200  {object}  dto.GetUserResponse	"User"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /public/users/{id} [get].
+func (s *PublicUserService) GetUser(ctx context.Context,
+	request dto.GetUserRequest,
+) (dto.GetUserResponse, error) {
+	var (
+		wg          sync.WaitGroup
+		user        dto.UserResponse
+		userErr     error
+		listings    dto.GetAllListingsResponse
+		listingsErr error
+		stale       bool
+	)
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		user, userErr = s.userServiceClient.GetUserByID(ctx, request.ID)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		stale = !s.projectionWaiter.Wait(ctx, request.ConsistencyTokens)
+
+		listings, listingsErr = s.listingViewServiceClient.GetAllListings(ctx, dto.GetAllListingsRequest{
+			PageNumber: 1,
+			PageSize:   profileListingsSize,
+			UserID:     &request.ID,
+		})
+	}()
+
+	wg.Wait()
+
+	if userErr != nil {
+		return dto.GetUserResponse{}, fmt.Errorf("get user: %w", userErr)
+	}
+
+	response := dto.GetUserResponse{
+		Result:   true,
+		User:     user,
+		Listings: []dto.ListingResponse{},
+		Stale:    stale,
+	}
+
+	// the profile is still useful without listings, so a listing-view failure
+	// only degrades the response
+	if listingsErr != nil {
+		slog.WarnContext(ctx, "get user listings", "user_id", request.ID, "error", listingsErr)
+
+		response.ListingsUnavailable = true
+
+		return response, nil
+	}
+
+	for _, listing := range listings.Listings {
+		// the user is already at the top level of the profile
+		listing.User = nil
+		response.Listings = append(response.Listings, listing)
+	}
+
+	return response, nil
+}
+
+// GetAllUsers godoc
+// @Summary      Get All Users
+// @Description  Get All Users
+// @Tags         User
+// @ID           getAllUsers
+// @Produce      json
+// @Param        page_num	query		int	false	"Page number"
+// @Param        page_size	query		int	false	"Page size"
+// @Success      200  {object}  dto.GetAllUsersResponse	"Users"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /public/users [get].
+func (s *PublicUserService) GetAllUsers(ctx context.Context,
+	request dto.GetAllUsersRequest,
+) (dto.GetAllUsersResponse, error) {
+	response, err := s.userServiceClient.GetAllUsers(ctx, request)
+	if err != nil {
+		return dto.GetAllUsersResponse{}, fmt.Errorf("get all users: %w", err)
+	}
+
+	return response, nil
+}
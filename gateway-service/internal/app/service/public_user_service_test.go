@@ -0,0 +1,137 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUserServiceServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{
+			"result": true,
+			"user": {
+				"id": 1,
+				"name": "John Doe",
+				"created_at": 1234567890,
+				"updated_at": 1234567890
+			}
+		}`)
+	}))
+}
+
+func TestPublicUserService_GetUser(t *testing.T) {
+	userServer := newUserServiceServer()
+	defer userServer.Close()
+
+	listingViewServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("user_id"))
+		assert.Equal(t, "1", r.URL.Query().Get("page_num"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{
+			"result": true,
+			"listings": [
+				{
+					"id": 10,
+					"listing_type": "rent",
+					"price": 5000,
+					"created_at": 1234567890,
+					"updated_at": 1234567890,
+					"user": {"id": 1, "name": "John Doe"}
+				}
+			]
+		}`)
+	}))
+	defer listingViewServer.Close()
+
+	subject := NewPublicUserService(
+		NewUserServiceClient(userServer.URL, "", WithMaxRetries(1)),
+		NewListingViewServiceClient(listingViewServer.URL, WithMaxRetries(1)),
+		nil,
+	)
+
+	got, err := subject.GetUser(context.Background(), dto.GetUserRequest{ID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, dto.GetUserResponse{
+		Result: true,
+		User: dto.UserResponse{
+			ID:        1,
+			Name:      "John Doe",
+			CreatedAt: 1234567890,
+			UpdatedAt: 1234567890,
+		},
+		Listings: []dto.ListingResponse{
+			{
+				ID:          10,
+				ListingType: "rent",
+				Price:       5000,
+				CreatedAt:   1234567890,
+				UpdatedAt:   1234567890,
+			},
+		},
+	}, got)
+}
+
+func TestPublicUserService_GetUser_ListingsUnavailable(t *testing.T) {
+	userServer := newUserServiceServer()
+	defer userServer.Close()
+
+	listingViewServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"error": "database is down"}`)
+	}))
+	defer listingViewServer.Close()
+
+	subject := NewPublicUserService(
+		NewUserServiceClient(userServer.URL, "", WithMaxRetries(1)),
+		NewListingViewServiceClient(listingViewServer.URL, WithMaxRetries(1)),
+		nil,
+	)
+
+	got, err := subject.GetUser(context.Background(), dto.GetUserRequest{ID: 1})
+
+	assert.NoError(t, err)
+	assert.True(t, got.ListingsUnavailable)
+	assert.Empty(t, got.Listings)
+	assert.Equal(t, int64(1), got.User.ID)
+}
+
+func TestPublicUserService_GetUser_NotFound(t *testing.T) {
+	userServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error": "user not found"}`)
+	}))
+	defer userServer.Close()
+
+	listingViewServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{"result": true, "listings": []}`)
+	}))
+	defer listingViewServer.Close()
+
+	subject := NewPublicUserService(
+		NewUserServiceClient(userServer.URL, "", WithMaxRetries(1)),
+		NewListingViewServiceClient(listingViewServer.URL, WithMaxRetries(1)),
+		nil,
+	)
+
+	_, err := subject.GetUser(context.Background(), dto.GetUserRequest{ID: 1})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "user not found")
+}
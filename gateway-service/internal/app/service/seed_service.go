@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+)
+
+// seedRentShare is the fraction of the seeded listings for rent, the others
+// are for sale.
+const seedRentShare = 0.6
+
+var (
+	seedFirstNames = []string{
+		"Adi", "Agus", "Amelia", "Andi", "Anisa", "Bayu", "Budi", "Citra", "Dewi", "Dimas",
+		"Eka", "Fajar", "Fitri", "Gita", "Hadi", "Indah", "Joko", "Kartika", "Lestari", "Maya",
+		"Nadia", "Nur", "Putri", "Rina", "Rizky", "Sari", "Siti", "Teguh", "Wahyu", "Yusuf",
+		"Aisha", "Daniel", "Grace", "Hannah", "James", "Kevin", "Mei Ling", "Michael", "Sarah", "Wei",
+	}
+	seedLastNames = []string{
+		"Wijaya", "Santoso", "Pratama", "Saputra", "Hidayat", "Kusuma", "Nugroho", "Setiawan",
+		"Siregar", "Lubis", "Nasution", "Simanjuntak", "Halim", "Gunawan", "Tanoto", "Salim",
+		"Wibowo", "Purnomo", "Susanto", "Rahman", "Tan", "Lim", "Lee", "Wong", "Ng", "Chen",
+		"Smith", "Johnson", "Brown", "Garcia",
+	}
+
+	// seedPrices are log-normal around a median, monthly rents and sale
+	// prices have their own scale and spread.
+	seedPrices = map[string]seedPriceDistribution{
+		dto.ListingTypeRent: {median: 2_500, sigma: 0.45, step: 50},
+		dto.ListingTypeSale: {median: 350_000, sigma: 0.6, step: 1_000},
+	}
+)
+
+type seedPriceDistribution struct {
+	median float64
+	sigma  float64
+	// step rounds the prices, like real ones.
+	step int64
+}
+
+func (d seedPriceDistribution) price(rng *rand.Rand) int64 {
+	price := d.median * math.Exp(d.sigma*rng.NormFloat64())
+
+	return max(int64(math.Round(price/float64(d.step)))*d.step, d.step)
+}
+
+// SeedUser is a generated user with the listings to create for them, their
+// UserID is set once the user is created.
+type SeedUser struct {
+	Name     string
+	Listings []dto.CreateListingRequest
+}
+
+// GenerateSeedData generates users with listingsPerUser listings each, the
+// same seed always generates the same data.
+func GenerateSeedData(seed uint64, users, listingsPerUser int) []SeedUser {
+	rng := rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // reproducible data, not secrets
+
+	data := make([]SeedUser, users)
+
+	for i := range data {
+		data[i].Name = seedFirstNames[rng.IntN(len(seedFirstNames))] + " " +
+			seedLastNames[rng.IntN(len(seedLastNames))]
+		data[i].Listings = make([]dto.CreateListingRequest, listingsPerUser)
+
+		for j := range data[i].Listings {
+			listingType := dto.ListingTypeSale
+			if rng.Float64() < seedRentShare {
+				listingType = dto.ListingTypeRent
+			}
+
+			data[i].Listings[j] = dto.CreateListingRequest{
+				ListingType: listingType,
+				Price:       seedPrices[listingType].price(rng),
+			}
+		}
+	}
+
+	return data
+}
+
+// SeedTarget creates the seeded users and listings, the gateway public API
+// or its service layer.
+type SeedTarget interface {
+	CreateUser(ctx context.Context, request dto.CreateUserRequest) (dto.CreateUserResponse, error)
+	CreateListing(ctx context.Context, request dto.CreateListingRequest) (dto.CreateListingResponse, error)
+}
+
+type SeedProgress struct {
+	Users    int
+	Listings int
+	Failed   int
+	Elapsed  time.Duration
+}
+
+// Rate is the number of users and listings created per second.
+func (p SeedProgress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Users+p.Listings) / p.Elapsed.Seconds()
+}
+
+type SeedResult struct {
+	SeedProgress
+	// Tokens are the consistency tokens of the last write of every subject,
+	// the projection converged once it applied them.
+	Tokens []dto.ConsistencyToken
+}
+
+// SeedService creates generated data through a SeedTarget, the users are
+// created concurrently and the listings of a user after them.
+type SeedService struct {
+	target           SeedTarget
+	concurrency      int
+	maxFailures      int
+	progressInterval time.Duration
+}
+
+// NewSeedService seeds with concurrency workers and gives up once more than
+// maxFailures requests failed.
+func NewSeedService(target SeedTarget, concurrency, maxFailures int,
+	progressInterval time.Duration,
+) *SeedService {
+	return &SeedService{
+		target:           target,
+		concurrency:      max(concurrency, 1),
+		maxFailures:      maxFailures,
+		progressInterval: progressInterval,
+	}
+}
+
+type seedRun struct {
+	start    time.Time
+	users    atomic.Int64
+	listings atomic.Int64
+	failed   atomic.Int64
+
+	mu       sync.Mutex
+	tokens   map[string]uint64
+	firstErr error
+}
+
+func (r *seedRun) progress() SeedProgress {
+	return SeedProgress{
+		Users:    int(r.users.Load()),
+		Listings: int(r.listings.Load()),
+		Failed:   int(r.failed.Load()),
+		Elapsed:  time.Since(r.start),
+	}
+}
+
+func (r *seedRun) addToken(value string) {
+	token, err := dto.ParseConsistencyToken(value)
+	if err != nil {
+		// a write without token, like a listing queued to the command bus,
+		// isn't waited for
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.Subject] = max(r.tokens[token.Subject], token.Sequence)
+}
+
+// Seed creates data and calls progress every progress interval and once done,
+// never concurrently. It stops at the first error past the max failures.
+func (s *SeedService) Seed(ctx context.Context, data []SeedUser,
+	progress func(SeedProgress),
+) (SeedResult, error) {
+	run := &seedRun{start: time.Now(), tokens: make(map[string]uint64)}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	users := make(chan SeedUser)
+	done := make(chan struct{})
+
+	var workers sync.WaitGroup
+
+	for range s.concurrency {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for user := range users {
+				if err := s.seedUser(runCtx, run, user); err != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(done)
+		s.reportProgress(runCtx, run, progress)
+	}()
+
+feed:
+	for _, user := range data {
+		select {
+		case users <- user:
+		case <-runCtx.Done():
+			break feed
+		}
+	}
+
+	close(users)
+	workers.Wait()
+	cancel()
+	<-done
+
+	result := SeedResult{SeedProgress: run.progress()}
+
+	for subject, sequence := range run.tokens {
+		result.Tokens = append(result.Tokens, dto.ConsistencyToken{Subject: subject, Sequence: sequence})
+	}
+
+	sort.Slice(result.Tokens, func(i, j int) bool { return result.Tokens[i].Subject < result.Tokens[j].Subject })
+
+	if progress != nil {
+		progress(result.SeedProgress)
+	}
+
+	if run.firstErr != nil {
+		return result, run.firstErr
+	}
+
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("seed interrupted: %w", ctx.Err())
+	}
+
+	return result, nil
+}
+
+func (s *SeedService) reportProgress(ctx context.Context, run *seedRun, progress func(SeedProgress)) {
+	if progress == nil || s.progressInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			progress(run.progress())
+		}
+	}
+}
+
+// seedUser creates user then their listings, it returns an error once the
+// run has to stop.
+func (s *SeedService) seedUser(ctx context.Context, run *seedRun, user SeedUser) error {
+	created, err := s.target.CreateUser(ctx, dto.CreateUserRequest{Name: user.Name})
+	if err != nil {
+		// the listings of the user are skipped
+		return s.fail(ctx, run, fmt.Errorf("create user %q: %w", user.Name, err))
+	}
+
+	run.users.Add(1)
+	run.addToken(created.ConsistencyToken)
+
+	for _, listing := range user.Listings {
+		listing.UserID = created.ID
+
+		response, err := s.target.CreateListing(ctx, listing)
+		if err != nil {
+			if err := s.fail(ctx, run, fmt.Errorf("create listing of user %d: %w", created.ID, err)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		run.listings.Add(1)
+		run.addToken(response.ConsistencyToken)
+	}
+
+	return nil
+}
+
+// fail counts a failed request, it returns the error of the run once it has
+// to stop.
+func (s *SeedService) fail(ctx context.Context, run *seedRun, err error) error {
+	if ctx.Err() != nil {
+		// a request cut short by the end of the run isn't a failure
+		return ctx.Err()
+	}
+
+	if int(run.failed.Add(1)) <= s.maxFailures {
+		return nil
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if run.firstErr == nil {
+		run.firstErr = fmt.Errorf("more than %d failed requests, last: %w", s.maxFailures, err)
+	}
+
+	return run.firstErr
+}
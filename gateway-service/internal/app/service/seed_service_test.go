@@ -0,0 +1,179 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSeedData(t *testing.T) {
+	data := GenerateSeedData(42, 200, 5)
+
+	assert.Len(t, data, 200)
+	assert.Equal(t, data, GenerateSeedData(42, 200, 5), "the same seed generates the same data")
+	assert.NotEqual(t, data, GenerateSeedData(43, 200, 5))
+
+	var rents, sales []int64
+
+	for _, user := range data {
+		assert.NotEmpty(t, user.Name)
+		assert.Len(t, user.Listings, 5)
+
+		for _, listing := range user.Listings {
+			assert.Zero(t, listing.UserID)
+
+			switch listing.ListingType {
+			case dto.ListingTypeRent:
+				assert.Zero(t, listing.Price%50)
+				rents = append(rents, listing.Price)
+			case dto.ListingTypeSale:
+				assert.Zero(t, listing.Price%1_000)
+				sales = append(sales, listing.Price)
+			default:
+				t.Fatalf("unexpected listing type %q", listing.ListingType)
+			}
+		}
+	}
+
+	// 60% of rents, within the noise of 1000 listings
+	assert.InDelta(t, 600, len(rents), 60)
+	assert.InDelta(t, 2_500, median(rents), 250)
+	assert.InDelta(t, 350_000, median(sales), 50_000)
+}
+
+func median(values []int64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return float64(sorted[len(sorted)/2])
+}
+
+// fakeSeedTarget creates users and listings in memory, failing the user names
+// in failUsers.
+type fakeSeedTarget struct {
+	mu        sync.Mutex
+	failUsers map[string]bool
+	sequence  atomic.Uint64
+	users     map[int64]string
+	listings  map[int64][]dto.CreateListingRequest
+}
+
+func newFakeSeedTarget(failUsers ...string) *fakeSeedTarget {
+	target := &fakeSeedTarget{
+		failUsers: make(map[string]bool),
+		users:     make(map[int64]string),
+		listings:  make(map[int64][]dto.CreateListingRequest),
+	}
+
+	for _, name := range failUsers {
+		target.failUsers[name] = true
+	}
+
+	return target
+}
+
+func (f *fakeSeedTarget) CreateUser(_ context.Context, request dto.CreateUserRequest) (dto.CreateUserResponse, error) {
+	if f.failUsers[request.Name] {
+		return dto.CreateUserResponse{}, errors.New("user service unavailable")
+	}
+
+	sequence := f.sequence.Add(1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := int64(len(f.users) + 1)
+	f.users[id] = request.Name
+
+	return dto.CreateUserResponse{
+		UserResponse:     dto.UserResponse{ID: id, Name: request.Name},
+		ConsistencyToken: fmt.Sprintf("user.created:%d", sequence),
+	}, nil
+}
+
+func (f *fakeSeedTarget) CreateListing(_ context.Context,
+	request dto.CreateListingRequest,
+) (dto.CreateListingResponse, error) {
+	sequence := f.sequence.Add(1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listings[request.UserID] = append(f.listings[request.UserID], request)
+
+	return dto.CreateListingResponse{ConsistencyToken: fmt.Sprintf("listing.created:%d", sequence)}, nil
+}
+
+func TestSeedService_Seed(t *testing.T) {
+	target := newFakeSeedTarget()
+	data := GenerateSeedData(1, 20, 3)
+
+	var progress []SeedProgress
+
+	result, err := NewSeedService(target, 4, 0, time.Hour).Seed(context.Background(), data,
+		func(p SeedProgress) { progress = append(progress, p) })
+
+	assert.NoError(t, err)
+	assert.Equal(t, 20, result.Users)
+	assert.Equal(t, 60, result.Listings)
+	assert.Equal(t, 0, result.Failed)
+	assert.Greater(t, result.Rate(), 0.0)
+	assert.Equal(t, []SeedProgress{result.SeedProgress}, progress)
+
+	// the last listing of the run is the last write
+	assert.Len(t, result.Tokens, 2)
+	assert.Equal(t, dto.ConsistencyToken{Subject: "listing.created", Sequence: 80}, result.Tokens[0])
+	assert.Equal(t, "user.created", result.Tokens[1].Subject)
+
+	for id, name := range target.users {
+		assert.Len(t, target.listings[id], 3, "listings of %s", name)
+
+		for _, listing := range target.listings[id] {
+			assert.Equal(t, id, listing.UserID)
+		}
+	}
+}
+
+func TestSeedService_SeedFailures(t *testing.T) {
+	data := []SeedUser{
+		{Name: "Budi Santoso", Listings: make([]dto.CreateListingRequest, 2)},
+		{Name: "Siti Rahman", Listings: make([]dto.CreateListingRequest, 2)},
+	}
+
+	t.Run("tolerated failures skip the listings of the user", func(t *testing.T) {
+		result, err := NewSeedService(newFakeSeedTarget("Budi Santoso"), 1, 1, 0).
+			Seed(context.Background(), data, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Users)
+		assert.Equal(t, 2, result.Listings)
+		assert.Equal(t, 1, result.Failed)
+	})
+
+	t.Run("too many failures stop the run", func(t *testing.T) {
+		_, err := NewSeedService(newFakeSeedTarget("Budi Santoso"), 1, 0, 0).
+			Seed(context.Background(), data, nil)
+
+		assert.EqualError(t, err, `more than 0 failed requests, last: create user "Budi Santoso": `+
+			"user service unavailable")
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewSeedService(newFakeSeedTarget(), 1, 0, 0).Seed(ctx, data, nil)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
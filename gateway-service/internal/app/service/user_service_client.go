@@ -6,32 +6,61 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
 )
 
+// getUserByIDResponse is the envelope user-service wraps a single user in.
+type getUserByIDResponse struct {
+	Result bool             `json:"result"`
+	User   dto.UserResponse `json:"user"`
+}
+
 type UserServiceClient struct {
 	httpClient HTTPClient
+	adminToken atomic.Pointer[string]
 }
 
+// NewUserServiceClient calls user-service at serviceURL, adminToken
+// authenticates every request so user-service trusts the actor the gateway
+// forwards, and the admin requests.
 func NewUserServiceClient(
 	serviceURL string,
+	adminToken string,
 	opts ...ClientOption,
 ) *UserServiceClient {
 	client := &UserServiceClient{
 		httpClient: HTTPClient{
-			client: http.DefaultClient,
-			url:    serviceURL,
+			url: serviceURL,
 		},
 	}
 
-	for _, opt := range opts {
-		opt(&client.httpClient)
-	}
+	client.httpClient.Configure(opts...)
+	client.SetAdminToken(adminToken)
 
 	return client
 }
 
+// Configure changes the options of the client, for instance on config reload.
+func (c *UserServiceClient) Configure(opts ...ClientOption) {
+	c.httpClient.Configure(opts...)
+}
+
+// SetAdminToken changes the token of the requests, for instance when its
+// secret file is rotated.
+func (c *UserServiceClient) SetAdminToken(adminToken string) {
+	c.adminToken.Store(&adminToken)
+}
+
+// authorize authenticates req with the admin token, without it user-service
+// ignores the actor of the request.
+func (c *UserServiceClient) authorize(req *http.Request) {
+	if token := *c.adminToken.Load(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 func (c *UserServiceClient) CreateUser(ctx context.Context,
 	request dto.CreateUserRequest,
 ) (dto.CreateUserResponse, error) {
@@ -41,6 +70,7 @@ func (c *UserServiceClient) CreateUser(ctx context.Context,
 
 	headerFunc := func(req *http.Request) {
 		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		c.authorize(req)
 	}
 
 	// Convert request to form values
@@ -59,16 +89,19 @@ func (c *UserServiceClient) CreateUser(ctx context.Context,
 		return dto.CreateUserResponse{}, fmt.Errorf("decode response: %w", err)
 	}
 
+	response.ConsistencyToken = resp.Header.Get(dto.ConsistencyTokenHeader)
+
 	return response, nil
 }
 
 func (c *UserServiceClient) GetUserByID(ctx context.Context, userID int64) (dto.UserResponse, error) {
-	var response dto.UserResponse
+	var response getUserByIDResponse
 
 	path := fmt.Sprintf("/users/%d", userID)
 
 	headerFunc := func(req *http.Request) {
 		req.Header.Add("Content-Type", "application/json")
+		c.authorize(req)
 	}
 
 	resp, err := c.httpClient.doRequestWithResponse(ctx, http.MethodGet, path, headerFunc,
@@ -83,5 +116,74 @@ func (c *UserServiceClient) GetUserByID(ctx context.Context, userID int64) (dto.
 		return dto.UserResponse{}, fmt.Errorf("decode response: %w", err)
 	}
 
+	return response.User, nil
+}
+
+func (c *UserServiceClient) GetAllUsers(ctx context.Context,
+	request dto.GetAllUsersRequest,
+) (dto.GetAllUsersResponse, error) {
+	var response dto.GetAllUsersResponse
+
+	values := url.Values{}
+	values.Add("page_num", fmt.Sprintf("%d", request.PageNumber))
+	values.Add("page_size", fmt.Sprintf("%d", request.PageSize))
+	path := fmt.Sprintf("/users?%s", values.Encode())
+
+	headerFunc := func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/json")
+		c.authorize(req)
+	}
+
+	resp, err := c.httpClient.doRequestWithResponse(ctx, http.MethodGet, path, headerFunc,
+		nil, defaultErrorResponseFunc)
+	if err != nil {
+		return dto.GetAllUsersResponse{}, fmt.Errorf("get users request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return dto.GetAllUsersResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	return response, nil
+}
+
+func (c *UserServiceClient) GetAuditLogs(ctx context.Context,
+	request dto.GetAuditLogsRequest,
+) (dto.GetAuditLogsResponse, error) {
+	var response dto.GetAuditLogsResponse
+
+	values := url.Values{}
+	values.Add("page_num", fmt.Sprintf("%d", request.PageNumber))
+	values.Add("page_size", fmt.Sprintf("%d", request.PageSize))
+
+	if request.Entity != "" {
+		values.Add("entity", request.Entity)
+	}
+
+	if request.Actor != "" {
+		values.Add("actor", request.Actor)
+	}
+
+	path := fmt.Sprintf("/admin/audit?%s", values.Encode())
+
+	headerFunc := func(req *http.Request) {
+		req.Header.Add("Content-Type", "application/json")
+		c.authorize(req)
+	}
+
+	resp, err := c.httpClient.doRequestWithResponse(ctx, http.MethodGet, path, headerFunc,
+		nil, defaultErrorResponseFunc)
+	if err != nil {
+		return dto.GetAuditLogsResponse{}, fmt.Errorf("get audit logs request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return dto.GetAuditLogsResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+
 	return response, nil
 }
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,6 +19,7 @@ func TestUserServiceClient_CreateUser_Positive(t *testing.T) {
 		return func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(dto.ConsistencyTokenHeader, "user.created:9")
 				w.WriteHeader(http.StatusOK)
 				io.WriteString(w, `{
 					"user": {
@@ -30,7 +32,7 @@ func TestUserServiceClient_CreateUser_Positive(t *testing.T) {
 			}))
 			defer server.Close()
 
-			subject := NewUserServiceClient(server.URL, WithMaxRetries(1))
+			subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
 			got, err := subject.CreateUser(context.Background(), request)
 
 			assert.NoError(t, err)
@@ -49,6 +51,7 @@ func TestUserServiceClient_CreateUser_Positive(t *testing.T) {
 				CreatedAt: 1234567890,
 				UpdatedAt: 1234567890,
 			},
+			ConsistencyToken: "user.created:9",
 		},
 	))
 }
@@ -65,7 +68,7 @@ func TestUserServiceClient_CreateUser_Negative(t *testing.T) {
 			}))
 			defer server.Close()
 
-			subject := NewUserServiceClient(server.URL, WithMaxRetries(1))
+			subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
 			_, err := subject.CreateUser(context.Background(), request)
 
 			assert.Error(t, err)
@@ -88,15 +91,18 @@ func TestUserServiceClient_GetUserByID_Positive(t *testing.T) {
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusOK)
 				io.WriteString(w, `{
-					"id": 1,
-					"name": "John Doe",
-					"created_at": 1234567890,
-					"updated_at": 1234567890
+					"result": true,
+					"user": {
+						"id": 1,
+						"name": "John Doe",
+						"created_at": 1234567890,
+						"updated_at": 1234567890
+					}
 				}`)
 			}))
 			defer server.Close()
 
-			subject := NewUserServiceClient(server.URL, WithMaxRetries(1))
+			subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
 			got, err := subject.GetUserByID(context.Background(), userID)
 
 			assert.NoError(t, err)
@@ -127,7 +133,7 @@ func TestUserServiceClient_GetUserByID_Negative(t *testing.T) {
 			}))
 			defer server.Close()
 
-			subject := NewUserServiceClient(server.URL, WithMaxRetries(1))
+			subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
 			_, err := subject.GetUserByID(context.Background(), userID)
 
 			assert.Error(t, err)
@@ -140,3 +146,178 @@ func TestUserServiceClient_GetUserByID_Negative(t *testing.T) {
 		"user not found",
 	))
 }
+
+func TestUserServiceClient_GetAllUsers_Positive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("page_num"))
+		assert.Equal(t, "5", r.URL.Query().Get("page_size"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{
+			"result": true,
+			"users": [
+				{
+					"id": 1,
+					"name": "John Doe",
+					"created_at": 1234567890,
+					"updated_at": 1234567890
+				}
+			]
+		}`)
+	}))
+	defer server.Close()
+
+	subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
+	got, err := subject.GetAllUsers(context.Background(), dto.GetAllUsersRequest{
+		PageNumber: 2,
+		PageSize:   5,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, dto.GetAllUsersResponse{
+		Result: true,
+		Users: []dto.UserResponse{
+			{
+				ID:        1,
+				Name:      "John Doe",
+				CreatedAt: 1234567890,
+				UpdatedAt: 1234567890,
+			},
+		},
+	}, got)
+}
+
+func TestUserServiceClient_ForwardsRequestID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "abc-123", r.Header.Get(dto.RequestIDHeader))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{"result": true, "user": {"id": 1, "name": "John Doe"}}`)
+	}))
+	defer server.Close()
+
+	ctx := dto.ContextWithRequestID(context.Background(), "abc-123")
+
+	subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
+	_, err := subject.GetUserByID(ctx, 1)
+
+	assert.NoError(t, err)
+}
+
+func TestUserServiceClient_GetAuditLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/audit", r.URL.Path)
+		assert.Equal(t, "actor=admin&entity=user&page_num=1&page_size=10", r.URL.RawQuery)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "admin", r.Header.Get(dto.ActorHeader))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{
+			"result": true,
+			"audit_logs": [
+				{
+					"id": 1,
+					"actor": "admin",
+					"action": "create",
+					"entity": "user",
+					"entity_id": "1",
+					"changes": {"name": {"before": null, "after": "John Doe"}},
+					"request_id": "abc-123",
+					"created_at": 1234567890
+				}
+			]
+		}`)
+	}))
+	defer server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+	req, _ = dto.RequestWithContext(req)
+
+	subject := NewUserServiceClient(server.URL, "secret", WithMaxRetries(1))
+	got, err := subject.GetAuditLogs(dto.ContextWithActor(req.Context(), "admin"), dto.GetAuditLogsRequest{
+		Entity:     "user",
+		Actor:      "admin",
+		PageNumber: 1,
+		PageSize:   10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, dto.GetAuditLogsResponse{
+		Result: true,
+		AuditLogs: []dto.AuditLogResponse{
+			{
+				ID:        1,
+				Actor:     "admin",
+				Action:    "create",
+				Entity:    "user",
+				EntityID:  "1",
+				Changes:   map[string]dto.AuditChangeResponse{"name": {After: "John Doe"}},
+				RequestID: "abc-123",
+				CreatedAt: 1234567890,
+			},
+		},
+	}, got)
+}
+
+func TestUserServiceClient_SetAdminToken(t *testing.T) {
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, `{"result": true, "audit_logs": []}`)
+	}))
+	defer server.Close()
+
+	subject := NewUserServiceClient(server.URL, "secret", WithMaxRetries(1))
+	subject.SetAdminToken("rotated")
+
+	_, err := subject.GetAuditLogs(context.Background(), dto.GetAuditLogsRequest{PageNumber: 1, PageSize: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer rotated", authorization)
+}
+
+func TestUserServiceClient_CreateUser_ForwardsActor(t *testing.T) {
+	var authorization, actor string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		actor = r.Header.Get(dto.ActorHeader)
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"user": {"id": 1, "name": "John Doe"}}`)
+	}))
+	defer server.Close()
+
+	subject := NewUserServiceClient(server.URL, "secret", WithMaxRetries(1))
+	ctx := dto.ContextWithActor(context.Background(), "seed")
+
+	_, err := subject.CreateUser(ctx, dto.CreateUserRequest{Name: "John Doe"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer secret", authorization)
+	assert.Equal(t, "seed", actor)
+}
+
+func TestUserServiceClient_CreateUser_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, `{"error": "The service cannot save changes right now, please try again later"}`)
+	}))
+	defer server.Close()
+
+	subject := NewUserServiceClient(server.URL, "", WithMaxRetries(1))
+	_, err := subject.CreateUser(context.Background(), dto.CreateUserRequest{Name: "John Doe"})
+
+	var appErr exception.ApplicationError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, exception.GetHTTPStatusCodeByErr(err))
+}
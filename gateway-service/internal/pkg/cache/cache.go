@@ -0,0 +1,409 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status describes how a value was served by the cache.
+type Status string
+
+const (
+	StatusHit    Status = "HIT"
+	StatusMiss   Status = "MISS"
+	StatusStale  Status = "STALE"
+	StatusBypass Status = "BYPASS"
+)
+
+// loadTimeout bounds the loads of misses and the background refreshes of
+// stale entries, they don't stop when the caller that started them goes away.
+var loadTimeout = 30 * time.Second
+
+// Loader fetches a fresh value for a key on cache miss or revalidation.
+type Loader[V any] func(ctx context.Context) (V, error)
+
+// load is a miss being loaded, the callers missing the same key meanwhile
+// wait for its result instead of calling the loader again. current is cleared,
+// with the cache mutex held, when the key is invalidated during the load so
+// the value read before the change isn't stored.
+type load[V any] struct {
+	done    chan struct{}
+	tags    []string
+	current bool
+	value   V
+	err     error
+}
+
+type entry[V any] struct {
+	value     V
+	tags      []string
+	expiresAt time.Time
+	staleAt   time.Time
+}
+
+// Stats holds cache counters since start.
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	StaleHits     uint64
+	Revalidations uint64
+	Invalidations uint64
+	Evictions     uint64
+	Entries       int
+}
+
+// Cache is an in-memory TTL cache with optional stale-while-revalidate.
+// An entry is fresh for ttl, after that it is served stale for up to staleTTL
+// while a single background refresh runs. Entries can be tagged so a group of
+// keys can be invalidated at once. Concurrent misses of a key share a single
+// load.
+type Cache[V any] struct {
+	mu           sync.Mutex
+	entries      map[string]*entry[V]
+	tags         map[string]map[string]struct{}
+	revalidating map[string]bool
+	loading      map[string]*load[V]
+	ttl          time.Duration
+	staleTTL     time.Duration
+	maxEntries   int
+	now          func() time.Time
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	staleHits     atomic.Uint64
+	revalidations atomic.Uint64
+	invalidations atomic.Uint64
+	evictions     atomic.Uint64
+}
+
+type Option[V any] func(*Cache[V])
+
+// WithStaleTTL enables stale-while-revalidate for the given window.
+func WithStaleTTL[V any](staleTTL time.Duration) Option[V] {
+	return func(c *Cache[V]) {
+		c.staleTTL = staleTTL
+	}
+}
+
+// WithMaxEntries bounds the number of cached keys, zero means unbounded.
+func WithMaxEntries[V any](maxEntries int) Option[V] {
+	return func(c *Cache[V]) {
+		c.maxEntries = maxEntries
+	}
+}
+
+func New[V any](ttl time.Duration, opts ...Option[V]) *Cache[V] {
+	c := &Cache[V]{
+		entries:      make(map[string]*entry[V]),
+		tags:         make(map[string]map[string]struct{}),
+		revalidating: make(map[string]bool),
+		loading:      make(map[string]*load[V]),
+		ttl:          ttl,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Get returns the cached value for key and whether it is still fresh.
+func (c *Cache[V]) Get(key string) (V, Status) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookup(key)
+}
+
+// Set stores value under key with the given invalidation tags.
+func (c *Cache[V]) Set(key string, value V, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.store(key, value, tags)
+}
+
+// GetOrLoad serves key from the cache, loading it with loader on miss. Stale
+// entries are returned immediately and refreshed in the background. A miss
+// waits for the load already running for key, if any, and shares its result.
+// The load outlives the callers, each of them stops waiting when its ctx is
+// done.
+func (c *Cache[V]) GetOrLoad(ctx context.Context, key string, loader Loader[V],
+	tags ...string,
+) (V, Status, error) {
+	c.mu.Lock()
+	value, status := c.lookup(key)
+
+	switch status {
+	case StatusStale:
+		_, running := c.revalidating[key]
+		if !running {
+			c.revalidating[key] = true
+
+			go c.revalidate(context.WithoutCancel(ctx), key, loader, tags)
+		}
+	case StatusMiss:
+		if running, ok := c.loading[key]; ok {
+			c.mu.Unlock()
+
+			return c.wait(ctx, running)
+		}
+	}
+
+	if status != StatusMiss {
+		c.mu.Unlock()
+
+		return value, status, nil
+	}
+
+	running := &load[V]{done: make(chan struct{}), tags: tags, current: true}
+	c.loading[key] = running
+	c.mu.Unlock()
+
+	go c.load(context.WithoutCancel(ctx), key, running, loader)
+
+	return c.wait(ctx, running)
+}
+
+// load runs loader for the callers waiting on running, a panic of loader is
+// returned to them as an error.
+func (c *Cache[V]) load(ctx context.Context, key string, running *load[V], loader Loader[V]) {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			running.err = fmt.Errorf("loader panicked: %v", r)
+		}
+
+		c.mu.Lock()
+		delete(c.loading, key)
+
+		if running.err == nil && running.current {
+			c.store(key, running.value, running.tags)
+		}
+		c.mu.Unlock()
+
+		close(running.done)
+	}()
+
+	running.value, running.err = loader(ctx)
+}
+
+// wait returns the result of running, or gives up when ctx is done.
+func (c *Cache[V]) wait(ctx context.Context, running *load[V]) (V, Status, error) {
+	select {
+	case <-running.done:
+		return running.result()
+	case <-ctx.Done():
+		var zero V
+
+		return zero, StatusMiss, fmt.Errorf("wait for cache value: %w", ctx.Err())
+	}
+}
+
+func (l *load[V]) result() (V, Status, error) {
+	if l.err != nil {
+		var zero V
+
+		return zero, StatusMiss, fmt.Errorf("load cache value: %w", l.err)
+	}
+
+	return l.value, StatusMiss, nil
+}
+
+// Invalidate removes key from the cache.
+func (c *Cache[V]) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		c.invalidations.Add(1)
+		c.remove(key)
+	}
+
+	if running, ok := c.loading[key]; ok {
+		running.current = false
+	}
+}
+
+// InvalidateTag removes every key stored with tag.
+func (c *Cache[V]) InvalidateTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tags[tag] {
+		c.invalidations.Add(1)
+		c.remove(key)
+	}
+
+	for _, running := range c.loading {
+		if slices.Contains(running.tags, tag) {
+			running.current = false
+		}
+	}
+}
+
+// Purge removes every entry.
+func (c *Cache[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidations.Add(uint64(len(c.entries)))
+	c.entries = make(map[string]*entry[V])
+	c.tags = make(map[string]map[string]struct{})
+
+	for key := range c.revalidating {
+		c.revalidating[key] = false
+	}
+
+	for _, running := range c.loading {
+		running.current = false
+	}
+}
+
+func (c *Cache[V]) Stats() Stats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		StaleHits:     c.staleHits.Load(),
+		Revalidations: c.revalidations.Load(),
+		Invalidations: c.invalidations.Load(),
+		Evictions:     c.evictions.Load(),
+		Entries:       entries,
+	}
+}
+
+func (c *Cache[V]) revalidate(ctx context.Context, key string, loader Loader[V], tags []string) {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	c.revalidations.Add(1)
+
+	value, err := loader(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.revalidating[key]
+	delete(c.revalidating, key)
+
+	if err != nil || !current {
+		// keep serving the stale entry until it expires, or drop the result
+		// when the entry was invalidated while it was being refreshed
+		return
+	}
+
+	c.store(key, value, tags)
+}
+
+// lookup must be called with c.mu held.
+func (c *Cache[V]) lookup(key string) (V, Status) {
+	var zero V
+
+	item, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+
+		return zero, StatusMiss
+	}
+
+	now := c.now()
+
+	switch {
+	case now.Before(item.staleAt):
+		c.hits.Add(1)
+
+		return item.value, StatusHit
+	case now.Before(item.expiresAt):
+		c.staleHits.Add(1)
+
+		return item.value, StatusStale
+	default:
+		c.remove(key)
+		c.misses.Add(1)
+
+		return zero, StatusMiss
+	}
+}
+
+// store must be called with c.mu held.
+func (c *Cache[V]) store(key string, value V, tags []string) {
+	if _, ok := c.entries[key]; ok {
+		c.remove(key)
+	}
+
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evictOldest()
+	}
+
+	now := c.now()
+	staleAt := now.Add(c.ttl)
+
+	c.entries[key] = &entry[V]{
+		value:     value,
+		tags:      tags,
+		staleAt:   staleAt,
+		expiresAt: staleAt.Add(c.staleTTL),
+	}
+
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+
+		c.tags[tag][key] = struct{}{}
+	}
+}
+
+// evictOldest drops the entry closest to expiry, must be called with c.mu held.
+func (c *Cache[V]) evictOldest() {
+	var (
+		oldestKey string
+		oldest    time.Time
+	)
+
+	for key, item := range c.entries {
+		if oldestKey == "" || item.expiresAt.Before(oldest) {
+			oldestKey = key
+			oldest = item.expiresAt
+		}
+	}
+
+	if oldestKey != "" {
+		c.evictions.Add(1)
+		c.remove(oldestKey)
+	}
+}
+
+// remove must be called with c.mu held.
+func (c *Cache[V]) remove(key string) {
+	item, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	if _, running := c.revalidating[key]; running {
+		c.revalidating[key] = false
+	}
+
+	for _, tag := range item.tags {
+		delete(c.tags[tag], key)
+
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+
+	delete(c.entries, key)
+}
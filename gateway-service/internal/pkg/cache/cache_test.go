@@ -0,0 +1,313 @@
+//go:build unit
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(ttl, staleTTL time.Duration, maxEntries int) (*Cache[string], *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	c := New(ttl, WithStaleTTL[string](staleTTL), WithMaxEntries[string](maxEntries))
+	c.now = clock.Now
+
+	return c, clock
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	c, clock := newTestCache(time.Minute, 0, 0)
+
+	var calls atomic.Int32
+	loader := func(_ context.Context) (string, error) {
+		calls.Add(1)
+
+		return "value", nil
+	}
+
+	got, status, err := c.GetOrLoad(context.Background(), "key", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", got)
+	assert.Equal(t, StatusMiss, status)
+
+	got, status, err = c.GetOrLoad(context.Background(), "key", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", got)
+	assert.Equal(t, StatusHit, status)
+	assert.Equal(t, int32(1), calls.Load())
+
+	clock.Advance(2 * time.Minute)
+
+	_, status, err = c.GetOrLoad(context.Background(), "key", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusMiss, status)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_GetOrLoadError(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 0, 0)
+	errLoad := errors.New("downstream down")
+
+	_, _, err := c.GetOrLoad(context.Background(), "key", func(_ context.Context) (string, error) {
+		return "", errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+
+	_, status := c.Get("key")
+	assert.Equal(t, StatusMiss, status)
+}
+
+func TestCache_GetOrLoadSharesMiss(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 0, 0)
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	loader := func(_ context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+
+		return "value", nil
+	}
+
+	const callers = 10
+
+	var wg sync.WaitGroup
+
+	for range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			got, status, err := c.GetOrLoad(context.Background(), "key", loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "value", got)
+			assert.Equal(t, StatusMiss, status)
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return c.Stats().Misses == callers
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, status := c.Get("key")
+	assert.Equal(t, StatusHit, status)
+}
+
+func TestCache_GetOrLoadWaitersShareError(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 0, 0)
+	errLoad := errors.New("downstream down")
+
+	loading := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		_, _, err := c.GetOrLoad(context.Background(), "key", func(_ context.Context) (string, error) {
+			close(loading)
+			<-release
+
+			return "", errLoad
+		})
+		assert.ErrorIs(t, err, errLoad)
+	}()
+
+	<-loading
+
+	// a waiter gives up with its own context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := c.GetOrLoad(ctx, "key", func(_ context.Context) (string, error) {
+		t.Error("loader called while a load is running")
+
+		return "", nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return len(c.loading) == 0
+	}, time.Second, time.Millisecond)
+
+	// the failed load is not cached, the next miss loads again
+	got, _, err := c.GetOrLoad(context.Background(), "key", func(_ context.Context) (string, error) {
+		return "value", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "value", got)
+}
+
+func TestCache_GetOrLoadOutlivesCaller(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 0, 0)
+
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (string, error) {
+		close(loading)
+		<-release
+
+		return "value", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+
+	go func() {
+		_, _, err := c.GetOrLoad(ctx, "key", loader)
+		canceled <- err
+	}()
+
+	<-loading
+
+	waited := make(chan string)
+
+	go func() {
+		got, _, err := c.GetOrLoad(context.Background(), "key", loader)
+		assert.NoError(t, err)
+		waited <- got
+	}()
+
+	// the caller that started the load gives up, the other one still waits
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+
+	assert.Eventually(t, func() bool {
+		return c.Stats().Misses == 2
+	}, time.Second, time.Millisecond)
+	close(release)
+
+	assert.Equal(t, "value", <-waited)
+
+	_, status := c.Get("key")
+	assert.Equal(t, StatusHit, status)
+}
+
+func TestCache_InvalidateDuringLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(c *Cache[string])
+		wantStored bool
+	}{
+		{name: "key", invalidate: func(c *Cache[string]) { c.Invalidate("key") }},
+		{name: "tag", invalidate: func(c *Cache[string]) { c.InvalidateTag("user:1") }},
+		{name: "purge", invalidate: (*Cache[string]).Purge},
+		{name: "other tag", invalidate: func(c *Cache[string]) { c.InvalidateTag("user:2") }, wantStored: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestCache(time.Minute, 0, 0)
+
+			loading := make(chan struct{})
+			release := make(chan struct{})
+			loaded := make(chan string)
+
+			go func() {
+				got, _, err := c.GetOrLoad(context.Background(), "key", func(_ context.Context) (string, error) {
+					close(loading)
+					<-release
+
+					return "before", nil
+				}, "user:1", "all")
+				assert.NoError(t, err)
+				loaded <- got
+			}()
+
+			<-loading
+			tt.invalidate(c)
+			close(release)
+
+			// the callers get the value they asked for, it is only cached
+			// when it is still current
+			assert.Equal(t, "before", <-loaded)
+
+			_, status := c.Get("key")
+			assert.Equal(t, tt.wantStored, status == StatusHit)
+		})
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	c, clock := newTestCache(time.Minute, time.Minute, 0)
+	c.Set("key", "old")
+
+	clock.Advance(90 * time.Second)
+
+	refreshed := make(chan struct{})
+	got, status, err := c.GetOrLoad(context.Background(), "key", func(_ context.Context) (string, error) {
+		defer close(refreshed)
+
+		return "new", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "old", got)
+	assert.Equal(t, StatusStale, status)
+
+	<-refreshed
+	assert.Eventually(t, func() bool {
+		value, status := c.Get("key")
+
+		return status == StatusHit && value == "new"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCache_InvalidateTag(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 0, 0)
+	c.Set("all:1", "a", "all")
+	c.Set("user:1", "b", "user:1")
+	c.Set("user:2", "c", "user:2")
+
+	c.InvalidateTag("user:1")
+	c.InvalidateTag("all")
+
+	_, status := c.Get("all:1")
+	assert.Equal(t, StatusMiss, status)
+	_, status = c.Get("user:1")
+	assert.Equal(t, StatusMiss, status)
+	_, status = c.Get("user:2")
+	assert.Equal(t, StatusHit, status)
+	assert.Equal(t, uint64(2), c.Stats().Invalidations)
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	c, clock := newTestCache(time.Minute, 0, 2)
+	c.Set("a", "a")
+	clock.Advance(time.Second)
+	c.Set("b", "b")
+	clock.Advance(time.Second)
+	c.Set("c", "c")
+
+	_, status := c.Get("a")
+	assert.Equal(t, StatusMiss, status)
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Evictions)
+}
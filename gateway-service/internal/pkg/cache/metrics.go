@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/metrics"
+)
+
+// RegisterMetrics exports the statistics of c with the given metric name
+// prefix, they are read from Cache.Stats on every scrape.
+func RegisterMetrics[V any](registry *metrics.Registry, prefix string, c *Cache[V]) {
+	registry.NewCounterFunc(prefix+"_hits_total", "Total number of lookups served by a fresh entry.",
+		func() float64 { return float64(c.Stats().Hits) })
+	registry.NewCounterFunc(prefix+"_misses_total", "Total number of lookups finding no usable entry.",
+		func() float64 { return float64(c.Stats().Misses) })
+	registry.NewCounterFunc(prefix+"_stale_hits_total", "Total number of lookups served by a stale entry.",
+		func() float64 { return float64(c.Stats().StaleHits) })
+	registry.NewCounterFunc(prefix+"_revalidations_total", "Total number of background refreshes of stale entries.",
+		func() float64 { return float64(c.Stats().Revalidations) })
+	registry.NewCounterFunc(prefix+"_invalidations_total", "Total number of entries invalidated.",
+		func() float64 { return float64(c.Stats().Invalidations) })
+	registry.NewCounterFunc(prefix+"_evictions_total", "Total number of entries evicted to stay under the max entries.",
+		func() float64 { return float64(c.Stats().Evictions) })
+	registry.NewGaugeFunc(prefix+"_entries", "Number of entries currently cached.",
+		func() float64 { return float64(c.Stats().Entries) })
+}
@@ -0,0 +1,31 @@
+//go:build unit
+
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 0, 0)
+	registry := metrics.NewRegistry()
+	RegisterMetrics(registry, "listing_cache", c)
+
+	c.Set("key", "value", "all")
+	c.Get("key")
+	c.Get("other")
+	c.InvalidateTag("all")
+
+	var out bytes.Buffer
+	assert.NoError(t, registry.Write(&out))
+
+	assert.Contains(t, out.String(), "listing_cache_hits_total 1\n")
+	assert.Contains(t, out.String(), "listing_cache_misses_total 1\n")
+	assert.Contains(t, out.String(), "listing_cache_invalidations_total 1\n")
+	assert.Contains(t, out.String(), "listing_cache_entries 0\n")
+}
@@ -0,0 +1,255 @@
+// Package contract checks the events of a service against the golden event
+// fixtures of the repository, contracts/events/<subject>/v<N>.json.
+//
+// A fixture is an example payload of a version of a subject. Producers emit
+// the shape of the latest version, consumers decode every version, so a
+// payload change needs a new version and breaks the tests of the services
+// that can't handle it.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var fileName = regexp.MustCompile(`^v(\d+)\.json$`)
+
+// Fixture is the example payload of a version of a subject.
+type Fixture struct {
+	Subject string
+	Version int
+	Payload []byte
+}
+
+func (f Fixture) String() string {
+	return fmt.Sprintf("%s/v%d", f.Subject, f.Version)
+}
+
+// Load reads the fixtures of subject in fsys, oldest version first. The
+// versions must start at 1 without gaps, the old ones are never removed.
+func Load(fsys fs.FS, subject string) ([]Fixture, error) {
+	entries, err := fs.ReadDir(fsys, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures of %s: %w", subject, err)
+	}
+
+	fixtures := make([]Fixture, 0, len(entries))
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("unexpected fixture %s/%s, want v<N>.json", subject, entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixture version %q: %w", match[1], err)
+		}
+
+		payload, err := fs.ReadFile(fsys, subject+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+
+		if !json.Valid(payload) {
+			return nil, fmt.Errorf("fixture %s/%s isn't valid JSON", subject, entry.Name())
+		}
+
+		fixtures = append(fixtures, Fixture{Subject: subject, Version: version, Payload: payload})
+	}
+
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Version < fixtures[j].Version })
+
+	for i, fixture := range fixtures {
+		if fixture.Version != i+1 {
+			return nil, fmt.Errorf("fixtures of %s have no v%d", subject, i+1)
+		}
+	}
+
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixture for %s", subject)
+	}
+
+	return fixtures, nil
+}
+
+// Latest returns the fixture of the newest version of subject.
+func Latest(fsys fs.FS, subject string) (Fixture, error) {
+	fixtures, err := Load(fsys, subject)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	return fixtures[len(fixtures)-1], nil
+}
+
+// SameShape compares payload with the fixture: the same fields at every
+// level, with the same JSON types. The values don't matter.
+func SameShape(fixture Fixture, payload []byte) error {
+	want, err := decode(fixture.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode fixture %s: %w", fixture, err)
+	}
+
+	got, err := decode(payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	return errors.Join(compareShape(want, got, "")...)
+}
+
+// Preserved checks that decoded, the value a consumer decoded from the
+// fixture, kept the values of the fields it declares. The fields it doesn't
+// declare are ignored, a consumer reads the part of the payload it needs.
+func Preserved(fixture Fixture, decoded any) error {
+	want, err := decode(fixture.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode fixture %s: %w", fixture, err)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to encode decoded value: %w", err)
+	}
+
+	got, err := decode(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode decoded value: %w", err)
+	}
+
+	return errors.Join(comparePreserved(want, got, "")...)
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// keep the integers exact, a float64 rounds the large IDs
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return v, nil
+}
+
+func compareShape(want, got any, path string) []error {
+	if kindOf(want) != kindOf(got) {
+		return []error{fmt.Errorf("%s: is %s, want %s", pathOrRoot(path), kindOf(got), kindOf(want))}
+	}
+
+	switch want := want.(type) {
+	case map[string]any:
+		got := got.(map[string]any) //nolint:forcetypeassert
+
+		var errs []error
+
+		for _, key := range sortedKeys(want) {
+			value, ok := got[key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: missing", join(path, key)))
+
+				continue
+			}
+
+			errs = append(errs, compareShape(want[key], value, join(path, key))...)
+		}
+
+		for _, key := range sortedKeys(got) {
+			if _, ok := want[key]; !ok {
+				errs = append(errs, fmt.Errorf("%s: not in the fixture", join(path, key)))
+			}
+		}
+
+		return errs
+	case []any:
+		got := got.([]any) //nolint:forcetypeassert
+
+		// the first element of the fixture is the shape of every element
+		if len(want) == 0 {
+			return nil
+		}
+
+		var errs []error
+
+		for i, value := range got {
+			errs = append(errs, compareShape(want[0], value, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+		return errs
+	default:
+		return nil
+	}
+}
+
+func comparePreserved(want, got any, path string) []error {
+	wantObject, wantIsObject := want.(map[string]any)
+	gotObject, gotIsObject := got.(map[string]any)
+
+	if wantIsObject && gotIsObject {
+		var errs []error
+
+		for _, key := range sortedKeys(wantObject) {
+			if value, ok := gotObject[key]; ok {
+				errs = append(errs, comparePreserved(wantObject[key], value, join(path, key))...)
+			}
+		}
+
+		return errs
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		return []error{fmt.Errorf("%s: decoded as %v, sent %v", pathOrRoot(path), got, want)}
+	}
+
+	return nil
+}
+
+func kindOf(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func join(path, key string) string {
+	return strings.TrimPrefix(path+"."+key, ".")
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "payload"
+	}
+
+	return path
+}
@@ -5,13 +5,14 @@ import (
 	"log/slog"
 
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/configcheck"
 	// Register PostgreSQL driver.
 	_ "github.com/lib/pq"
 )
 
 // InitDB initialises PG database connection.
 func InitDB(cfg config.Config) *sql.DB {
-	slog.Debug("connecting to DB...", slog.String("dsn", cfg.DB.DSN))
+	slog.Debug("connecting to DB...", slog.String("dsn", configcheck.Mask(cfg.DB.DSN)))
 
 	database, err := sql.Open("postgres", cfg.DB.DSN)
 	if err != nil {
@@ -16,6 +16,7 @@ const (
 	CodeUnauthorized  = http.StatusUnauthorized
 	CodeForbidden     = http.StatusForbidden
 	CodeConflict      = http.StatusConflict
+	CodeUnavailable   = http.StatusServiceUnavailable
 )
 
 var (
@@ -50,6 +51,16 @@ var (
 		},
 		StatusCode: CodeConflict,
 	}
+
+	// ErrServiceUnavailable is returned when a downstream service answers it
+	// is temporarily unavailable, for instance user-service without NATS.
+	ErrServiceUnavailable = ApplicationError{
+		Localizable: lang.Localizable{
+			MessageID: "errors.service_unavailable",
+			Message:   "service unavailable",
+		},
+		StatusCode: CodeUnavailable,
+	}
 )
 
 // ApplicationError handles application level errors.
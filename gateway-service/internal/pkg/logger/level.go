@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultLevel is the level of the logger installed by InitStructuredLogger.
+var DefaultLevel = NewLevelController(slog.LevelInfo)
+
+// LevelStatus describes the level in effect, RevertAt is zero without
+// override.
+type LevelStatus struct {
+	Level      slog.Level
+	Configured slog.Level
+	RevertAt   time.Time
+}
+
+// LevelController is a slog.Leveler combining the configured level with a
+// temporary override, for instance to debug a live process without
+// restarting it.
+type LevelController struct {
+	level *slog.LevelVar
+
+	mu         sync.Mutex
+	configured slog.Level
+	overridden bool
+	revertAt   time.Time
+	timer      *time.Timer
+}
+
+func NewLevelController(configured slog.Level) *LevelController {
+	controller := &LevelController{
+		level:      new(slog.LevelVar),
+		configured: configured,
+	}
+
+	controller.level.Set(configured)
+
+	return controller
+}
+
+func (c *LevelController) Level() slog.Level {
+	return c.level.Level()
+}
+
+// SetConfigured changes the configured level, an override in progress keeps
+// precedence until it expires.
+func (c *LevelController) SetConfigured(level slog.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configured = level
+
+	if !c.overridden {
+		c.level.Set(level)
+	}
+}
+
+// Override sets level for duration then reverts to the configured level, a
+// new override replaces the one in progress.
+func (c *LevelController) Override(level slog.Level, duration time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+
+	c.overridden = true
+	c.revertAt = time.Now().Add(duration)
+	c.level.Set(level)
+
+	var timer *time.Timer
+
+	timer = time.AfterFunc(duration, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// a newer override owns the level
+		if c.timer != timer {
+			return
+		}
+
+		c.reset()
+	})
+	c.timer = timer
+
+	return c.revertAt
+}
+
+// Reset ends the override in progress.
+func (c *LevelController) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+
+	c.reset()
+}
+
+func (c *LevelController) reset() {
+	c.timer = nil
+	c.overridden = false
+	c.revertAt = time.Time{}
+	c.level.Set(c.configured)
+}
+
+func (c *LevelController) Status() LevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return LevelStatus{
+		Level:      c.level.Level(),
+		Configured: c.configured,
+		RevertAt:   c.revertAt,
+	}
+}
@@ -0,0 +1,41 @@
+//go:build unit
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextHandler(t *testing.T) {
+	var (
+		out    = new(bytes.Buffer)
+		logger = slog.New(NewContextHandler(slog.NewJSONHandler(out, nil)))
+		ctx    = ContextWithAttrs(context.Background(), slog.String("request_id", "abc"))
+	)
+
+	ctx = ContextWithAttrs(ctx, slog.String("subject", "user.created"))
+
+	logger.InfoContext(ctx, "hello")
+
+	var record map[string]any
+	err := json.Unmarshal(out.Bytes(), &record)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "abc", record["request_id"])
+	assert.Equal(t, "user.created", record["subject"])
+
+	out.Reset()
+	logger.Info("no context")
+
+	record = map[string]any{}
+	err = json.Unmarshal(out.Bytes(), &record)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, record, "request_id")
+}
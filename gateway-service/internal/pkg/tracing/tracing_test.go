@@ -0,0 +1,226 @@
+//go:build unit
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanRecorder keeps the exported spans after the tracer is shut down, the
+// in-memory exporter of the SDK forgets them.
+type spanRecorder struct {
+	*tracetest.InMemoryExporter
+}
+
+func (spanRecorder) Shutdown(context.Context) error {
+	return nil
+}
+
+func newSpanRecorder() spanRecorder {
+	return spanRecorder{InMemoryExporter: tracetest.NewInMemoryExporter()}
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("child spans share the trace of their parent", func(t *testing.T) {
+		exporter := newSpanRecorder()
+		tracer := NewTracer("gateway-service", exporter)
+
+		ctx, parent := tracer.Start(context.Background(), "GET /users", SpanKindServer)
+		_, child := tracer.Start(ctx, "UserRepository.GetByID", SpanKindClient)
+
+		child.SetAttribute("db.system", "postgresql")
+		child.SetAttribute("db.rows", 3)
+		child.RecordError(errors.New("connection refused"))
+		child.RecordError(nil)
+		child.End()
+		parent.End()
+		parent.End()
+
+		err := tracer.Shutdown(context.Background())
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, "UserRepository.GetByID", spans[0].Name)
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+		assert.Contains(t, spans[0].Resource.Attributes(), attribute.String("service.name", "gateway-service"))
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+		assert.Contains(t, spans[0].Attributes, attribute.String("db.system", "postgresql"))
+		assert.Contains(t, spans[0].Attributes, attribute.Int("db.rows", 3))
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "connection refused", spans[0].Status.Description)
+		assert.False(t, spans[1].Parent.IsValid())
+	})
+
+	t.Run("unsampled trace is propagated but not recorded", func(t *testing.T) {
+		exporter := newSpanRecorder()
+		tracer := NewTracer("gateway-service", exporter)
+
+		remote := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{1},
+			SpanID:  trace.SpanID{2},
+			Remote:  true,
+		})
+
+		_, span := tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), remote),
+			"GET /users", SpanKindServer)
+		span.End()
+
+		err := tracer.Shutdown(context.Background())
+		assert.NoError(t, err)
+
+		assert.False(t, span.IsRecording())
+		assert.Equal(t, trace.TraceID{1}, span.SpanContext().TraceID())
+		assert.Empty(t, exporter.GetSpans())
+	})
+
+	t.Run("start without tracer does nothing", func(t *testing.T) {
+		ctx, span := Start(context.Background(), "GET /users", SpanKindServer)
+		span.SetAttribute("http.method", "GET")
+		span.RecordError(errors.New("not found"))
+		span.End()
+
+		assert.False(t, span.IsRecording())
+		assert.False(t, SpanFromContext(ctx).SpanContext().IsValid())
+	})
+}
+
+func TestPropagation(t *testing.T) {
+	t.Run("extract without tracer", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		ctx := Extract(context.Background(), header)
+		assert.False(t, SpanFromContext(ctx).SpanContext().IsValid())
+	})
+
+	SetTracer(NewTracer("gateway-service", newSpanRecorder()))
+	defer SetTracer(nil)
+
+	t.Run("inject and extract round trip", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		header.Set(TracestateHeader, "vendor=value")
+
+		ctx, span := Start(Extract(context.Background(), header), "GET /users", SpanKindServer)
+		defer span.End()
+
+		out := http.Header{}
+		Inject(ctx, out)
+
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanContext().SpanID().String()+"-01",
+			out.Get(TraceparentHeader))
+		assert.Equal(t, "vendor=value", out.Get(TracestateHeader))
+	})
+
+	t.Run("inject without span", func(t *testing.T) {
+		header := http.Header{}
+		Inject(context.Background(), header)
+
+		assert.Empty(t, header)
+	})
+
+	tests := []struct {
+		name        string
+		traceparent string
+		valid       bool
+		sampled     bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"future version with extra field", "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what", true, true},
+		{"version 00 with extra field", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what", false, false},
+		{"forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"short span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01", false, false},
+		{"empty", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(TraceparentHeader, tt.traceparent)
+
+			sc := SpanFromContext(Extract(context.Background(), header)).SpanContext()
+
+			assert.Equal(t, tt.valid, sc.IsValid())
+			assert.Equal(t, tt.sampled, sc.IsSampled())
+		})
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	out := new(bytes.Buffer)
+	exporter, err := NewWriterExporter(out)
+	assert.NoError(t, err)
+
+	tracer := NewTracer("user-service", exporter)
+	_, span := tracer.Start(context.Background(), "POST /users", SpanKindServer)
+	span.End()
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	var line map[string]any
+	err = json.Unmarshal(out.Bytes(), &line)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "POST /users", line["Name"])
+	assert.Equal(t, float64(SpanKindServer), line["SpanKind"])
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+
+	exporter, err := NewFileExporter(path)
+	assert.NoError(t, err)
+
+	tracer := NewTracer("user-service", exporter)
+	_, span := tracer.Start(context.Background(), "POST /users", SpanKindServer)
+	span.End()
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"POST /users"`)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body) //nolint:errcheck
+		requests <- r
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(context.Background(), server.URL+"/v1/traces", time.Second)
+	assert.NoError(t, err)
+
+	tracer := NewTracer("user-service", exporter)
+	_, span := tracer.Start(context.Background(), "POST /users", SpanKindServer)
+	span.End()
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	req := <-requests
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/v1/traces", req.URL.Path)
+	assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
 	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/exception"
 )
@@ -19,6 +20,18 @@ import (
 func ResponseWithBody(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
+	if headerer, ok := response.(kithttp.Headerer); ok {
+		for key, values := range headerer.Headers() {
+			for _, value := range values {
+				w.Header().Add(key, value)
+			}
+		}
+	}
+
+	if statusCoder, ok := response.(kithttp.StatusCoder); ok {
+		w.WriteHeader(statusCoder.StatusCode())
+	}
+
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		return fmt.Errorf("encode response body: %w", err)
 	}
@@ -45,7 +58,7 @@ func ErrorResponse(ctx context.Context, err error, respWriter http.ResponseWrite
 	)
 
 	respWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
-	slog.Info("error", "error", err)
+	slog.InfoContext(ctx, "error", "error", err)
 
 	if errors.As(err, &appErr) {
 		respWriter.WriteHeader(appErr.StatusCode)
@@ -54,7 +67,7 @@ func ErrorResponse(ctx context.Context, err error, respWriter http.ResponseWrite
 		// in case of failure to get request context, default language will be used
 		message = appErr.Localize(reqContext.Language)
 
-		slog.Default().Debug("error", "cause", err.Error())
+		slog.Default().DebugContext(ctx, "error", "cause", err.Error())
 	} else {
 		respWriter.WriteHeader(http.StatusInternalServerError)
 
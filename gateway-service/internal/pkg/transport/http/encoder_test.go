@@ -60,3 +60,38 @@ func TestCreatedResponse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusCreated, resp.Code)
 }
+
+type headerResponse struct {
+	Foo string `json:"foo"`
+}
+
+func (headerResponse) Headers() http.Header {
+	return http.Header{"X-Cache": []string{"HIT"}}
+}
+
+func TestEncodeJSONResponseWithHeaders(t *testing.T) {
+	resp := httptest.NewRecorder()
+	err := ResponseWithBody(context.Background(), resp, headerResponse{Foo: "bar"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "HIT", resp.Result().Header.Get("X-Cache"))
+	assert.JSONEq(t, `{"foo": "bar"}`, resp.Body.String())
+}
+
+type acceptedResponse struct {
+	Foo string `json:"foo"`
+}
+
+func (acceptedResponse) StatusCode() int {
+	return http.StatusAccepted
+}
+
+func TestEncodeJSONResponseWithStatusCode(t *testing.T) {
+	resp := httptest.NewRecorder()
+	err := ResponseWithBody(context.Background(), resp, acceptedResponse{Foo: "bar"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Result().Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"foo": "bar"}`, resp.Body.String())
+}
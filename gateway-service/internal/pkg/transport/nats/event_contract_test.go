@@ -0,0 +1,33 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/contract"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+// contractsDir holds the golden event fixtures shared by the services.
+const contractsDir = "../../../../../contracts/events"
+
+func TestDecoder_AcceptsEveryListingCreatedVersion(t *testing.T) {
+	fixtures, err := contract.Load(os.DirFS(contractsDir), "listing.created")
+	assert.NoError(t, err)
+
+	decoder := NewDecoder[dto.ListingCreated]()
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.String(), func(t *testing.T) {
+			decoded, err := decoder(context.Background(), &nats.Msg{Subject: fixture.Subject, Data: fixture.Payload})
+			if assert.NoError(t, err) {
+				assert.NoError(t, contract.Preserved(fixture, decoded))
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/timing"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Consumer is a durable JetStream consumer, instances sharing the name split
+// the messages between them. A message is acked once the endpoint succeeded
+// and redelivered on error until maxDeliver is reached.
+type Consumer[T any] struct {
+	stream        jetstream.Stream
+	name          string
+	subject       string
+	maxDeliver    int
+	slowThreshold time.Duration
+	ep            endpoint.Endpoint
+	dec           Decoder[T]
+	consumer      jetstream.Consumer
+	consumerCtx   jetstream.ConsumeContext
+}
+
+func NewConsumer[T any](
+	ctx context.Context,
+	stream jetstream.Stream,
+	name string,
+	subject string,
+	maxDeliver int,
+	slowThreshold time.Duration,
+	ep endpoint.Endpoint,
+	dec Decoder[T],
+) (*Consumer[T], error) {
+	c := &Consumer[T]{
+		stream:        stream,
+		name:          name,
+		subject:       subject,
+		maxDeliver:    maxDeliver,
+		slowThreshold: slowThreshold,
+		ep:            ep,
+		dec:           dec,
+	}
+
+	if err := c.createConsumer(ctx); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Consumer[T]) createConsumer(ctx context.Context) error {
+	cons, err := c.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       c.name,
+		FilterSubject: c.subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		MaxDeliver:    c.maxDeliver,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update consumer: %w", err)
+	}
+
+	c.consumer = cons
+
+	return nil
+}
+
+func (c *Consumer[T]) Start(ctx context.Context) error {
+	slog.Info("starting nats consumer", "consumer", c.name, "subject", c.subject)
+
+	var err error
+
+	c.consumerCtx, err = c.consumer.Consume(func(msg jetstream.Msg) {
+		// reuse the core NATS decoders, they only need the subject, data and headers
+		coreMsg := &nats.Msg{Subject: msg.Subject(), Data: msg.Data(), Header: msg.Headers()}
+		msgCtx := dto.ContextWithRequestID(ctx, requestID(coreMsg))
+
+		msgCtx, span := startHandlerSpan(msgCtx, msg.Subject(), msg.Headers())
+		defer span.End()
+
+		msgCtx, breakdown := timing.ContextWithBreakdown(msgCtx)
+		start := time.Now()
+
+		defer func() {
+			observeHandler(msgCtx, c.subject, start, c.slowThreshold, breakdown)
+		}()
+
+		request, err := c.dec(msgCtx, coreMsg)
+		if err != nil {
+			// a malformed message never decodes, retrying it is pointless
+			slog.ErrorContext(msgCtx, "failed to decode message", "subject", msg.Subject(), "error", err)
+			span.RecordError(err)
+			messagesFailed.WithLabelValues(c.subject).Inc()
+			msg.Term() //nolint:errcheck
+
+			return
+		}
+
+		if _, err = c.ep(msgCtx, request); err != nil {
+			slog.ErrorContext(msgCtx, "failed to execute endpoint", "subject", msg.Subject(), "error", err)
+			span.RecordError(err)
+			messagesFailed.WithLabelValues(c.subject).Inc()
+			messagesNaked.WithLabelValues(c.subject).Inc()
+			msg.Nak() //nolint:errcheck
+
+			return
+		}
+
+		if err := msg.Ack(); err != nil {
+			slog.ErrorContext(msgCtx, "failed to ack message", "subject", msg.Subject(), "error", err)
+
+			return
+		}
+
+		messagesProcessed.WithLabelValues(c.subject).Inc()
+
+		slog.InfoContext(msgCtx, "successfully processing message",
+			slog.String("type", "inbound"),
+			slog.String("transport", "nats"),
+			slog.String("subject", msg.Subject()),
+		)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Consumer[T]) Stop() {
+	slog.Info("stopping nats consumer", "consumer", c.name, "subject", c.subject)
+
+	if c.consumerCtx != nil {
+		c.consumerCtx.Drain()
+	}
+}
@@ -0,0 +1,93 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent struct {
+	ID int64 `json:"id"`
+}
+
+func TestConsumer_Start(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		err       error
+		wantCalls int
+	}{
+		{
+			name:      "acks a handled message",
+			data:      `{"id": 1}`,
+			wantCalls: 1,
+		},
+		{
+			name:      "redelivers a failed message until max deliver",
+			data:      `{"id": 1}`,
+			err:       errors.New("failed"),
+			wantCalls: 3,
+		},
+		{
+			name:      "terminates a malformed message",
+			data:      `not json`,
+			wantCalls: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			js := jetstreamtest.New()
+
+			stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+			assert.NoError(t, err)
+
+			var requestIDs []string
+
+			consumer, err := NewConsumer(ctx, stream, "gateway_user_created", "user.created", 3, 0,
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					assert.Equal(t, &testEvent{ID: 1}, request)
+
+					reqContext, _ := dto.RequestFromContext(ctx)
+					requestIDs = append(requestIDs, reqContext.RequestID)
+
+					return nil, tt.err
+				}, NewDecoder[testEvent]())
+			assert.NoError(t, err)
+			assert.NoError(t, consumer.Start(ctx))
+
+			msg := nats.NewMsg("user.created")
+			msg.Data = []byte(tt.data)
+			msg.Header.Set(dto.RequestIDHeader, "req-1")
+
+			_, err = js.PublishMsg(ctx, msg)
+			assert.NoError(t, err)
+
+			js.Flush()
+			assert.Len(t, requestIDs, tt.wantCalls)
+
+			for _, id := range requestIDs {
+				assert.Equal(t, "req-1", id)
+			}
+
+			cons, err := stream.Consumer(ctx, "gateway_user_created")
+			assert.NoError(t, err)
+
+			info, err := cons.Info(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, info.NumAckPending)
+			assert.Equal(t, uint64(0), info.NumPending)
+
+			consumer.Stop()
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/tracing"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+type Publisher struct {
+	js  jetstream.JetStream
+	enc Encoder
+}
+
+func NewPublisher(js jetstream.JetStream, enc Encoder) *Publisher {
+	return &Publisher{
+		js:  js,
+		enc: enc,
+	}
+}
+
+// Publish encodes and sends a message to JetStream, the request ID and the
+// trace of ctx are copied into the message headers so consumers can keep the
+// correlation.
+func (p *Publisher) Publish(ctx context.Context, subject string, request interface{}) (*jetstream.PubAck, error) {
+	ctx, span := tracing.Start(ctx, subject+" publish", tracing.SpanKindProducer)
+	defer span.End()
+
+	span.SetAttribute("messaging.system", "nats")
+	span.SetAttribute("messaging.destination", subject)
+
+	data, err := p.enc(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+
+	if reqContext, ok := dto.RequestFromContext(ctx); ok && reqContext.RequestID != "" {
+		msg.Header.Set(dto.RequestIDHeader, reqContext.RequestID)
+	}
+
+	tracing.Inject(ctx, msg.Header)
+
+	ack, err := p.js.PublishMsg(ctx, msg)
+	span.RecordError(err)
+
+	return ack, err //nolint:wrapcheck
+}
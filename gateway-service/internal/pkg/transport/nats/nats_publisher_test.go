@@ -0,0 +1,59 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+	assert.NoError(t, err)
+
+	publisher := NewPublisher(js, JSONEncoder)
+
+	ack, err := publisher.Publish(dto.ContextWithRequestID(ctx, "req-1"), "user.created", map[string]int{"id": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &jetstream.PubAck{Stream: "events", Sequence: 1}, ack)
+
+	ack, err = publisher.Publish(ctx, "user.created", map[string]int{"id": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), ack.Sequence)
+
+	msgs := js.Messages("events")
+	assert.Len(t, msgs, 2)
+	assert.JSONEq(t, `{"id": 1}`, string(msgs[0].Data))
+	assert.Equal(t, "req-1", msgs[0].Header.Get(dto.RequestIDHeader))
+	assert.Empty(t, msgs[1].Header.Get(dto.RequestIDHeader))
+}
+
+func TestPublisher_PublishErrors(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+	publisher := NewPublisher(js, JSONEncoder)
+
+	_, err := publisher.Publish(ctx, "user.created", struct{}{})
+	assert.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+	assert.NoError(t, err)
+
+	js.FailPublish(errors.New("nats: connection closed"))
+
+	_, err = publisher.Publish(ctx, "user.created", struct{}{})
+	assert.EqualError(t, err, "nats: connection closed")
+
+	_, err = publisher.Publish(ctx, "user.created", make(chan int))
+	assert.Error(t, err)
+	assert.Empty(t, js.Messages("events"))
+}
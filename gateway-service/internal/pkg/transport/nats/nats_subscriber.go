@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/timing"
+	"github.com/nats-io/nats.go"
+)
+
+// Subscriber is a core NATS subscription, every gateway instance receives
+// every message, which is what broadcast style events like cache
+// invalidation need. Use JetStream consumers for at-least-once processing.
+type Subscriber[T any] struct {
+	conn          *nats.Conn
+	subject       string
+	slowThreshold time.Duration
+	ep            endpoint.Endpoint
+	dec           Decoder[T]
+	sub           *nats.Subscription
+}
+
+func NewSubscriber[T any](
+	conn *nats.Conn,
+	subject string,
+	slowThreshold time.Duration,
+	ep endpoint.Endpoint,
+	dec Decoder[T],
+) *Subscriber[T] {
+	return &Subscriber[T]{
+		conn:          conn,
+		subject:       subject,
+		slowThreshold: slowThreshold,
+		ep:            ep,
+		dec:           dec,
+	}
+}
+
+func (s *Subscriber[T]) Start(ctx context.Context) error {
+	slog.Info("starting nats subscriber", "subject", s.subject)
+
+	sub, err := s.conn.Subscribe(s.subject, func(msg *nats.Msg) {
+		msgCtx := dto.ContextWithRequestID(ctx, requestID(msg))
+
+		msgCtx, span := startHandlerSpan(msgCtx, msg.Subject, msg.Header)
+		defer span.End()
+
+		msgCtx, breakdown := timing.ContextWithBreakdown(msgCtx)
+		start := time.Now()
+
+		defer func() {
+			observeHandler(msgCtx, s.subject, start, s.slowThreshold, breakdown)
+		}()
+
+		request, err := s.dec(msgCtx, msg)
+		if err != nil {
+			slog.ErrorContext(msgCtx, "failed to decode message", "subject", s.subject, "error", err)
+			span.RecordError(err)
+			messagesFailed.WithLabelValues(s.subject).Inc()
+
+			return
+		}
+
+		if _, err = s.ep(msgCtx, request); err != nil {
+			slog.ErrorContext(msgCtx, "failed to execute endpoint", "subject", s.subject, "error", err)
+			span.RecordError(err)
+			messagesFailed.WithLabelValues(s.subject).Inc()
+
+			return
+		}
+
+		messagesProcessed.WithLabelValues(s.subject).Inc()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	s.sub = sub
+
+	return nil
+}
+
+func (s *Subscriber[T]) Stop() {
+	slog.Info("stopping nats subscriber", "subject", s.subject)
+
+	if s.sub != nil {
+		s.sub.Drain() //nolint:errcheck
+	}
+}
+
+// requestID returns the correlation ID the publisher put in the message
+// headers, or a new one for publishers that do not propagate it or send an
+// invalid one.
+func requestID(msg *nats.Msg) string {
+	if id := msg.Header.Get(dto.RequestIDHeader); dto.ValidRequestID(id) {
+		return id
+	}
+
+	return dto.NewRequestID()
+}
@@ -0,0 +1,26 @@
+package nats
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/logger"
+	"github.com/ijalalfrz/event-driven-nats/gateway-service/internal/pkg/tracing"
+	"github.com/nats-io/nats.go"
+)
+
+// startHandlerSpan continues the trace of the publisher for the handling of a
+// message.
+func startHandlerSpan(ctx context.Context, subject string, header nats.Header) (context.Context, *tracing.Span) {
+	ctx = tracing.Extract(ctx, header)
+
+	ctx, span := tracing.Start(ctx, subject+" process", tracing.SpanKindConsumer)
+	span.SetAttribute("messaging.system", "nats")
+	span.SetAttribute("messaging.destination", subject)
+
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = logger.ContextWithAttrs(ctx, slog.String("trace_id", sc.TraceID().String()))
+	}
+
+	return ctx, span
+}
@@ -19,11 +19,14 @@ class App(tornado.web.Application):
         self.db.row_factory = sqlite3.Row
         self.init_db()
         self.nats_conn = None
+        self.nats_js = None
         self.nats_event = "listing.created"
 
     async def init_nats(self, connection_url):
         try:
             self.nats_conn = await nats.connect(connection_url)
+            # Publishing through JetStream so the ack carries the stream sequence
+            self.nats_js = self.nats_conn.jetstream()
             logging.info(f"Connected to NATS at {connection_url}")
         except Exception as e:
             logging.error(f"Failed to connect to NATS: {e}")
@@ -149,17 +152,26 @@ class ListingsHandler(BaseHandler):
         )
         self.application.db.commit()
 
-        if self.application.nats_conn:
+        consistency_token = None
+        if self.application.nats_js:
+            # Forwarding the correlation id so consumers can keep tracing the request
+            headers = None
+            request_id = self.request.headers.get("X-Transaction-Id")
+            if request_id:
+                headers = {"X-Transaction-Id": request_id}
+
             try:
-                yield self.application.nats_conn.publish(self.application.nats_event, json.dumps({
+                ack = yield self.application.nats_js.publish(self.application.nats_event, json.dumps({
                     "id": cursor.lastrowid,
                     "user_id": user_id_val,
                     "listing_type": listing_type_val,
                     "price": price_val,
                     "created_at": time_now,
                     "updated_at": time_now
-                }).encode())
-                logging.info("Published listing to NATS")
+                }).encode(), headers=headers)
+                # Readers send the token back to wait for the projection to catch up
+                consistency_token = "{}:{}".format(self.application.nats_event, ack.seq)
+                logging.info("Published listing to NATS. request_id: {}, seq: {}".format(request_id, ack.seq))
             except Exception as e:
                 logging.error(f"Failed to publish to NATS: {e}")
         
@@ -177,6 +189,9 @@ class ListingsHandler(BaseHandler):
             updated_at=time_now
         )
 
+        if consistency_token:
+            self.set_header("X-Consistency-Token", consistency_token)
+
         self.write_json({"result": True, "listing": listing})
 
     def _validate_user_id(self, user_id, errors):
@@ -0,0 +1,248 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	natstransport "github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/transport/nats"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/spf13/cobra"
+)
+
+const eventFetchTimeout = 5 * time.Second
+
+// eventDecoders decode the events of the stream like its consumers do.
+var eventDecoders = map[string]natstransport.EventDecoder{
+	userCreatedSubject:    natstransport.AnyDecoder(natstransport.NewDecoder[dto.UserCreated]()),
+	listingCreatedSubject: natstransport.AnyDecoder(natstransport.NewDecoder[dto.ListingCreated]()),
+}
+
+var (
+	eventSubject string
+	eventFrom    string
+	eventFilters []string
+	eventSeq     uint64
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Inspect the events of the listing view stream",
+}
+
+var eventsTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Print the events as they are published",
+	Example: "  app events tail --subject user.created --from 2026-10-01\n" +
+		"  app events tail --where user_id=42",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		filters, err := parseEventFilters(eventFilters)
+		if err != nil {
+			return err
+		}
+
+		consumerCfg := jetstream.OrderedConsumerConfig{DeliverPolicy: jetstream.DeliverNewPolicy}
+
+		if eventSubject != "" {
+			consumerCfg.FilterSubjects = []string{eventSubject}
+		}
+
+		if eventFrom != "" {
+			from, err := parseEventTime(eventFrom)
+			if err != nil {
+				return err
+			}
+
+			consumerCfg.DeliverPolicy = jetstream.DeliverByStartTimePolicy
+			consumerCfg.OptStartTime = &from
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return withEventStream(ctx, func(stream jetstream.Stream) error {
+			return tailEvents(ctx, stream, consumerCfg,
+				natstransport.NewInspector(cmd.OutOrStdout(), eventDecoders, filters...))
+		})
+	},
+}
+
+var eventsGetCmd = &cobra.Command{
+	Use:     "get",
+	Short:   "Print the event at a stream sequence",
+	Example: "  app events get --seq 42",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if eventSeq == 0 {
+			return errors.New("--seq is required")
+		}
+
+		return withEventStream(cmd.Context(), func(stream jetstream.Stream) error {
+			cons, err := stream.OrderedConsumer(cmd.Context(), jetstream.OrderedConsumerConfig{
+				DeliverPolicy: jetstream.DeliverByStartSequencePolicy,
+				OptStartSeq:   eventSeq,
+			})
+			if err != nil {
+				return fmt.Errorf("create ordered consumer: %w", err)
+			}
+
+			msg, err := cons.Next(jetstream.FetchMaxWait(eventFetchTimeout))
+			if err != nil {
+				return fmt.Errorf("no event at sequence %d: %w", eventSeq, err)
+			}
+
+			// a deleted sequence delivers the next event instead
+			if meta, err := msg.Metadata(); err != nil || meta.Sequence.Stream != eventSeq {
+				return fmt.Errorf("no event at sequence %d", eventSeq)
+			}
+
+			_, err = natstransport.NewInspector(cmd.OutOrStdout(), eventDecoders).Print(cmd.Context(), msg)
+
+			return err //nolint:wrapcheck
+		})
+	},
+}
+
+var eventsStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print the number of events by subject and the sequence range of the stream",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return withEventStream(cmd.Context(), func(stream jetstream.Stream) error {
+			info, err := stream.Info(cmd.Context(), jetstream.WithSubjectFilter(">"))
+			if err != nil {
+				return fmt.Errorf("get stream info: %w", err)
+			}
+
+			printEventStats(cmd, info)
+
+			return nil
+		})
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	eventsTailCmd.Flags().StringVar(&eventSubject, "subject", "", "only print the events of this subject")
+	eventsTailCmd.Flags().StringVar(&eventFrom, "from", "",
+		"print the events published since this date or RFC 3339 time, only new events when empty")
+	eventsTailCmd.Flags().StringArrayVar(&eventFilters, "where", nil,
+		"only print the events whose JSON field equals a value, like user_id=42")
+	eventsGetCmd.Flags().Uint64Var(&eventSeq, "seq", 0, "stream sequence of the event")
+
+	eventsCmd.AddCommand(eventsTailCmd, eventsGetCmd, eventsStatsCmd)
+}
+
+// withEventStream looks up the stream without creating it, the inspection
+// must not change the server.
+func withEventStream(ctx context.Context, fn func(jetstream.Stream) error) error {
+	cfg := config.MustInitConfig(cfgFilePath)
+
+	nc, err := connectNATS(cfg)
+	if err != nil {
+		return fmt.Errorf("connect to NATS: %w", err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return fmt.Errorf("create NATS JetStream: %w", err)
+	}
+
+	stream, err := js.Stream(ctx, streamName)
+	if err != nil {
+		return fmt.Errorf("get stream %s: %w", streamName, err)
+	}
+
+	return fn(stream)
+}
+
+func tailEvents(ctx context.Context, stream jetstream.Stream,
+	consumerCfg jetstream.OrderedConsumerConfig, inspector *natstransport.Inspector,
+) error {
+	cons, err := stream.OrderedConsumer(ctx, consumerCfg)
+	if err != nil {
+		return fmt.Errorf("create ordered consumer: %w", err)
+	}
+
+	msgs, err := cons.Messages()
+	if err != nil {
+		return fmt.Errorf("consume events: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		msgs.Stop()
+	}()
+
+	for {
+		msg, err := msgs.Next()
+		if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("next event: %w", err)
+		}
+
+		// an event the consumers can't decode is worth seeing too
+		if _, err := inspector.Print(ctx, msg); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n\n", err)
+		}
+	}
+}
+
+func printEventStats(cmd *cobra.Command, info *jetstream.StreamInfo) {
+	state := info.State
+
+	fmt.Fprintf(cmd.OutOrStdout(), "stream %s: %d events, first #%d %s, last #%d %s\n\n",
+		info.Config.Name, state.Msgs,
+		state.FirstSeq, state.FirstTime.Format(time.RFC3339),
+		state.LastSeq, state.LastTime.Format(time.RFC3339))
+
+	subjects := make([]string, 0, len(state.Subjects))
+	for subject := range state.Subjects {
+		subjects = append(subjects, subject)
+	}
+
+	sort.Strings(subjects)
+
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(writer, "SUBJECT\tEVENTS")
+
+	for _, subject := range subjects {
+		fmt.Fprintf(writer, "%s\t%d\n", subject, state.Subjects[subject])
+	}
+
+	writer.Flush()
+}
+
+func parseEventFilters(exprs []string) ([]natstransport.FieldFilter, error) {
+	filters := make([]natstransport.FieldFilter, 0, len(exprs))
+
+	for _, expr := range exprs {
+		filter, err := natstransport.ParseFieldFilter(expr)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+func parseEventTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, want a date like 2026-10-01 or an RFC 3339 time", value)
+}
@@ -14,12 +14,12 @@ import (
 
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/endpoint"
-	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/repository"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/router"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/service"
-	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/db"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/health"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/lang"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/logger"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/metrics"
 	"github.com/spf13/cobra"
 )
 
@@ -28,18 +28,24 @@ var timeout = 30 * time.Second
 var httpServerCmd = &cobra.Command{
 	Use:   "http",
 	Short: "Serve incoming requests from REST HTTP/JSON API",
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
 		slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
 		cfg := config.MustInitConfig(cfgFilePath)
+		applyStorageFlag(&cfg)
 
 		logger.InitStructuredLogger(cfg.LogLevel)
+		reloadLogLevel()
 
-		runHTTPServer(cfg)
+		shutdownTracing := initTracing(cfg)
+		defer shutdownTracing()
+
+		runHTTPServer(cmd.Context(), cfg)
 	},
 }
 
-func runHTTPServer(cfg config.Config) {
-	ctx, cancel := context.WithCancel(context.Background())
+// runHTTPServer serves until a signal is received or parent is done.
+func runHTTPServer(parent context.Context, cfg config.Config) {
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 
 	var waitGroup sync.WaitGroup
@@ -51,18 +57,21 @@ func runHTTPServer(cfg config.Config) {
 
 	go func() {
 		defer waitGroup.Done()
-		startHTTPServer(ctx, cfg)
+		startHTTPServer(ctx, cfg, cancel)
 	}()
 
 	sigChannel := make(chan os.Signal, 1)
 	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigChannel)
 
 	select {
 	case sig := <-sigChannel:
 		cancel()
 		slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
 	case <-ctx.Done():
-		slog.ErrorContext(ctx, "failed to start HTTP server")
+		if parent.Err() == nil {
+			slog.ErrorContext(ctx, "failed to start HTTP server")
+		}
 	}
 
 	waitGroup.Wait()
@@ -70,37 +79,63 @@ func runHTTPServer(cfg config.Config) {
 }
 
 // startHTTPServer loads config and starts HTTP server.
-func startHTTPServer(ctx context.Context, cfg config.Config) {
+func startHTTPServer(ctx context.Context, cfg config.Config,
+	cancel context.CancelFunc) {
 	var waitGroup sync.WaitGroup
 
 	waitGroup.Add(1)
 
 	go func() {
 		defer waitGroup.Done()
-		startMainApp(ctx, cfg)
+		if err := startMainApp(ctx, cfg); err != nil {
+			slog.Error("failed to start main app", slog.String("error", err.Error()))
+			cancel()
+		}
 	}()
 
-	if cfg.HTTP.PprofEnabled {
+	if cfg.HTTP.PprofEnabled || cfg.Metrics.Enabled {
 		waitGroup.Add(1)
 
 		go func() {
 			defer waitGroup.Done()
-			startPprof(ctx, cfg)
+			startInternalServer(ctx, cfg, nil)
 		}()
 	}
 
 	waitGroup.Wait()
 }
 
-func startMainApp(ctx context.Context, cfg config.Config) {
+func startMainApp(ctx context.Context, cfg config.Config) error {
 	lang.SetSupportedLanguages(cfg.Locales.SupportedLanguages)
 	lang.SetBasePath(cfg.Locales.BasePath)
 
-	endpts := makeEndpoints(cfg)
+	checks := health.New(cfg.Health.CheckTimeout)
+
+	repos, err := makeRepositories(ctx, cfg, checks)
+	if err != nil {
+		return err
+	}
+
+	if cfg.DB.AutoMigrate && repos.db != nil {
+		if err := autoMigrate(ctx, repos.db); err != nil {
+			return err
+		}
+	}
+
+	if cfg.DB.Storage == config.StorageMemory {
+		stopProjection, err := startMemoryProjection(ctx, cfg, repos, checks)
+		if err != nil {
+			return err
+		}
+		defer stopProjection()
+	}
+
+	endpts := makeEndpoints(repos)
 
 	router := router.MakeHTTPRouter(
 		endpts,
 		cfg,
+		checks,
 	)
 
 	server := &http.Server{
@@ -120,6 +155,8 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 
 	<-ctx.Done()
 
+	drainReadiness(checks, cfg.Health.ShutdownDelay)
+
 	// shutdown ctx
 	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -129,33 +166,56 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 	}
 
 	slog.Info("HTTP server gracefully stopped")
-}
 
-func makeEndpoints(cfg config.Config) endpoint.Endpoint {
-	dbConn := db.InitDB(cfg)
-
-	// init all repo
-	listingRepository := repository.NewListingRepository(dbConn)
+	return nil
+}
 
+func makeEndpoints(repos repositories) endpoint.Endpoint {
 	return endpoint.Endpoint{
-		Listing: makeListingEndpoints(listingRepository),
+		Listing:    makeListingEndpoints(repos.listing),
+		Projection: makeProjectionEndpoints(repos.offset),
+		LogLevel:   endpoint.NewLogLevelEndpoint(service.NewLogLevelService(logger.DefaultLevel)),
 	}
 }
 
-func makeListingEndpoints(listingRepository *repository.ListingRepository) endpoint.Listing {
+func makeListingEndpoints(listingRepository service.ListingViewRepository) endpoint.Listing {
 	listingViewSvc := service.NewListingViewService(listingRepository)
 
 	return endpoint.NewListingEndpoint(listingViewSvc, nil)
 }
 
-func startPprof(ctx context.Context, cfg config.Config) {
-	// manually register pprof handlers with custom path.
-	http.HandleFunc("/internal/pprof/", pprof.Index)
-	http.HandleFunc("/internal/pprof/cmdline", pprof.Cmdline)
-	http.HandleFunc("/internal/pprof/profile", pprof.Profile)
-	http.HandleFunc("/internal/pprof/symbol", pprof.Symbol)
-	http.HandleFunc("/internal/pprof/trace", pprof.Trace)
-	slog.Info("running pprof...", slog.Int("port", cfg.HTTP.PprofPort))
+func makeProjectionEndpoints(offsetRepository service.ProjectionOffsetRepository) endpoint.Projection {
+	offsetSvc := service.NewProjectionOffsetService(offsetRepository)
+
+	return endpoint.NewProjectionEndpoint(offsetSvc)
+}
+
+// startInternalServer serves pprof, the Prometheus metrics and, when routes is
+// not nil, the routes of a process without public HTTP server on the internal
+// port.
+func startInternalServer(ctx context.Context, cfg config.Config, routes http.Handler) {
+	if cfg.HTTP.PprofEnabled {
+		// manually register pprof handlers with custom path.
+		http.HandleFunc("/internal/pprof/", pprof.Index)
+		http.HandleFunc("/internal/pprof/cmdline", pprof.Cmdline)
+		http.HandleFunc("/internal/pprof/profile", pprof.Profile)
+		http.HandleFunc("/internal/pprof/symbol", pprof.Symbol)
+		http.HandleFunc("/internal/pprof/trace", pprof.Trace)
+	}
+
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Addr != "" {
+			go startMetricsServer(ctx, cfg)
+		} else {
+			http.Handle("/metrics", metrics.Default.Handler())
+		}
+	}
+
+	if routes != nil {
+		http.Handle("/", routes)
+	}
+
+	slog.Info("running internal server...", slog.Int("port", cfg.HTTP.PprofPort))
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf("localhost:%d", cfg.HTTP.PprofPort),
@@ -165,7 +225,7 @@ func startPprof(ctx context.Context, cfg config.Config) {
 	// limit access by binding port to localhost only.
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("pprof server error", slog.String("error", err.Error()))
+			slog.Error("internal server error", slog.String("error", err.Error()))
 		}
 	}()
 
@@ -175,8 +235,41 @@ func startPprof(ctx context.Context, cfg config.Config) {
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		slog.Error("failed to shutdown pprof server", slog.String("error", err.Error()))
+		slog.Error("failed to shutdown internal server", slog.String("error", err.Error()))
+	}
+
+	slog.Info("internal server stopped")
+}
+
+// startMetricsServer serves the Prometheus metrics on METRICS_ADDR, unlike
+// the internal port it is reachable from other hosts, pprof isn't served.
+func startMetricsServer(ctx context.Context, cfg config.Config) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+
+	slog.Info("running metrics server...", slog.String("addr", cfg.Metrics.Addr))
+
+	server := &http.Server{
+		Addr:              cfg.Metrics.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.HTTP.Timeout,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server error", slog.String("error", err.Error()))
+		}
+	}()
+
+	<-ctx.Done()
+
+	// ctx is done, the shutdown gets its own deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shutdown metrics server", slog.String("error", err.Error()))
 	}
 
-	slog.Info("pprof server stopped")
+	slog.Info("metrics server stopped")
 }
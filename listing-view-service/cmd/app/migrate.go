@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/db/migrations"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/db"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/logger"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/migrate"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the database schema with the migrations embedded in the binary",
+	Long: "Migrate the database schema with the migrations embedded in the binary.\n" +
+		"The version is tracked in schema_migrations, like the migrate CLI does, and an advisory lock\n" +
+		"makes the replicas migrating at the same time wait for each other.",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:          "up",
+	Short:        "Apply the pending migrations",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return withMigrator(cmd, func(ctx context.Context, migrator *migrate.Migrator) error {
+			return migrator.Up(ctx)
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:          "down [N]",
+	Short:        "Revert the last N applied migrations, 1 by default",
+	Example:      "  app migrate down\n  app migrate goto 0",
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 1
+
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[0])
+			}
+
+			steps = n
+		}
+
+		return withMigrator(cmd, func(ctx context.Context, migrator *migrate.Migrator) error {
+			return migrator.Down(ctx, steps)
+		})
+	},
+}
+
+var migrateGotoCmd = &cobra.Command{
+	Use:          "goto N",
+	Short:        "Migrate up or down to version N, 0 reverts every migration",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+
+		return withMigrator(cmd, func(ctx context.Context, migrator *migrate.Migrator) error {
+			return migrator.Goto(ctx, version)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "Print the version of the database and the pending migrations",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return withMigrator(cmd, func(ctx context.Context, migrator *migrate.Migrator) error {
+			status, err := migrator.Status(ctx)
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			printMigrateStatus(cmd, status)
+
+			return nil
+		})
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateGotoCmd, migrateStatusCmd)
+}
+
+// withMigrator opens the database of the config file and stops migrating on
+// SIGINT or SIGTERM, the running migration is rolled back.
+func withMigrator(cmd *cobra.Command, fn func(context.Context, *migrate.Migrator) error) error {
+	cfg := config.MustInitConfig(cfgFilePath)
+	applyStorageFlag(&cfg)
+
+	logger.InitStructuredLogger(cfg.LogLevel)
+
+	if cfg.DB.Storage != config.StoragePostgres {
+		return fmt.Errorf("%s storage has no schema to migrate", cfg.DB.Storage)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbConn, err := db.Connect(ctx, cfg)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer dbConn.Close()
+
+	migrator, err := newMigrator(dbConn)
+	if err != nil {
+		return err
+	}
+
+	return fn(ctx, migrator)
+}
+
+func newMigrator(dbConn *sql.DB) (*migrate.Migrator, error) {
+	migrator, err := migrate.New(dbConn, migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+
+	return migrator, nil
+}
+
+// autoMigrate applies the pending migrations before the server starts, when
+// DB_AUTO_MIGRATE is set.
+func autoMigrate(ctx context.Context, dbConn *sql.DB) error {
+	migrator, err := newMigrator(dbConn)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		return fmt.Errorf("migrate database: %w", err)
+	}
+
+	return nil
+}
+
+func printMigrateStatus(cmd *cobra.Command, status migrate.Status) {
+	out := cmd.OutOrStdout()
+
+	switch {
+	case status.Dirty:
+		fmt.Fprintf(out, "version %d, dirty\n", status.Version)
+	case status.Version == 0:
+		fmt.Fprintln(out, "no migration applied")
+	default:
+		fmt.Fprintf(out, "version %d\n", status.Version)
+	}
+
+	fmt.Fprintf(out, "%d pending\n\n", status.Pending())
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED")
+
+	for _, migration := range status.Migrations {
+		applied := "no"
+		if migration.Applied {
+			applied = "yes"
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", migration.Version, migration.Name, applied)
+	}
+
+	writer.Flush()
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -11,9 +12,9 @@ import (
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/endpoint"
-	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/repository"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/router"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/service"
-	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/db"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/health"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/logger"
 	natstransport "github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/transport/nats"
 	"github.com/nats-io/nats.go"
@@ -22,9 +23,11 @@ import (
 )
 
 var (
-	userCreatedSubject    = "user.created"
-	listingCreatedSubject = "listing.created"
-	streamName            = "listing_view_event"
+	userCreatedSubject     = dto.UserCreatedSubject
+	listingCreatedSubject  = dto.ListingCreatedSubject
+	streamName             = dto.EventStreamName
+	userCreatedConsumer    = "listing_view_user_created"
+	listingCreatedConsumer = "listing_view_listing_created"
 )
 
 var natsConsumerCmd = &cobra.Command{
@@ -33,20 +36,38 @@ var natsConsumerCmd = &cobra.Command{
 	Run: func(_ *cobra.Command, _ []string) {
 		slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
 		cfg := config.MustInitConfig(cfgFilePath)
+		applyStorageFlag(&cfg)
 
 		logger.InitStructuredLogger(cfg.LogLevel)
+		reloadLogLevel()
+
+		shutdownTracing := initTracing(cfg)
+		defer shutdownTracing()
 
 		runNATS(cfg)
 	},
 }
 
+func connectNATS(cfg config.Config) (*nats.Conn, error) {
+	return natstransport.Connect(natstransport.ConnOptions{ //nolint:wrapcheck
+		URL:           cfg.NATS.URL,
+		Name:          "listing-view-service",
+		MaxReconnects: cfg.NATS.MaxReconnects,
+		ReconnectWait: cfg.NATS.ReconnectWait,
+	})
+}
+
 func runNATS(cfg config.Config) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	nc, err := nats.Connect(cfg.NATS.URL,
-		nats.MaxReconnects(cfg.NATS.MaxReconnects),
-		nats.ReconnectWait(cfg.NATS.ReconnectWait))
+	if cfg.DB.Storage == config.StorageMemory {
+		slog.Error("the consumer can't share a memory storage with the HTTP server, " +
+			"the http command consumes the events itself with it")
+		return
+	}
+
+	nc, err := connectNATS(cfg)
 	if err != nil {
 		slog.Error("failed to connect to NATS", "error", err)
 		return
@@ -58,91 +79,152 @@ func runNATS(cfg config.Config) {
 		return
 	}
 
-	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{
+	stream, err := createStream(ctx, js)
+	if err != nil {
+		slog.Error("failed to create stream", "error", err)
+		return
+	}
+
+	checks := health.New(cfg.Health.CheckTimeout)
+
+	repos, err := makeRepositories(ctx, cfg, checks)
+	if err != nil {
+		slog.Error("failed to open storage", "error", err)
+		return
+	}
+
+	subs, err := startSubscribers(ctx, cfg, stream, makeNatsEndpoints(repos),
+		userCreatedConsumer, listingCreatedConsumer)
+	if err != nil {
+		slog.Error("failed to create consumers", "error", err)
+		return
+	}
+
+	checks.Register("nats", health.NATSChecker(nc))
+	checks.Register("jetstream", health.JetStreamChecker(js))
+	subs.register(checks)
+
+	// consumers have no HTTP server, the internal port serves their probes,
+	// metrics and the admin API
+	adminSvc := service.NewConsumerAdminService(cfg.NATS.ErrorWindow,
+		subs.userCreated, subs.listingCreated)
+	adminRouter := router.MakeAdminRouter(endpoint.Endpoint{
+		ConsumerAdmin: endpoint.NewConsumerAdminEndpoint(adminSvc),
+	}, cfg, checks)
+
+	go startInternalServer(ctx, cfg, adminRouter)
+
+	sigChannel := make(chan os.Signal, 1)
+	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+
+	select {
+	case sig := <-sigChannel:
+		cancel()
+		slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
+	case <-ctx.Done():
+		slog.Info("context done. Exiting...", "error", ctx.Err())
+	}
+
+	checks.Shutdown()
+
+	subs.stop()
+	nc.Close()
+
+	slog.Info("nats consumer stopped")
+}
+
+func createStream(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
+	return js.CreateStream(ctx, jetstream.StreamConfig{ //nolint:wrapcheck
 		Name: streamName,
 		Subjects: []string{
 			userCreatedSubject,
 			listingCreatedSubject,
 		},
 	})
-	if err != nil {
-		slog.Error("failed to create stream", "error", err)
-		return
-	}
+}
 
+// subscribers are the consumers of the projection.
+type subscribers struct {
+	userCreated    *natstransport.Consumer[dto.UserCreated]
+	listingCreated *natstransport.Consumer[dto.ListingCreated]
+}
+
+func startSubscribers(ctx context.Context, cfg config.Config, stream jetstream.Stream,
+	endpoints endpoint.Endpoint, userConsumer, listingConsumer string,
+) (subscribers, error) {
 	middlewares := []gokitendpoint.Middleware{
 		natstransport.AutoAckMiddleware(),
 	}
-	endpoints := makeNatsEndpoints(cfg)
 
-	userCreatedConsumer, err := natstransport.NewSubscriber(
+	userCreatedSubscriber, err := natstransport.NewSubscriber(
 		ctx,
 		stream,
+		userConsumer,
 		userCreatedSubject,
+		cfg.NATS.HandlerTimeThreshold,
 		endpoints.User.OnCreated,
 		natstransport.NewDecoder[dto.UserCreated](),
 		middlewares,
+		natstransport.WithFailedEndpoint(endpoints.Projection.OnFailed),
 	)
 	if err != nil {
-		slog.Error("failed to create user created consumer", "error", err)
-		return
+		return subscribers{}, fmt.Errorf("create user created consumer: %w", err)
 	}
 
-	listingCreatedConsumer, err := natstransport.NewSubscriber(
+	listingCreatedSubscriber, err := natstransport.NewSubscriber(
 		ctx,
 		stream,
+		listingConsumer,
 		listingCreatedSubject,
+		cfg.NATS.HandlerTimeThreshold,
 		endpoints.Listing.OnCreated,
 		natstransport.NewDecoder[dto.ListingCreated](),
 		middlewares,
+		natstransport.WithFailedEndpoint(endpoints.Projection.OnFailed),
 	)
 	if err != nil {
-		slog.Error("failed to create listing created consumer", "error", err)
-		return
+		return subscribers{}, fmt.Errorf("create listing created consumer: %w", err)
 	}
 
-	userCreatedConsumer.Start(ctx)
-	listingCreatedConsumer.Start(ctx)
+	userCreatedSubscriber.Start(ctx)
+	listingCreatedSubscriber.Start(ctx)
 
-	sigChannel := make(chan os.Signal, 1)
-	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
-
-	select {
-	case sig := <-sigChannel:
-		cancel()
-		slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
-	case <-ctx.Done():
-		slog.Info("context done. Exiting...", "error", ctx.Err())
-	}
-
-	userCreatedConsumer.Stop()
-	listingCreatedConsumer.Stop()
-	nc.Close()
-
-	slog.Info("nats consumer stopped")
+	return subscribers{
+		userCreated:    userCreatedSubscriber,
+		listingCreated: listingCreatedSubscriber,
+	}, nil
 }
 
-func makeNatsEndpoints(cfg config.Config) endpoint.Endpoint {
-	dbConn := db.InitDB(cfg)
+func (s subscribers) register(checks *health.Health) {
+	checks.Register(s.userCreated.Name(), s.userCreated)
+	checks.Register(s.listingCreated.Name(), s.listingCreated)
+}
 
-	// init all repo
-	userRepo := repository.NewUserRepository(dbConn)
-	listingRepo := repository.NewListingRepository(dbConn)
+func (s subscribers) stop() {
+	s.userCreated.Stop()
+	s.listingCreated.Stop()
+}
 
+func makeNatsEndpoints(repos repositories) endpoint.Endpoint {
 	return endpoint.Endpoint{
-		User:    makeUserEndpoint(userRepo),
-		Listing: makeListingEndpoint(listingRepo, userRepo),
+		User:       makeUserEndpoint(repos.user, repos.offset),
+		Listing:    makeListingEndpoint(repos.listing, repos.user, repos.offset),
+		Projection: makeProjectionEndpoints(repos.offset),
 	}
 }
 
-func makeUserEndpoint(userRepo *repository.UserRepository) endpoint.User {
-	userSvc := service.NewUserService(userRepo)
+func makeUserEndpoint(userRepo service.UserRepository,
+	offsetRepo service.ProjectionOffsetRepository,
+) endpoint.User {
+	userSvc := service.NewUserService(userRepo, offsetRepo)
 
 	return endpoint.NewUserEndpoint(userSvc)
 }
 
-func makeListingEndpoint(listingRepo *repository.ListingRepository, userRepo *repository.UserRepository) endpoint.Listing {
-	listingSvc := service.NewListingService(listingRepo, userRepo)
+func makeListingEndpoint(listingRepo listingRepository, userRepo service.UserRepository,
+	offsetRepo service.ProjectionOffsetRepository,
+) endpoint.Listing {
+	listingSvc := service.NewListingService(listingRepo, userRepo, offsetRepo)
 	listingViewSvc := service.NewListingViewService(listingRepo)
 
 	return endpoint.NewListingEndpoint(listingViewSvc, listingSvc)
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/repository"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/service"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/db"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/health"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/metrics"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// memoryConsumerSuffix names the consumers of a memory storage apart from the
+// ones of the consumer command, so they don't share the events.
+const memoryConsumerSuffix = "_memory"
+
+// storageFlag overrides the STORAGE config key when set.
+var storageFlag string
+
+// listingRepository writes and reads the listing projection.
+type listingRepository interface {
+	service.ListingRepository
+	service.ListingViewRepository
+}
+
+type repositories struct {
+	user    service.UserRepository
+	listing listingRepository
+	offset  service.ProjectionOffsetRepository
+	// db is the Postgres database, nil with the memory storage.
+	db *sql.DB
+}
+
+// applyStorageFlag gives --storage precedence over the config file.
+func applyStorageFlag(cfg *config.Config) {
+	if storageFlag != "" {
+		cfg.DB.Storage = storageFlag
+	}
+}
+
+// makeRepositories opens the storage of cfg, a Postgres storage is waited for
+// until it answers and then checked by the readiness probe.
+func makeRepositories(ctx context.Context, cfg config.Config, checks *health.Health) (repositories, error) {
+	switch cfg.DB.Storage {
+	case config.StoragePostgres:
+		dbConn, err := db.Connect(ctx, cfg)
+		if err != nil {
+			return repositories{}, err //nolint:wrapcheck
+		}
+
+		db.RegisterMetrics(metrics.Default, dbConn)
+		checks.Register("postgres", health.DBChecker(dbConn))
+
+		return repositories{
+			user:    repository.NewUserRepository(dbConn, cfg.DB.SlowQueryThreshold),
+			listing: repository.NewListingRepository(dbConn, cfg.DB.SlowQueryThreshold),
+			offset:  repository.NewProjectionOffsetRepository(dbConn, cfg.DB.SlowQueryThreshold),
+			db:      dbConn,
+		}, nil
+	case config.StorageMemory:
+		slog.Warn("data is stored in memory and lost when the process stops")
+
+		memDB := memdb.New()
+
+		return repositories{
+			user:    repository.NewMemoryUserRepository(memDB),
+			listing: repository.NewMemoryListingRepository(memDB),
+			offset:  repository.NewMemoryProjectionOffsetRepository(memDB),
+		}, nil
+	default:
+		return repositories{}, fmt.Errorf("unknown storage %q, want %s or %s",
+			cfg.DB.Storage, config.StoragePostgres, config.StorageMemory)
+	}
+}
+
+// startMemoryProjection consumes the events in the HTTP server process, the
+// only one seeing a memory storage. The projection starts empty, so the
+// consumers are recreated to replay the stream from its first event. The
+// returned function stops them.
+func startMemoryProjection(ctx context.Context, cfg config.Config, repos repositories,
+	checks *health.Health,
+) (func(), error) {
+	nc, err := connectNATS(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+
+		return nil, fmt.Errorf("create NATS JetStream: %w", err)
+	}
+
+	stream, err := createStream(ctx, js)
+	if err != nil {
+		nc.Close()
+
+		return nil, fmt.Errorf("create stream: %w", err)
+	}
+
+	userConsumer := userCreatedConsumer + memoryConsumerSuffix
+	listingConsumer := listingCreatedConsumer + memoryConsumerSuffix
+
+	for _, name := range []string{userConsumer, listingConsumer} {
+		err := stream.DeleteConsumer(ctx, name)
+		if err != nil && !errors.Is(err, jetstream.ErrConsumerNotFound) {
+			nc.Close()
+
+			return nil, fmt.Errorf("delete consumer %s: %w", name, err)
+		}
+	}
+
+	subs, err := startSubscribers(ctx, cfg, stream, makeNatsEndpoints(repos), userConsumer, listingConsumer)
+	if err != nil {
+		nc.Close()
+
+		return nil, err
+	}
+
+	checks.Register("nats", health.NATSChecker(nc))
+	checks.Register("jetstream", health.JetStreamChecker(js))
+	subs.register(checks)
+
+	return func() {
+		subs.stop()
+		nc.Close()
+	}, nil
+}
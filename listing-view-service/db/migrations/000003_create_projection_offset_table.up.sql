@@ -0,0 +1,7 @@
+-- last applied stream sequence per subject, used by readers waiting for
+-- their own writes to be projected
+CREATE TABLE IF NOT EXISTS projection_offsets (
+    subject VARCHAR PRIMARY KEY,
+    sequence BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
@@ -0,0 +1,9 @@
+-- stream sequences the projection gave up on, readers waiting for one of
+-- them are answered stale instead of caught up
+CREATE TABLE IF NOT EXISTS projection_failures (
+    subject VARCHAR NOT NULL,
+    sequence BIGINT NOT NULL,
+    error VARCHAR NOT NULL,
+    failed_at BIGINT NOT NULL,
+    PRIMARY KEY (subject, sequence)
+);
@@ -4,6 +4,8 @@ package config
 import (
 	"log/slog"
 	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/configcheck"
 )
 
 type LogLeveler string
@@ -16,48 +18,140 @@ func (l LogLeveler) Level() slog.Level {
 	return level
 }
 
-// Config holds the server configuration.
+// reloadableKeys are the lower case keys applied without restart by the
+// OnChange listeners.
+var reloadableKeys = map[string]bool{
+	"log_level":      true,
+	"allowed_origin": true,
+	"service_tokens": true,
+}
+
+// Config holds the server configuration. The validate tags are checked by
+// configcheck when it is loaded, secret:"true" masks a field when printed or
+// logged and lets it be read from the file named by its key suffixed by _FILE.
 type Config struct {
-	LogLevel             LogLeveler    `mapstructure:"LOG_LEVEL"`
-	ServiceTokens        string        `mapstructure:"SERVICE_TOKENS"`
+	LogLevel             LogLeveler    `mapstructure:"LOG_LEVEL" validate:"log_level"`
+	ServiceTokens        string        `mapstructure:"SERVICE_TOKENS" secret:"true"`
 	TracingEnabled       bool          `mapstructure:"TRACING_ENABLED"`
 	ProfilingEnabled     bool          `mapstructure:"PROFILING_ENABLED"`
-	RequestTimeThreshold time.Duration `mapstructure:"REQUEST_TIME_THRESHOLD"`
+	RequestTimeThreshold time.Duration `mapstructure:"REQUEST_TIME_THRESHOLD" validate:"gte=0"`
 	DB                   DB            `mapstructure:",squash"`
 	HTTP                 HTTP          `mapstructure:",squash"`
+	Metrics              Metrics       `mapstructure:",squash"`
+	Tracing              Tracing       `mapstructure:",squash"`
+	Health               Health        `mapstructure:",squash"`
+	Logging              Logging       `mapstructure:",squash"`
 	HTTPCaller           HTTPCaller    `mapstructure:",squash"`
 	Locales              Locales       `mapstructure:",squash"`
 	NATS                 NATS          `mapstructure:",squash"`
 }
 
+// LogValue logs the config with its secrets masked.
+func (c Config) LogValue() slog.Value {
+	return configcheck.LogValue(c)
+}
+
+// String formats the config with its secrets masked, so printing it with %v
+// doesn't leak them either.
+func (c Config) String() string {
+	return configcheck.String(c)
+}
+
+// Storages of the repositories.
+const (
+	StoragePostgres = "postgres"
+	// StorageMemory keeps the data in memory, for running the service without
+	// Postgres, it is lost when the process stops.
+	StorageMemory = "memory"
+)
+
 type DB struct {
-	DSN                   string        `mapstructure:"DB_DSN"`
-	MaxOpenConnections    int           `mapstructure:"DB_MAX_OPEN_CONNECTIONS"`
-	MaxIdleConnections    int           `mapstructure:"DB_MAX_IDLE_CONNECTIONS"`
+	Storage               string        `mapstructure:"STORAGE" validate:"oneof=postgres memory"`
+	DSN                   string        `mapstructure:"DB_DSN" validate:"required_if=Storage postgres,dsn" secret:"true"`
+	MaxOpenConnections    int           `mapstructure:"DB_MAX_OPEN_CONNECTIONS" validate:"gte=0"`
+	MaxIdleConnections    int           `mapstructure:"DB_MAX_IDLE_CONNECTIONS" validate:"gte=0"`
 	MaxConnectionLifetime time.Duration `mapstructure:"DB_MAX_CONNECTIONS_LIFETIME"`
 	MaxIdleConnectionTime time.Duration `mapstructure:"DB_MAX_IDLE_CONNECTIONS_TIME"`
+	// SlowQueryThreshold logs statements slower than it, zero disables it.
+	SlowQueryThreshold time.Duration `mapstructure:"DB_SLOW_QUERY_THRESHOLD"`
+	// AutoMigrate applies the pending migrations when the http command
+	// starts, the replicas starting together wait for each other.
+	AutoMigrate bool `mapstructure:"DB_AUTO_MIGRATE"`
+	// ConnectTimeout is how long the startup waits for the database to
+	// answer, zero tries once.
+	ConnectTimeout time.Duration `mapstructure:"DB_CONNECT_TIMEOUT" validate:"gte=0"`
+	// ConnectBackoff is the wait after the first failed connection attempt,
+	// it doubles after each of the next ones.
+	ConnectBackoff time.Duration `mapstructure:"DB_CONNECT_BACKOFF" validate:"gte=1ms"`
 }
 
 type HTTP struct {
-	Port          int           `mapstructure:"HTTP_PORT"`
-	Timeout       time.Duration `mapstructure:"HTTP_TIMEOUT"`
+	Port          int           `mapstructure:"HTTP_PORT" validate:"min=1,max=65535"`
+	Timeout       time.Duration `mapstructure:"HTTP_TIMEOUT" validate:"omitempty,gte=1ms"`
 	PprofEnabled  bool          `mapstructure:"PPROF_ENABLED"`
-	PprofPort     int           `mapstructure:"PPROF_PORT"`
+	PprofPort     int           `mapstructure:"PPROF_PORT" validate:"gte=0,max=65535"`
 	AllowedOrigin []string      `mapstructure:"ALLOWED_ORIGIN"`
 }
 
+type Metrics struct {
+	// Enabled serves /metrics, on the internal (pprof) port unless Addr is set.
+	Enabled bool `mapstructure:"METRICS_ENABLED"`
+	// Addr is the address of a listener of its own for /metrics, like :9090
+	// for Prometheus to scrape it from another host.
+	Addr string `mapstructure:"METRICS_ADDR" validate:"omitempty,hostname_port"`
+}
+
+// Health configures the readiness probe.
+type Health struct {
+	CheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT" validate:"gte=1ms"`
+	// ShutdownDelay is how long the readiness probe fails before the server
+	// stops accepting requests, so load balancers drain the traffic first.
+	ShutdownDelay time.Duration `mapstructure:"HEALTH_SHUTDOWN_DELAY" validate:"gte=0"`
+}
+
+// Logging configures the request and response bodies logged by the HTTP
+// logging middleware.
+type Logging struct {
+	// BodyLimit is the number of bytes of a body logged before truncating it.
+	BodyLimit int `mapstructure:"LOG_BODY_LIMIT" validate:"gte=0"`
+	// RedactFields are the dot separated paths of the fields masked in the
+	// logged bodies, * matches any key or array element.
+	RedactFields []string `mapstructure:"LOG_REDACT_FIELDS"`
+	// ContentTypes are the media types whose bodies are logged, empty logs
+	// all of them.
+	ContentTypes []string `mapstructure:"LOG_CONTENT_TYPES"`
+	// SuccessSampleRate is the fraction of the successful requests logged,
+	// error responses are always logged.
+	SuccessSampleRate float64 `mapstructure:"LOG_SUCCESS_SAMPLE_RATE" validate:"gte=0,lte=1"`
+}
+
+// Tracing configures where spans go once TRACING_ENABLED is set.
+type Tracing struct {
+	// Exporter is one of stdout, file or otlp.
+	Exporter string `mapstructure:"TRACING_EXPORTER" validate:"omitempty,oneof=stdout file otlp"`
+	// FilePath is the JSON lines file of the file exporter.
+	FilePath string `mapstructure:"TRACING_FILE_PATH"`
+	// OTLPEndpoint is the OTLP/HTTP traces URL of the otlp exporter.
+	OTLPEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT" validate:"omitempty,url"`
+}
+
 type HTTPCaller struct {
 	Timeout time.Duration `mapstructure:"HTTP_CALLER_TIMEOUT"`
 }
 
 type Locales struct {
-	BasePath           string `mapstructure:"LOCALES_BASE_PATH"`
-	SupportedLanguages string `mapstructure:"LOCALES_SUPPORTED_LANGUAGES"`
+	BasePath           string `mapstructure:"LOCALES_BASE_PATH" validate:"required"`
+	SupportedLanguages string `mapstructure:"LOCALES_SUPPORTED_LANGUAGES" validate:"required"`
 }
 
 type NATS struct {
-	URL           string        `mapstructure:"NATS_URL"`
+	URL           string        `mapstructure:"NATS_URL" validate:"required,urls"`
 	StreamName    string        `mapstructure:"NATS_STREAM_NAME"`
-	MaxReconnects int           `mapstructure:"NATS_MAX_RECONNECTS"`
-	ReconnectWait time.Duration `mapstructure:"NATS_RECONNECT_WAIT"`
+	MaxReconnects int           `mapstructure:"NATS_MAX_RECONNECTS" validate:"gte=-1"`
+	ReconnectWait time.Duration `mapstructure:"NATS_RECONNECT_WAIT" validate:"gte=0"`
+	// HandlerTimeThreshold logs message handlers slower than it, zero
+	// disables it.
+	HandlerTimeThreshold time.Duration `mapstructure:"NATS_HANDLER_TIME_THRESHOLD" validate:"gte=0"`
+	// ErrorWindow is how far back the consumer admin API counts errors.
+	ErrorWindow time.Duration `mapstructure:"NATS_CONSUMER_ERROR_WINDOW" validate:"omitempty,gte=1s"`
 }
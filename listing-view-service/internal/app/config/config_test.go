@@ -15,12 +15,19 @@ func TestLoadConfig(t *testing.T) {
 
 		assert.Equal(t, LogLeveler("info"), config.LogLevel)
 		assert.Equal(t, false, config.TracingEnabled)
+		assert.Equal(t, "stdout", config.Tracing.Exporter)
+		assert.Equal(t, 2*time.Second, config.Health.CheckTimeout)
+		assert.Equal(t, 65536, config.Logging.BodyLimit)
+		assert.Equal(t, []string{"listings.*.user.name"}, config.Logging.RedactFields)
+		assert.Equal(t, 1.0, config.Logging.SuccessSampleRate)
 		assert.Equal(t, 3001, config.HTTP.Port)
 		assert.Equal(t, false, config.HTTP.PprofEnabled)
 		assert.Equal(t, 3002, config.HTTP.PprofPort)
+		assert.Equal(t, StoragePostgres, config.DB.Storage)
 		assert.Equal(t, "postgres://docker@postgres-listing-service/listing_development?sslmode=disable", config.DB.DSN)
 		assert.Equal(t, 2, config.DB.MaxOpenConnections)
 		assert.Equal(t, 1, config.DB.MaxIdleConnections)
 		assert.Equal(t, 1*time.Hour, config.DB.MaxConnectionLifetime)
+		assert.Equal(t, false, config.DB.AutoMigrate)
 	})
 }
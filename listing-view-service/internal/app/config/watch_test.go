@@ -0,0 +1,105 @@
+//go:build unit
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedKeys(t *testing.T) {
+	prev := map[string]any{"log_level": "info", "http_port": "3001", "nats_url": "nats://nats:4222"}
+	next := map[string]any{"log_level": "debug", "http_port": "3001", "allowed_origin": "http://localhost"}
+
+	assert.Equal(t, []string{"allowed_origin", "log_level", "nats_url"}, changedKeys(prev, next))
+	assert.Empty(t, changedKeys(prev, prev))
+}
+
+// validEnv holds the keys a config needs to be valid, besides the ones the
+// tests change.
+const validEnv = "STORAGE=memory\nNATS_URL=nats://nats:4222\nHEALTH_CHECK_TIMEOUT=2s\n" +
+	"LOCALES_BASE_PATH=./resources/locales\nLOCALES_SUPPORTED_LANGUAGES=en\nDB_CONNECT_BACKOFF=500ms\n"
+
+func TestWatcher_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(path, []byte(validEnv+"LOG_LEVEL=info\nHTTP_PORT=3001\n"), 0o600))
+
+	vpr := viper.New()
+	vpr.SetConfigFile(path)
+	vpr.SetConfigType("env")
+	assert.NoError(t, vpr.ReadInConfig())
+
+	var got []Config
+
+	listeners = nil
+	defer func() { listeners = nil }()
+
+	OnChange(func(cfg Config) { got = append(got, cfg) })
+
+	cfg, _ := decode(vpr)
+	w := &watcher{vpr: vpr, settings: settingsOf(vpr, cfg)}
+
+	// a key that needs a restart is not applied
+	assert.NoError(t, os.WriteFile(path, []byte(validEnv+"LOG_LEVEL=info\nHTTP_PORT=4001\n"), 0o600))
+	assert.NoError(t, vpr.ReadInConfig())
+	w.reload()
+	assert.Empty(t, got)
+
+	// an invalid config is not applied
+	assert.NoError(t, os.WriteFile(path, []byte(validEnv+"LOG_LEVEL=verbose\nHTTP_PORT=4001\n"), 0o600))
+	assert.NoError(t, vpr.ReadInConfig())
+	w.reload()
+	assert.Empty(t, got)
+
+	assert.NoError(t, os.WriteFile(path, []byte(validEnv+"LOG_LEVEL=debug\nHTTP_PORT=4001\n"), 0o600))
+	assert.NoError(t, vpr.ReadInConfig())
+	w.reload()
+
+	assert.Len(t, got, 1)
+	assert.Equal(t, LogLeveler("debug"), got[0].LogLevel)
+	assert.Equal(t, 4001, got[0].HTTP.Port)
+}
+
+func TestWatcher_ReloadSecretFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	tokensPath := filepath.Join(dir, "service_tokens")
+
+	assert.NoError(t, os.WriteFile(tokensPath, []byte("s3cr3t\n"), 0o600))
+	env := validEnv + "LOG_LEVEL=info\nHTTP_PORT=3001\nSERVICE_TOKENS_FILE=" + tokensPath + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(env), 0o600))
+
+	vpr := viper.New()
+	vpr.SetConfigFile(path)
+	vpr.SetConfigType("env")
+	assert.NoError(t, vpr.ReadInConfig())
+
+	cfg, report := decode(vpr)
+	assert.Empty(t, report.Errors)
+	assert.Empty(t, report.Warnings)
+	assert.Equal(t, "s3cr3t", cfg.ServiceTokens)
+
+	var got []Config
+
+	listeners = nil
+	defer func() { listeners = nil }()
+
+	OnChange(func(cfg Config) { got = append(got, cfg) })
+
+	w := &watcher{vpr: vpr, settings: settingsOf(vpr, cfg)}
+
+	w.reload()
+	assert.Empty(t, got)
+
+	// the secret is rotated in its file, the config file doesn't change
+	assert.NoError(t, os.WriteFile(tokensPath, []byte("r0t4t3d\n"), 0o600))
+	w.reload()
+
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "r0t4t3d", got[0].ServiceTokens)
+	}
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ConsumerStatus reports how far a NATS consumer is behind its stream.
+type ConsumerStatus struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Paused  bool   `json:"paused"`
+	// PausedUntil is when the server resumes the consumer by itself.
+	PausedUntil   int64  `json:"paused_until,omitempty"`
+	NumPending    uint64 `json:"num_pending"`
+	NumAckPending int    `json:"num_ack_pending"`
+	// NumRedelivered is reported by the server, Redeliveries counts the
+	// redelivered messages this process handled.
+	NumRedelivered  int    `json:"num_redelivered"`
+	Redeliveries    uint64 `json:"redeliveries"`
+	LastSequence    uint64 `json:"last_sequence"`
+	LastProcessedAt int64  `json:"last_processed_at,omitempty"`
+	RecentErrors    int    `json:"recent_errors"`
+	ErrorWindow     string `json:"error_window"`
+	LastError       string `json:"last_error,omitempty"`
+}
+
+type GetConsumersRequest struct{}
+
+func (r *GetConsumersRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+type GetConsumersResponse struct {
+	Result    bool             `json:"result"`
+	Consumers []ConsumerStatus `json:"consumers"`
+}
+
+type ConsumerRequest struct {
+	Name string `json:"-"`
+}
+
+func (r *ConsumerRequest) Bind(req *http.Request) error {
+	r.Name = chi.URLParam(req, "name")
+
+	return nil
+}
+
+type ConsumerResponse struct {
+	Result   bool           `json:"result"`
+	Consumer ConsumerStatus `json:"consumer"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/asyncapi"
+
+const (
+	UserCreatedSubject    = "user.created"
+	ListingCreatedSubject = "listing.created"
+
+	// EventStreamName is the stream the projection consumers read from.
+	EventStreamName = "listing_view_event"
+)
+
+// EventCatalog documents the events consumed by the projection, generated as
+// AsyncAPI by the asyncapi command and served on /docs/asyncapi.json.
+var EventCatalog = asyncapi.Catalog{
+	Service:     "listing-view-service",
+	Version:     "1.0.0",
+	Description: "Builds the read-optimized listing projection from the user and listing events.",
+	Consumes: []asyncapi.Event{
+		{
+			Subject:     UserCreatedSubject,
+			Stream:      EventStreamName,
+			Description: "A user was created, its name is denormalized in the listings.",
+			Message:     "UserCreated",
+			Payload:     UserCreated{},
+		},
+		{
+			Subject:     ListingCreatedSubject,
+			Stream:      EventStreamName,
+			Description: "A listing was created.",
+			Message:     "ListingCreated",
+			Payload:     ListingCreated{},
+		},
+	},
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type GetLogLevelRequest struct{}
+
+func (r *GetLogLevelRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+type ResetLogLevelRequest struct{}
+
+func (r *ResetLogLevelRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+// SetLogLevelRequest overrides the log level until Duration elapses, the
+// service default applies when it is zero.
+type SetLogLevelRequest struct {
+	Level        string        `json:"level" validate:"required,oneof=debug info warn error"`
+	DurationText string        `json:"duration"`
+	Duration     time.Duration `json:"-"`
+}
+
+func (r *SetLogLevelRequest) Bind(_ *http.Request) error {
+	if err := validate.Struct(r); err != nil {
+		return NewInvalidRequestError(err)
+	}
+
+	if r.DurationText != "" {
+		duration, err := time.ParseDuration(r.DurationText)
+		if err != nil || duration <= 0 {
+			return NewInvalidRequestError(fmt.Errorf("invalid duration: %s", r.DurationText))
+		}
+
+		r.Duration = duration
+	}
+
+	return nil
+}
+
+type LogLevelResponse struct {
+	Result          bool   `json:"result"`
+	Level           string `json:"level"`
+	ConfiguredLevel string `json:"configured_level"`
+	// RevertAt is when an override ends, in unix microseconds.
+	RevertAt int64 `json:"revert_at,omitempty"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"context"
+	"net/http"
+)
+
+// EventMeta identifies the stream message an event handler is applying.
+type EventMeta struct {
+	Subject  string
+	Sequence uint64
+}
+
+// eventMetaContextKey is the context.Context key to store the event metadata.
+var eventMetaContextKey = contextKey("event_meta")
+
+func ContextWithEventMeta(ctx context.Context, meta EventMeta) context.Context {
+	return context.WithValue(ctx, eventMetaContextKey, meta)
+}
+
+func EventMetaFromContext(ctx context.Context) (EventMeta, bool) {
+	meta, ok := ctx.Value(eventMetaContextKey).(EventMeta)
+
+	return meta, ok
+}
+
+type GetProjectionOffsetsRequest struct{}
+
+func (r *GetProjectionOffsetsRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+// EventFailed is a stream message the consumer gave up on, it is not
+// redelivered.
+type EventFailed struct {
+	Subject  string
+	Sequence uint64
+	Error    string
+}
+
+// GetProjectionOffsetsResponse has the offset of every subject and the recent
+// sequences below it that failed, a write at a failed sequence is never
+// visible.
+type GetProjectionOffsetsResponse struct {
+	Result  bool                `json:"result"`
+	Offsets map[string]uint64   `json:"offsets"`
+	Failed  map[string][]uint64 `json:"failed,omitempty"`
+}
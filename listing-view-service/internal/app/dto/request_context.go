@@ -2,11 +2,23 @@ package dto
 
 import (
 	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
 	"net/http"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/logger"
 )
 
+// RequestIDHeader carries the correlation ID across HTTP calls and NATS messages.
+const RequestIDHeader = "X-Transaction-Id"
+
+// maxRequestIDLength bounds the request IDs taken from the callers.
+const maxRequestIDLength = 128
+
 type RequestContext struct {
-	Language string `mapstructure:"language"`
+	Language  string `mapstructure:"language"`
+	RequestID string `mapstructure:"request_id"`
 }
 
 type contextKey string
@@ -20,6 +32,7 @@ func RequestWithContext(req *http.Request) (*http.Request, error) {
 	reqContext.Language = getLanguage(req)
 
 	ctx := context.WithValue(req.Context(), requestContextKey, reqContext)
+	ctx = ContextWithRequestID(ctx, getRequestID(req))
 
 	return req.WithContext(ctx), nil
 }
@@ -30,6 +43,59 @@ func RequestFromContext(ctx context.Context) (RequestContext, bool) {
 	return reqContext, ok
 }
 
+// ContextWithRequestID stores id in the request context and adds it to every
+// log record written with the returned context.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	reqContext, _ := RequestFromContext(ctx)
+	reqContext.RequestID = id
+
+	ctx = context.WithValue(ctx, requestContextKey, reqContext)
+
+	return logger.ContextWithAttrs(ctx, slog.String("request_id", id))
+}
+
+// NewRequestID generates a random UUIDv4 request ID.
+func NewRequestID() string {
+	var id [16]byte
+
+	_, _ = rand.Read(id[:])
+
+	id[6] = (id[6] & 0x0f) | 0x40 // version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
 func getLanguage(req *http.Request) string {
 	return req.Header.Get("Accept-Language")
 }
+
+// ValidRequestID reports whether a request ID received from a caller can be
+// kept, it is logged and forwarded so it must be at most 128 characters of
+// [A-Za-z0-9._-].
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, char := range []byte(id) {
+		switch {
+		case 'a' <= char && char <= 'z', 'A' <= char && char <= 'Z', '0' <= char && char <= '9':
+		case char == '.', char == '_', char == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// getRequestID keeps the request ID of the caller, or replaces it with a new
+// one when it is missing or invalid.
+func getRequestID(req *http.Request) string {
+	if id := req.Header.Get(RequestIDHeader); ValidRequestID(id) {
+		return id
+	}
+
+	return NewRequestID()
+}
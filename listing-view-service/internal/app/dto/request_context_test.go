@@ -5,6 +5,7 @@ package dto
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,3 +33,62 @@ func TestRequestContext(t *testing.T) {
 
 	assert.Equal(t, language, reqContext.Language)
 }
+
+func TestRequestContext_RequestID(t *testing.T) {
+	req, err := http.NewRequestWithContext(context.Background(), "GET", "/foo", nil)
+	assert.NoError(t, err)
+
+	out, err := RequestWithContext(req)
+	assert.NoError(t, err)
+
+	reqContext, ok := RequestFromContext(out.Context())
+	assert.True(t, ok)
+	assert.Len(t, reqContext.RequestID, 36)
+
+	req.Header.Set(RequestIDHeader, "abc-123")
+
+	out, err = RequestWithContext(req)
+	assert.NoError(t, err)
+
+	reqContext, ok = RequestFromContext(out.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "abc-123", reqContext.RequestID)
+}
+
+func TestRequestContext_InvalidRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		keep bool
+	}{
+		{name: "uuid", id: "0b8e7c1a-5b8e-4c7e-9f3b-2d1c0a9e8f7d", keep: true},
+		{name: "dots and underscores", id: "job_42.retry-1", keep: true},
+		{name: "max length", id: strings.Repeat("a", 128), keep: true},
+		{name: "too long", id: strings.Repeat("a", 129)},
+		{name: "spaces", id: "abc 123"},
+		{name: "log injection", id: "abc\nlevel=ERROR"},
+		{name: "non ascii", id: "abc-é"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), "GET", "/foo", nil)
+			assert.NoError(t, err)
+
+			req.Header.Set(RequestIDHeader, tt.id)
+
+			out, err := RequestWithContext(req)
+			assert.NoError(t, err)
+
+			reqContext, _ := RequestFromContext(out.Context())
+			assert.Equal(t, tt.keep, ValidRequestID(tt.id))
+
+			if tt.keep {
+				assert.Equal(t, tt.id, reqContext.RequestID)
+			} else {
+				assert.NotEqual(t, tt.id, reqContext.RequestID)
+				assert.Len(t, reqContext.RequestID, 36)
+			}
+		})
+	}
+}
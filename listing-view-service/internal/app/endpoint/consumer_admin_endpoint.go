@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+)
+
+type ConsumerAdminService interface {
+	GetConsumers(ctx context.Context) (dto.GetConsumersResponse, error)
+	GetConsumer(ctx context.Context, req dto.ConsumerRequest) (dto.ConsumerResponse, error)
+	PauseConsumer(ctx context.Context, req dto.ConsumerRequest) (dto.ConsumerResponse, error)
+	ResumeConsumer(ctx context.Context, req dto.ConsumerRequest) (dto.ConsumerResponse, error)
+}
+
+func NewConsumerAdminEndpoint(svc ConsumerAdminService) ConsumerAdmin {
+	return ConsumerAdmin{
+		GetAll: MakeGetConsumersEndpoint(svc),
+		Get:    makeConsumerEndpoint(svc.GetConsumer),
+		Pause:  makeConsumerEndpoint(svc.PauseConsumer),
+		Resume: makeConsumerEndpoint(svc.ResumeConsumer),
+	}
+}
+
+func MakeGetConsumersEndpoint(svc ConsumerAdminService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if _, ok := request.(*dto.GetConsumersRequest); !ok {
+			return nil, fmt.Errorf("consumer admin service: %w", ErrInvalidType)
+		}
+
+		res, err := svc.GetConsumers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("consumer admin service: %w", err)
+		}
+
+		return res, nil
+	}
+}
+
+// makeConsumerEndpoint adapts the service methods acting on a single consumer.
+func makeConsumerEndpoint(
+	fn func(ctx context.Context, req dto.ConsumerRequest) (dto.ConsumerResponse, error),
+) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ConsumerRequest)
+		if !ok {
+			return nil, fmt.Errorf("consumer admin service: %w", ErrInvalidType)
+		}
+
+		res, err := fn(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("consumer admin service: %w", err)
+		}
+
+		return res, nil
+	}
+}
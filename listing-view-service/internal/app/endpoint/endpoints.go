@@ -23,7 +23,28 @@ type User struct {
 	OnCreated endpoint.Endpoint
 }
 
+type Projection struct {
+	GetOffsets endpoint.Endpoint
+	OnFailed   endpoint.Endpoint
+}
+
+type ConsumerAdmin struct {
+	GetAll endpoint.Endpoint
+	Get    endpoint.Endpoint
+	Pause  endpoint.Endpoint
+	Resume endpoint.Endpoint
+}
+
+type LogLevel struct {
+	Get   endpoint.Endpoint
+	Set   endpoint.Endpoint
+	Reset endpoint.Endpoint
+}
+
 type Endpoint struct {
 	Listing
 	User
+	Projection
+	ConsumerAdmin
+	LogLevel
 }
@@ -0,0 +1,52 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+)
+
+type ProjectionOffsetService interface {
+	GetOffsets(ctx context.Context) (dto.GetProjectionOffsetsResponse, error)
+	RecordFailure(ctx context.Context, req dto.EventFailed) error
+}
+
+func NewProjectionEndpoint(svc ProjectionOffsetService) Projection {
+	return Projection{
+		GetOffsets: MakeGetProjectionOffsetsEndpoint(svc),
+		OnFailed:   MakeOnFailedEventEndpoint(svc),
+	}
+}
+
+func MakeGetProjectionOffsetsEndpoint(svc ProjectionOffsetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if _, ok := request.(*dto.GetProjectionOffsetsRequest); !ok {
+			return nil, fmt.Errorf("projection offset service: %w", ErrInvalidType)
+		}
+
+		res, err := svc.GetOffsets(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("projection offset service: %w", err)
+		}
+
+		return res, nil
+	}
+}
+
+func MakeOnFailedEventEndpoint(svc ProjectionOffsetService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.EventFailed)
+		if !ok {
+			return nil, fmt.Errorf("projection offset service: %w", ErrInvalidType)
+		}
+
+		err := svc.RecordFailure(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("projection offset service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
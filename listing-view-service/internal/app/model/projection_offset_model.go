@@ -0,0 +1,17 @@
+package model
+
+// ProjectionOffset is the last stream sequence applied to the projection for a subject.
+type ProjectionOffset struct {
+	Subject   string `json:"subject"`
+	Sequence  uint64 `json:"sequence"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// ProjectionFailure is a stream sequence that failed and was not applied to
+// the projection, the offset of its subject can still move past it.
+type ProjectionFailure struct {
+	Subject  string `json:"subject"`
+	Sequence uint64 `json:"sequence"`
+	Error    string `json:"error"`
+	FailedAt int64  `json:"failed_at"`
+}
@@ -4,6 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/timing"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/tracing"
 )
 
 type transactable struct {
@@ -15,6 +21,10 @@ func (r *transactable) WithTransaction(ctx context.Context,
 ) error {
 	var err error
 
+	// the statements of the transaction are timed on their own
+	ctx, span := startDBSpan(ctx, "transaction")
+	defer span.End()
+
 	dbTx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -35,3 +45,63 @@ func (r *transactable) WithTransaction(ctx context.Context,
 
 	return err
 }
+
+// queryTimer times repository statements, each one is a child span of the
+// caller, counts in the DB time of the request and is logged when slower than
+// slowQueryThreshold.
+type queryTimer struct {
+	slowQueryThreshold time.Duration
+}
+
+// startQuery starts timing statement, the returned function stops it. Only
+// the types of args are logged, their values may be personal data.
+func (t queryTimer) startQuery(ctx context.Context, statement string, args ...any) (context.Context, func()) {
+	ctx, span := startDBSpan(ctx, statement)
+	start := time.Now()
+
+	return ctx, func() {
+		span.End()
+
+		duration := time.Since(start)
+		timing.AddDB(ctx, duration)
+
+		if t.slowQueryThreshold > 0 && duration > t.slowQueryThreshold {
+			slog.WarnContext(ctx, "slow query",
+				slog.String("statement", statement),
+				slog.Duration("duration_ns", duration),
+				slog.Any("args", redactArgs(args)),
+			)
+		}
+	}
+}
+
+func startDBSpan(ctx context.Context, name string) (context.Context, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, name, tracing.SpanKindClient)
+	span.SetAttribute("db.system", "postgresql")
+	span.SetAttribute("db.statement_name", name)
+
+	return ctx, span
+}
+
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = fmt.Sprintf("<%T>", arg)
+	}
+
+	return redacted
+}
+
+// memoryTransactable runs the transactions of the in-memory repositories, the
+// transaction is carried by the context and txFunc gets a nil *sql.Tx.
+type memoryTransactable struct {
+	db *memdb.DB
+}
+
+func (r *memoryTransactable) WithTransaction(ctx context.Context,
+	txFunc func(context.Context, *sql.Tx) error,
+) error {
+	return r.db.WithTransaction(ctx, func(ctx context.Context) error {
+		return txFunc(ctx, nil)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
 )
@@ -13,19 +14,26 @@ type ListingRepository struct {
 	db *sql.DB
 	errorMapper
 	transactable
+	queryTimer
 }
 
-func NewListingRepository(db *sql.DB) *ListingRepository {
+func NewListingRepository(db *sql.DB, slowQueryThreshold time.Duration) *ListingRepository {
 	return &ListingRepository{
 		db: db,
 		transactable: transactable{
 			db: db,
 		},
+		queryTimer: queryTimer{
+			slowQueryThreshold: slowQueryThreshold,
+		},
 	}
 }
 
 func (r *ListingRepository) GetAll(ctx context.Context, limit,
 	offset int, userID *int64) ([]model.Listing, error) {
+	ctx, endQuery := r.startQuery(ctx, "ListingRepository.GetAll", limit, offset, userID)
+	defer endQuery()
+
 	var args []interface{}
 	var paramIndex int = 1
 	var user model.User
@@ -87,6 +95,10 @@ func (r *ListingRepository) GetAll(ctx context.Context, limit,
 }
 
 func (r *ListingRepository) CreateTx(ctx context.Context, tx *sql.Tx, listing *model.Listing) error {
+	ctx, endQuery := r.startQuery(ctx, "ListingRepository.CreateTx", listing.ID, listing.UserID,
+		listing.ListingType, listing.Price, listing.User, listing.CreatedAt, listing.UpdatedAt)
+	defer endQuery()
+
 	query := `
 		INSERT INTO listings (id, user_id, listing_type, price, user_detail, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
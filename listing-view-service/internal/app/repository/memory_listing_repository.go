@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+)
+
+// MemoryListingRepository keeps the listing projection in memory, for running
+// the service without Postgres. The data is lost when the process stops.
+type MemoryListingRepository struct {
+	listings *memdb.Table[int64, model.Listing]
+	memoryTransactable
+}
+
+func NewMemoryListingRepository(db *memdb.DB) *MemoryListingRepository {
+	return &MemoryListingRepository{
+		listings: memdb.NewTable[int64, model.Listing](db),
+		memoryTransactable: memoryTransactable{
+			db: db,
+		},
+	}
+}
+
+func (r *MemoryListingRepository) GetAll(ctx context.Context, limit,
+	offset int, userID *int64) ([]model.Listing, error) {
+	var keep func(model.Listing) bool
+	if userID != nil {
+		keep = func(listing model.Listing) bool {
+			return listing.UserID == *userID
+		}
+	}
+
+	listings := r.listings.Select(ctx, keep, func(a, b model.Listing) bool {
+		return a.CreatedAt > b.CreatedAt
+	})
+
+	return memdb.Page(listings, limit, offset), nil
+}
+
+// CreateTx inserts the listing or replaces all but its creation time, like the
+// Postgres upsert.
+func (r *MemoryListingRepository) CreateTx(ctx context.Context, _ *sql.Tx, listing *model.Listing) error {
+	r.listings.Upsert(ctx, listing.ID, func(current model.Listing, exists bool) model.Listing {
+		updated := *listing
+		if exists {
+			updated.CreatedAt = current.CreatedAt
+		}
+
+		return updated
+	})
+
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryListingRepository(t *testing.T) {
+	ctx := context.Background()
+	db := memdb.New()
+	listings := NewMemoryListingRepository(db)
+	offsets := NewMemoryProjectionOffsetRepository(db)
+
+	save := func(listing model.Listing, sequence uint64) error {
+		return listings.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			if err := listings.CreateTx(ctx, tx, &listing); err != nil {
+				return err
+			}
+
+			return offsets.SaveTx(ctx, tx, &model.ProjectionOffset{Subject: "listing.created", Sequence: sequence})
+		})
+	}
+
+	assert.NoError(t, save(model.Listing{ID: 1, UserID: 1, Price: 100, CreatedAt: 1}, 1))
+	assert.NoError(t, save(model.Listing{ID: 2, UserID: 2, Price: 200, CreatedAt: 2}, 3))
+	// a redelivered older event doesn't move the offset back
+	assert.NoError(t, save(model.Listing{ID: 1, UserID: 1, Price: 150, CreatedAt: 5}, 2))
+
+	t.Run("get all latest first", func(t *testing.T) {
+		got, err := listings.GetAll(ctx, 10, 0, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Listing{
+			{ID: 2, UserID: 2, Price: 200, CreatedAt: 2},
+			{ID: 1, UserID: 1, Price: 150, CreatedAt: 1},
+		}, got)
+	})
+
+	t.Run("get all of a user", func(t *testing.T) {
+		userID := int64(2)
+		got, err := listings.GetAll(ctx, 10, 0, &userID)
+
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, int64(2), got[0].ID)
+	})
+
+	t.Run("offset only moves forward", func(t *testing.T) {
+		got, err := offsets.GetAll(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.ProjectionOffset{{Subject: "listing.created", Sequence: 3}}, got)
+	})
+
+	t.Run("rollback undoes the listing and the offset", func(t *testing.T) {
+		err := listings.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			if err := listings.CreateTx(ctx, tx, &model.Listing{ID: 3, CreatedAt: 3}); err != nil {
+				return err
+			}
+
+			if err := offsets.SaveTx(ctx, tx, &model.ProjectionOffset{Subject: "listing.created", Sequence: 4}); err != nil {
+				return err
+			}
+
+			return errors.New("failed")
+		})
+		assert.Error(t, err)
+
+		got, _ := listings.GetAll(ctx, 10, 0, nil)
+		assert.Len(t, got, 2)
+
+		offset, _ := offsets.GetAll(ctx)
+		assert.Equal(t, uint64(3), offset[0].Sequence)
+	})
+}
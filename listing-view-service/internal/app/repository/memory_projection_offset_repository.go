@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+)
+
+// MemoryProjectionOffsetRepository keeps the projection offsets in memory, it
+// must share the DB of the projection repositories to be saved in their
+// transactions.
+type MemoryProjectionOffsetRepository struct {
+	offsets  *memdb.Table[string, model.ProjectionOffset]
+	failures *memdb.Table[projectionFailureKey, model.ProjectionFailure]
+}
+
+// projectionFailureKey is the primary key of the failures, like in Postgres.
+type projectionFailureKey struct {
+	subject  string
+	sequence uint64
+}
+
+func NewMemoryProjectionOffsetRepository(db *memdb.DB) *MemoryProjectionOffsetRepository {
+	return &MemoryProjectionOffsetRepository{
+		offsets:  memdb.NewTable[string, model.ProjectionOffset](db),
+		failures: memdb.NewTable[projectionFailureKey, model.ProjectionFailure](db),
+	}
+}
+
+func (r *MemoryProjectionOffsetRepository) GetAll(ctx context.Context) ([]model.ProjectionOffset, error) {
+	return r.offsets.Select(ctx, nil, func(a, b model.ProjectionOffset) bool {
+		return a.Subject < b.Subject
+	}), nil
+}
+
+// SaveTx moves the offset of the subject forward, an older sequence never
+// overwrites a newer one.
+func (r *MemoryProjectionOffsetRepository) SaveTx(ctx context.Context, _ *sql.Tx,
+	offset *model.ProjectionOffset,
+) error {
+	r.offsets.Upsert(ctx, offset.Subject, func(current model.ProjectionOffset, exists bool) model.ProjectionOffset {
+		updated := *offset
+		if exists && current.Sequence > updated.Sequence {
+			updated.Sequence = current.Sequence
+		}
+
+		return updated
+	})
+
+	return nil
+}
+
+// SaveFailure records a sequence the projection gave up on, recording it again
+// keeps the first error.
+func (r *MemoryProjectionOffsetRepository) SaveFailure(ctx context.Context, failure *model.ProjectionFailure) error {
+	key := projectionFailureKey{subject: failure.Subject, sequence: failure.Sequence}
+
+	r.failures.Upsert(ctx, key, func(current model.ProjectionFailure, exists bool) model.ProjectionFailure {
+		if exists {
+			return current
+		}
+
+		return *failure
+	})
+
+	return nil
+}
+
+// GetFailures returns the limit most recent failed sequences, newest first.
+func (r *MemoryProjectionOffsetRepository) GetFailures(ctx context.Context, limit int) ([]model.ProjectionFailure, error) {
+	failures := r.failures.Select(ctx, nil, func(a, b model.ProjectionFailure) bool {
+		return a.Sequence > b.Sequence
+	})
+
+	return memdb.Page(failures, limit, 0), nil
+}
@@ -0,0 +1,40 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryProjectionOffsetRepository_Failures(t *testing.T) {
+	ctx := context.Background()
+	offsets := NewMemoryProjectionOffsetRepository(memdb.New())
+
+	for _, failure := range []model.ProjectionFailure{
+		{Subject: "listing.created", Sequence: 3, Error: "user not found"},
+		{Subject: "user.created", Sequence: 5, Error: "invalid payload"},
+		{Subject: "listing.created", Sequence: 8, Error: "user not found"},
+		{Subject: "listing.created", Sequence: 3, Error: "retried"},
+	} {
+		assert.NoError(t, offsets.SaveFailure(ctx, &failure))
+	}
+
+	got, err := offsets.GetFailures(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ProjectionFailure{
+		{Subject: "listing.created", Sequence: 8, Error: "user not found"},
+		{Subject: "user.created", Sequence: 5, Error: "invalid payload"},
+		{Subject: "listing.created", Sequence: 3, Error: "user not found"},
+	}, got)
+
+	got, err = offsets.GetFailures(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ProjectionFailure{
+		{Subject: "listing.created", Sequence: 8, Error: "user not found"},
+	}, got)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/memdb"
+)
+
+// MemoryUserRepository keeps the users in memory, for running the service
+// without Postgres. The data is lost when the process stops.
+type MemoryUserRepository struct {
+	users *memdb.Table[int64, model.User]
+	memoryTransactable
+}
+
+func NewMemoryUserRepository(db *memdb.DB) *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: memdb.NewTable[int64, model.User](db),
+		memoryTransactable: memoryTransactable{
+			db: db,
+		},
+	}
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id int64) (model.User, error) {
+	user, ok := r.users.Get(ctx, id)
+	if !ok {
+		return model.User{}, sql.ErrNoRows
+	}
+
+	return user, nil
+}
+
+// CreateTx inserts the user or updates its name, like the Postgres upsert.
+func (r *MemoryUserRepository) CreateTx(ctx context.Context, _ *sql.Tx, user *model.User) error {
+	r.users.Upsert(ctx, user.ID, func(current model.User, exists bool) model.User {
+		if !exists {
+			return *user
+		}
+
+		current.Name = user.Name
+		current.UpdatedAt = user.UpdatedAt
+
+		return current
+	})
+
+	return nil
+}
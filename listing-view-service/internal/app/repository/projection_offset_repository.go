@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+)
+
+type ProjectionOffsetRepository struct {
+	db *sql.DB
+	errorMapper
+	transactable
+	queryTimer
+}
+
+func NewProjectionOffsetRepository(db *sql.DB, slowQueryThreshold time.Duration) *ProjectionOffsetRepository {
+	return &ProjectionOffsetRepository{
+		db: db,
+		transactable: transactable{
+			db: db,
+		},
+		queryTimer: queryTimer{
+			slowQueryThreshold: slowQueryThreshold,
+		},
+	}
+}
+
+func (r *ProjectionOffsetRepository) GetAll(ctx context.Context) ([]model.ProjectionOffset, error) {
+	ctx, endQuery := r.startQuery(ctx, "ProjectionOffsetRepository.GetAll")
+	defer endQuery()
+
+	query := `
+		SELECT subject, sequence, updated_at
+		FROM projection_offsets
+		ORDER BY subject
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	defer rows.Close()
+
+	offsets := []model.ProjectionOffset{}
+	for rows.Next() {
+		var offset model.ProjectionOffset
+
+		err := rows.Scan(&offset.Subject, &offset.Sequence, &offset.UpdatedAt)
+		if err != nil {
+			return nil, r.errorMapper.mapError(err)
+		}
+
+		offsets = append(offsets, offset)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	return offsets, nil
+}
+
+// SaveTx moves the offset of the subject forward, an older sequence never
+// overwrites a newer one.
+func (r *ProjectionOffsetRepository) SaveTx(ctx context.Context, tx *sql.Tx,
+	offset *model.ProjectionOffset,
+) error {
+	ctx, endQuery := r.startQuery(ctx, "ProjectionOffsetRepository.SaveTx", offset.Subject, offset.Sequence, offset.UpdatedAt)
+	defer endQuery()
+
+	query := `
+		INSERT INTO projection_offsets (subject, sequence, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subject) DO UPDATE SET
+			sequence = GREATEST(projection_offsets.sequence, $2),
+			updated_at = $3
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return r.errorMapper.mapError(err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, offset.Subject, offset.Sequence, offset.UpdatedAt)
+	if err != nil {
+		return r.errorMapper.mapError(err)
+	}
+
+	return nil
+}
+
+// SaveFailure records a sequence the projection gave up on, recording it again
+// keeps the first error.
+func (r *ProjectionOffsetRepository) SaveFailure(ctx context.Context, failure *model.ProjectionFailure) error {
+	ctx, endQuery := r.startQuery(ctx, "ProjectionOffsetRepository.SaveFailure", failure.Subject, failure.Sequence)
+	defer endQuery()
+
+	query := `
+		INSERT INTO projection_failures (subject, sequence, error, failed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subject, sequence) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, failure.Subject, failure.Sequence, failure.Error, failure.FailedAt)
+	if err != nil {
+		return r.errorMapper.mapError(err)
+	}
+
+	return nil
+}
+
+// GetFailures returns the limit most recent failed sequences, newest first.
+func (r *ProjectionOffsetRepository) GetFailures(ctx context.Context, limit int) ([]model.ProjectionFailure, error) {
+	ctx, endQuery := r.startQuery(ctx, "ProjectionOffsetRepository.GetFailures", limit)
+	defer endQuery()
+
+	query := `
+		SELECT subject, sequence, error, failed_at
+		FROM projection_failures
+		ORDER BY sequence DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	defer rows.Close()
+
+	failures := []model.ProjectionFailure{}
+	for rows.Next() {
+		var failure model.ProjectionFailure
+
+		err := rows.Scan(&failure.Subject, &failure.Sequence, &failure.Error, &failure.FailedAt)
+		if err != nil {
+			return nil, r.errorMapper.mapError(err)
+		}
+
+		failures = append(failures, failure)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	return failures, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
 )
@@ -11,18 +12,25 @@ type UserRepository struct {
 	db *sql.DB
 	errorMapper
 	transactable
+	queryTimer
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db *sql.DB, slowQueryThreshold time.Duration) *UserRepository {
 	return &UserRepository{
 		db: db,
 		transactable: transactable{
 			db: db,
 		},
+		queryTimer: queryTimer{
+			slowQueryThreshold: slowQueryThreshold,
+		},
 	}
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (model.User, error) {
+	ctx, endQuery := r.startQuery(ctx, "UserRepository.GetByID", id)
+	defer endQuery()
+
 	query := `
 		SELECT id, name, created_at, updated_at
 		FROM users
@@ -46,6 +54,9 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (model.User, err
 }
 
 func (r *UserRepository) CreateTx(ctx context.Context, tx *sql.Tx, user *model.User) error {
+	ctx, endQuery := r.startQuery(ctx, "UserRepository.CreateTx", user.ID, user.Name, user.CreatedAt, user.UpdatedAt)
+	defer endQuery()
+
 	query := `
 		INSERT INTO users (id, name, created_at, updated_at)
 		VALUES ($1, $2, $3, $4)
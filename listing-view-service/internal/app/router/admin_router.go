@@ -0,0 +1,67 @@
+package router
+
+import (
+	"log/slog"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/health"
+	httptransport "github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/transport/http"
+)
+
+// MakeAdminRouter builds the router of the probes and operator endpoints of
+// the consumer process, it is only served on the internal port. The admin
+// API requires a service token like the one of the HTTP server.
+func MakeAdminRouter(endpts endpoint.Endpoint, cfg config.Config, checks *health.Health) *chi.Mux {
+	router := chi.NewRouter()
+
+	serviceTokens := httptransport.NewServiceTokens(cfg.ServiceTokens)
+	config.OnChange(func(cfg config.Config) {
+		serviceTokens.SetTokens(cfg.ServiceTokens)
+	})
+
+	router.Get("/health/live", checks.LiveHandler())
+	router.Get("/health/ready", checks.ReadyHandler())
+
+	router.Group(func(router chi.Router) {
+		router.Use(
+			httptransport.HeaderMiddleware(),
+			httptransport.LoggingMiddleware(slog.Default()),
+			httptransport.Recoverer(slog.Default()),
+			render.SetContentType(render.ContentTypeJSON),
+		)
+
+		router.Route("/admin/consumers", func(router chi.Router) {
+			router.Use(serviceTokens.Handler)
+
+			router.Get("/", httptransport.MakeHandlerFunc(
+				endpts.ConsumerAdmin.GetAll,
+				httptransport.DecodeRequest[dto.GetConsumersRequest],
+				httptransport.ResponseWithBody,
+			))
+
+			router.Get("/{name}", httptransport.MakeHandlerFunc(
+				endpts.ConsumerAdmin.Get,
+				httptransport.DecodeRequest[dto.ConsumerRequest],
+				httptransport.ResponseWithBody,
+			))
+
+			router.Post("/{name}/pause", httptransport.MakeHandlerFunc(
+				endpts.ConsumerAdmin.Pause,
+				httptransport.DecodeRequest[dto.ConsumerRequest],
+				httptransport.ResponseWithBody,
+			))
+
+			router.Post("/{name}/resume", httptransport.MakeHandlerFunc(
+				endpts.ConsumerAdmin.Resume,
+				httptransport.DecodeRequest[dto.ConsumerRequest],
+				httptransport.ResponseWithBody,
+			))
+		})
+	})
+
+	return router
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/service"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminRouter_ServiceToken(t *testing.T) {
+	adminSvc := service.NewConsumerAdminService(time.Minute)
+	router := MakeAdminRouter(endpoint.Endpoint{
+		ConsumerAdmin: endpoint.NewConsumerAdminEndpoint(adminSvc),
+	}, config.Config{ServiceTokens: "secret"}, health.New(time.Second))
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "probe without token", method: http.MethodGet, path: "/health/live", wantStatus: http.StatusOK},
+		{name: "list without token", method: http.MethodGet, path: "/admin/consumers", wantStatus: http.StatusUnauthorized},
+		{
+			name: "pause without token", method: http.MethodPost,
+			path: "/admin/consumers/listing_view_user_created/pause", wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "resume with wrong token", method: http.MethodPost, token: "guess",
+			path: "/admin/consumers/listing_view_user_created/resume", wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "list with token", method: http.MethodGet, path: "/admin/consumers",
+			token: "secret", wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
@@ -5,10 +5,12 @@ package router
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/health"
 )
 
 func TestConfigRoute(t *testing.T) {
@@ -24,10 +26,12 @@ func TestConfigRoute(t *testing.T) {
 
 	router := MakeHTTPRouter(
 		endpoint.Endpoint{
-			Listing: endpoint.Listing{},
-			User:    endpoint.User{},
+			Listing:    endpoint.Listing{},
+			User:       endpoint.User{},
+			Projection: endpoint.Projection{},
 		},
 		cfg,
+		health.New(time.Second),
 	)
 
 	testCases := []struct {
@@ -42,12 +46,54 @@ func TestConfigRoute(t *testing.T) {
 			path:        "/health",
 			shouldMatch: true,
 		},
+		{
+			name:        "Liveness",
+			method:      http.MethodGet,
+			path:        "/health/live",
+			shouldMatch: true,
+		},
+		{
+			name:        "Readiness",
+			method:      http.MethodGet,
+			path:        "/health/ready",
+			shouldMatch: true,
+		},
+		{
+			name:        "AsyncAPI Document",
+			method:      http.MethodGet,
+			path:        "/docs/asyncapi.json",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Log Level",
+			method:      http.MethodGet,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Set Log Level",
+			method:      http.MethodPut,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Reset Log Level",
+			method:      http.MethodDelete,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
 		{
 			name:        "Get All Listings",
 			method:      http.MethodGet,
 			path:        "/listings",
 			shouldMatch: true,
 		},
+		{
+			name:        "Get Projection Offsets",
+			method:      http.MethodGet,
+			path:        "/projection/offsets",
+			shouldMatch: true,
+		},
 	}
 
 	chiCtx := chi.NewRouteContext()
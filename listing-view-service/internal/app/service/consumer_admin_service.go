@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/exception"
+)
+
+type Consumer interface {
+	Name() string
+	Status(ctx context.Context, errorWindow time.Duration) (dto.ConsumerStatus, error)
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+}
+
+// ConsumerAdminService reports the lag of the projection consumers and lets
+// operators pause them, for instance to stop projection writes during a
+// database maintenance.
+type ConsumerAdminService struct {
+	consumers   []Consumer
+	errorWindow time.Duration
+}
+
+func NewConsumerAdminService(errorWindow time.Duration, consumers ...Consumer) *ConsumerAdminService {
+	return &ConsumerAdminService{
+		consumers:   consumers,
+		errorWindow: errorWindow,
+	}
+}
+
+func (s *ConsumerAdminService) GetConsumers(ctx context.Context) (dto.GetConsumersResponse, error) {
+	response := dto.GetConsumersResponse{
+		Result:    true,
+		Consumers: make([]dto.ConsumerStatus, 0, len(s.consumers)),
+	}
+
+	for _, consumer := range s.consumers {
+		status, err := consumer.Status(ctx, s.errorWindow)
+		if err != nil {
+			return dto.GetConsumersResponse{}, fmt.Errorf("get status of %s: %w", consumer.Name(), err)
+		}
+
+		response.Consumers = append(response.Consumers, status)
+	}
+
+	return response, nil
+}
+
+func (s *ConsumerAdminService) GetConsumer(ctx context.Context,
+	request dto.ConsumerRequest,
+) (dto.ConsumerResponse, error) {
+	consumer, err := s.find(request.Name)
+	if err != nil {
+		return dto.ConsumerResponse{}, err
+	}
+
+	return s.status(ctx, consumer)
+}
+
+// PauseConsumer stops every process sharing the consumer from handling
+// messages until it is resumed, it returns once the messages in flight in this
+// process are handled.
+func (s *ConsumerAdminService) PauseConsumer(ctx context.Context,
+	request dto.ConsumerRequest,
+) (dto.ConsumerResponse, error) {
+	consumer, err := s.find(request.Name)
+	if err != nil {
+		return dto.ConsumerResponse{}, err
+	}
+
+	if err := consumer.Pause(ctx); err != nil {
+		return dto.ConsumerResponse{}, fmt.Errorf("pause %s: %w", consumer.Name(), err)
+	}
+
+	return s.status(ctx, consumer)
+}
+
+func (s *ConsumerAdminService) ResumeConsumer(ctx context.Context,
+	request dto.ConsumerRequest,
+) (dto.ConsumerResponse, error) {
+	consumer, err := s.find(request.Name)
+	if err != nil {
+		return dto.ConsumerResponse{}, err
+	}
+
+	if err := consumer.Resume(ctx); err != nil {
+		return dto.ConsumerResponse{}, fmt.Errorf("resume %s: %w", consumer.Name(), err)
+	}
+
+	return s.status(ctx, consumer)
+}
+
+func (s *ConsumerAdminService) find(name string) (Consumer, error) {
+	for _, consumer := range s.consumers {
+		if consumer.Name() == name {
+			return consumer, nil
+		}
+	}
+
+	err := exception.ErrRecordNotFound
+	err.MessageVars = map[string]interface{}{
+		"name": "consumer",
+	}
+
+	return nil, err
+}
+
+func (s *ConsumerAdminService) status(ctx context.Context, consumer Consumer) (dto.ConsumerResponse, error) {
+	status, err := consumer.Status(ctx, s.errorWindow)
+	if err != nil {
+		return dto.ConsumerResponse{}, fmt.Errorf("get status of %s: %w", consumer.Name(), err)
+	}
+
+	return dto.ConsumerResponse{
+		Result:   true,
+		Consumer: status,
+	}, nil
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerAdminService_GetConsumers(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc := NewConsumerAdminService(5*time.Minute,
+			&MockConsumer{name: "listing_view_user_created"},
+			&MockConsumer{name: "listing_view_listing_created", paused: true},
+		)
+
+		got, err := svc.GetConsumers(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, dto.GetConsumersResponse{
+			Result: true,
+			Consumers: []dto.ConsumerStatus{
+				{Name: "listing_view_user_created", ErrorWindow: "5m0s"},
+				{Name: "listing_view_listing_created", Paused: true, ErrorWindow: "5m0s"},
+			},
+		}, got)
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		svc := NewConsumerAdminService(5*time.Minute,
+			&MockConsumer{name: "listing_view_user_created", statusErr: ErrMockDB},
+		)
+
+		_, err := svc.GetConsumers(context.Background())
+		assert.ErrorIs(t, err, ErrMockDB)
+	})
+}
+
+func TestConsumerAdminService_PauseResume(t *testing.T) {
+	consumer := &MockConsumer{name: "listing_view_user_created"}
+	svc := NewConsumerAdminService(time.Minute, consumer)
+	req := dto.ConsumerRequest{Name: "listing_view_user_created"}
+
+	got, err := svc.PauseConsumer(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, got.Consumer.Paused)
+
+	got, err = svc.GetConsumer(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, got.Consumer.Paused)
+
+	got, err = svc.ResumeConsumer(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, got.Consumer.Paused)
+
+	t.Run("pause error", func(t *testing.T) {
+		consumer.pauseErr = context.DeadlineExceeded
+		defer func() { consumer.pauseErr = nil }()
+
+		_, err := svc.PauseConsumer(context.Background(), req)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("resume error", func(t *testing.T) {
+		consumer.paused = true
+		consumer.resumeErr = ErrMockDB
+
+		_, err := svc.ResumeConsumer(context.Background(), req)
+		assert.ErrorIs(t, err, ErrMockDB)
+		assert.True(t, consumer.paused)
+	})
+
+	t.Run("unknown consumer", func(t *testing.T) {
+		_, err := svc.PauseConsumer(context.Background(), dto.ConsumerRequest{Name: "unknown"})
+
+		var appErr exception.ApplicationError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, exception.CodeNotFound, appErr.StatusCode)
+	})
+}
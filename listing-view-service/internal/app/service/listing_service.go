@@ -19,12 +19,16 @@ type ListingRepository interface {
 type ListingService struct {
 	listingsRepo ListingRepository
 	userRepo     UserRepository
+	offsetRepo   ProjectionOffsetRepository
 }
 
-func NewListingService(listingsRepo ListingRepository, userRepo UserRepository) *ListingService {
+func NewListingService(listingsRepo ListingRepository, userRepo UserRepository,
+	offsetRepo ProjectionOffsetRepository,
+) *ListingService {
 	return &ListingService{
 		listingsRepo: listingsRepo,
 		userRepo:     userRepo,
+		offsetRepo:   offsetRepo,
 	}
 }
 
@@ -50,7 +54,7 @@ func (s *ListingService) OnCreatedListing(ctx context.Context, req dto.ListingCr
 			return err
 		}
 
-		return nil
+		return saveOffsetTx(ctx, tx, s.offsetRepo)
 	})
 	if err != nil {
 		return fmt.Errorf("on created listing: %w", err)
@@ -14,7 +14,7 @@ import (
 func TestListingService_OnCreatedListing(t *testing.T) {
 	onCreatedListing := func(name string, req dto.ListingCreated, mockListingRepo *MockListingRepository, mockUserRepo *MockUserRepository, wantErr error) func(t *testing.T) {
 		return func(t *testing.T) {
-			svc := NewListingService(mockListingRepo, mockUserRepo)
+			svc := NewListingService(mockListingRepo, mockUserRepo, &MockProjectionOffsetRepository{})
 			err := svc.OnCreatedListing(context.Background(), req)
 			if wantErr != nil {
 				assert.ErrorIs(t, err, wantErr)
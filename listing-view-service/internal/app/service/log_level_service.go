@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/logger"
+)
+
+const (
+	defaultLogLevelDuration = 15 * time.Minute
+	maxLogLevelDuration     = 24 * time.Hour
+)
+
+type LevelController interface {
+	Override(level slog.Level, duration time.Duration) time.Time
+	Reset()
+	Status() logger.LevelStatus
+}
+
+// LogLevelService changes the log level of the running process, an override
+// always reverts so a forgotten debug level doesn't flood the logs.
+type LogLevelService struct {
+	levels LevelController
+}
+
+func NewLogLevelService(levels LevelController) *LogLevelService {
+	return &LogLevelService{levels: levels}
+}
+
+func (s *LogLevelService) GetLogLevel(_ context.Context) (dto.LogLevelResponse, error) {
+	return s.response(), nil
+}
+
+func (s *LogLevelService) SetLogLevel(ctx context.Context,
+	request dto.SetLogLevelRequest,
+) (dto.LogLevelResponse, error) {
+	duration := request.Duration
+	if duration == 0 {
+		duration = defaultLogLevelDuration
+	}
+
+	if duration > maxLogLevelDuration {
+		return dto.LogLevelResponse{}, dto.NewInvalidRequestError(
+			fmt.Errorf("duration is longer than %s", maxLogLevelDuration))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(request.Level)); err != nil {
+		return dto.LogLevelResponse{}, dto.NewInvalidRequestError(err)
+	}
+
+	revertAt := s.levels.Override(level, duration)
+
+	slog.WarnContext(ctx, "log level overridden",
+		slog.String("level", request.Level),
+		slog.Time("revert_at", revertAt),
+	)
+
+	return s.response(), nil
+}
+
+func (s *LogLevelService) ResetLogLevel(ctx context.Context) (dto.LogLevelResponse, error) {
+	s.levels.Reset()
+
+	slog.WarnContext(ctx, "log level override reset")
+
+	return s.response(), nil
+}
+
+func (s *LogLevelService) response() dto.LogLevelResponse {
+	status := s.levels.Status()
+
+	response := dto.LogLevelResponse{
+		Result:          true,
+		Level:           strings.ToLower(status.Level.String()),
+		ConfiguredLevel: strings.ToLower(status.Configured.String()),
+	}
+
+	if !status.RevertAt.IsZero() {
+		response.RevertAt = status.RevertAt.UnixMicro()
+	}
+
+	return response
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
 
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
 )
@@ -92,6 +95,43 @@ func (m *MockListingRepository) WithTransaction(ctx context.Context, txFunc func
 	return txFunc(ctx, &sql.Tx{})
 }
 
+// MockProjectionOffsetRepository implements ProjectionOffsetRepository interface
+type MockProjectionOffsetRepository struct {
+	offsets  []model.ProjectionOffset
+	failures []model.ProjectionFailure
+	err      error
+}
+
+func (m *MockProjectionOffsetRepository) GetAll(ctx context.Context) ([]model.ProjectionOffset, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.offsets, nil
+}
+
+func (m *MockProjectionOffsetRepository) SaveTx(ctx context.Context, tx *sql.Tx, offset *model.ProjectionOffset) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.offsets = append(m.offsets, *offset)
+	return nil
+}
+
+func (m *MockProjectionOffsetRepository) SaveFailure(ctx context.Context, failure *model.ProjectionFailure) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.failures = append(m.failures, *failure)
+	return nil
+}
+
+func (m *MockProjectionOffsetRepository) GetFailures(ctx context.Context, limit int) ([]model.ProjectionFailure, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.failures, nil
+}
+
 // Test data
 var mockUsers = []model.User{
 	{
@@ -137,3 +177,44 @@ var mockListings = []model.Listing{
 		},
 	},
 }
+
+// MockConsumer implements Consumer interface
+type MockConsumer struct {
+	name      string
+	paused    bool
+	statusErr error
+	pauseErr  error
+	resumeErr error
+}
+
+func (m *MockConsumer) Name() string {
+	return m.name
+}
+
+func (m *MockConsumer) Status(_ context.Context, errorWindow time.Duration) (dto.ConsumerStatus, error) {
+	if m.statusErr != nil {
+		return dto.ConsumerStatus{}, m.statusErr
+	}
+
+	return dto.ConsumerStatus{Name: m.name, Paused: m.paused, ErrorWindow: errorWindow.String()}, nil
+}
+
+func (m *MockConsumer) Pause(context.Context) error {
+	if m.pauseErr != nil {
+		return m.pauseErr
+	}
+
+	m.paused = true
+
+	return nil
+}
+
+func (m *MockConsumer) Resume(ctx context.Context) error {
+	if m.resumeErr != nil {
+		return m.resumeErr
+	}
+
+	m.paused = false
+
+	return nil
+}
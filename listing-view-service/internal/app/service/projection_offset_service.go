@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+)
+
+// maxReportedFailures bounds the failed sequences returned with the offsets,
+// readers wait for recent writes so the oldest failures are left out.
+const maxReportedFailures = 100
+
+type ProjectionOffsetRepository interface {
+	GetAll(ctx context.Context) ([]model.ProjectionOffset, error)
+	SaveTx(ctx context.Context, tx *sql.Tx, offset *model.ProjectionOffset) error
+	SaveFailure(ctx context.Context, failure *model.ProjectionFailure) error
+	GetFailures(ctx context.Context, limit int) ([]model.ProjectionFailure, error)
+}
+
+type ProjectionOffsetService struct {
+	offsetRepo ProjectionOffsetRepository
+}
+
+func NewProjectionOffsetService(offsetRepo ProjectionOffsetRepository) *ProjectionOffsetService {
+	return &ProjectionOffsetService{
+		offsetRepo: offsetRepo,
+	}
+}
+
+// GetOffsets returns the last applied stream sequence per subject, readers
+// compare it with the sequence of their write to know if it is visible yet.
+// The offset moves past failed sequences, they are returned apart so a reader
+// waiting for one of them does not take it as applied.
+func (s *ProjectionOffsetService) GetOffsets(ctx context.Context) (dto.GetProjectionOffsetsResponse, error) {
+	offsets, err := s.offsetRepo.GetAll(ctx)
+	if err != nil {
+		return dto.GetProjectionOffsetsResponse{}, fmt.Errorf("failed to get projection offsets: %w", err)
+	}
+
+	failures, err := s.offsetRepo.GetFailures(ctx, maxReportedFailures)
+	if err != nil {
+		return dto.GetProjectionOffsetsResponse{}, fmt.Errorf("failed to get projection failures: %w", err)
+	}
+
+	response := dto.GetProjectionOffsetsResponse{
+		Result:  true,
+		Offsets: make(map[string]uint64, len(offsets)),
+	}
+
+	for _, offset := range offsets {
+		response.Offsets[offset.Subject] = offset.Sequence
+	}
+
+	for _, failure := range failures {
+		if response.Failed == nil {
+			response.Failed = make(map[string][]uint64)
+		}
+
+		response.Failed[failure.Subject] = append(response.Failed[failure.Subject], failure.Sequence)
+	}
+
+	return response, nil
+}
+
+// RecordFailure records an event the consumer gave up on, the projection will
+// never apply it.
+func (s *ProjectionOffsetService) RecordFailure(ctx context.Context, req dto.EventFailed) error {
+	err := s.offsetRepo.SaveFailure(ctx, &model.ProjectionFailure{
+		Subject:  req.Subject,
+		Sequence: req.Sequence,
+		Error:    req.Error,
+		FailedAt: time.Now().UnixMicro(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save projection failure: %w", err)
+	}
+
+	return nil
+}
+
+// saveOffsetTx records the event being applied in the same transaction as the
+// projection write, events without stream metadata are not tracked.
+func saveOffsetTx(ctx context.Context, tx *sql.Tx, offsetRepo ProjectionOffsetRepository) error {
+	meta, ok := dto.EventMetaFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	err := offsetRepo.SaveTx(ctx, tx, &model.ProjectionOffset{
+		Subject:   meta.Subject,
+		Sequence:  meta.Sequence,
+		UpdatedAt: time.Now().UnixMicro(),
+	})
+	if err != nil {
+		return fmt.Errorf("save projection offset: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectionOffsetService_GetOffsets(t *testing.T) {
+	getOffsets := func(mockRepo *MockProjectionOffsetRepository, want dto.GetProjectionOffsetsResponse) func(t *testing.T) {
+		return func(t *testing.T) {
+			svc := NewProjectionOffsetService(mockRepo)
+			got, err := svc.GetOffsets(context.Background())
+			if mockRepo.err != nil {
+				assert.ErrorIs(t, err, mockRepo.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	}
+
+	t.Run("success", getOffsets(
+		&MockProjectionOffsetRepository{offsets: []model.ProjectionOffset{
+			{Subject: "listing.created", Sequence: 7},
+			{Subject: "user.created", Sequence: 12},
+		}},
+		dto.GetProjectionOffsetsResponse{
+			Result: true,
+			Offsets: map[string]uint64{
+				"listing.created": 7,
+				"user.created":    12,
+			},
+		},
+	))
+
+	t.Run("with_failures", getOffsets(
+		&MockProjectionOffsetRepository{
+			offsets: []model.ProjectionOffset{
+				{Subject: "listing.created", Sequence: 9},
+			},
+			failures: []model.ProjectionFailure{
+				{Subject: "listing.created", Sequence: 8},
+				{Subject: "listing.created", Sequence: 4},
+			},
+		},
+		dto.GetProjectionOffsetsResponse{
+			Result: true,
+			Offsets: map[string]uint64{
+				"listing.created": 9,
+			},
+			Failed: map[string][]uint64{
+				"listing.created": {8, 4},
+			},
+		},
+	))
+
+	t.Run("db_error", getOffsets(
+		&MockProjectionOffsetRepository{err: ErrMockDB},
+		dto.GetProjectionOffsetsResponse{},
+	))
+}
+
+func TestProjectionOffsetService_RecordFailure(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := &MockProjectionOffsetRepository{}
+		svc := NewProjectionOffsetService(mockRepo)
+
+		err := svc.RecordFailure(context.Background(), dto.EventFailed{
+			Subject:  "listing.created",
+			Sequence: 8,
+			Error:    "user not found",
+		})
+		assert.NoError(t, err)
+
+		if assert.Len(t, mockRepo.failures, 1) {
+			assert.Equal(t, "listing.created", mockRepo.failures[0].Subject)
+			assert.Equal(t, uint64(8), mockRepo.failures[0].Sequence)
+			assert.Equal(t, "user not found", mockRepo.failures[0].Error)
+			assert.NotZero(t, mockRepo.failures[0].FailedAt)
+		}
+	})
+
+	t.Run("db_error", func(t *testing.T) {
+		svc := NewProjectionOffsetService(&MockProjectionOffsetRepository{err: ErrMockDB})
+
+		err := svc.RecordFailure(context.Background(), dto.EventFailed{Subject: "listing.created", Sequence: 8})
+		assert.ErrorIs(t, err, ErrMockDB)
+	})
+}
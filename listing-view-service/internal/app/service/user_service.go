@@ -18,12 +18,14 @@ type UserRepository interface {
 }
 
 type UserService struct {
-	userRepo UserRepository
+	userRepo   UserRepository
+	offsetRepo ProjectionOffsetRepository
 }
 
-func NewUserService(userRepo UserRepository) *UserService {
+func NewUserService(userRepo UserRepository, offsetRepo ProjectionOffsetRepository) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		offsetRepo: offsetRepo,
 	}
 }
 
@@ -41,7 +43,7 @@ func (s *UserService) OnCreatedUser(ctx context.Context, req dto.UserCreated) er
 			return err
 		}
 
-		return nil
+		return saveOffsetTx(ctx, tx, s.offsetRepo)
 	})
 
 	if err != nil {
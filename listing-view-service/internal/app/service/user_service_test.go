@@ -13,7 +13,7 @@ import (
 func TestUserService_OnCreatedUser(t *testing.T) {
 	onCreatedUser := func(name string, req dto.UserCreated, mockRepo *MockUserRepository, wantErr error) func(t *testing.T) {
 		return func(t *testing.T) {
-			svc := NewUserService(mockRepo)
+			svc := NewUserService(mockRepo, &MockProjectionOffsetRepository{})
 			err := svc.OnCreatedUser(context.Background(), req)
 			if wantErr != nil {
 				assert.ErrorIs(t, err, wantErr)
@@ -55,3 +55,20 @@ func TestUserService_OnCreatedUser(t *testing.T) {
 		ErrMockDB,
 	))
 }
+
+func TestUserService_OnCreatedUser_SavesOffset(t *testing.T) {
+	offsetRepo := &MockProjectionOffsetRepository{}
+	svc := NewUserService(&MockUserRepository{}, offsetRepo)
+
+	ctx := dto.ContextWithEventMeta(context.Background(), dto.EventMeta{
+		Subject:  "user.created",
+		Sequence: 42,
+	})
+
+	err := svc.OnCreatedUser(ctx, dto.UserCreated{ID: 3, Name: "Test User"})
+	assert.NoError(t, err)
+
+	assert.Len(t, offsetRepo.offsets, 1)
+	assert.Equal(t, "user.created", offsetRepo.offsets[0].Subject)
+	assert.Equal(t, uint64(42), offsetRepo.offsets[0].Sequence)
+}
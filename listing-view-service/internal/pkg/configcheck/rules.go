@@ -0,0 +1,70 @@
+package configcheck
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate knows the rules of the config on top of the baked in ones:
+//   - dsn: a postgres:// URL or a key=value connection string, empty passes
+//   - urls: a comma separated list of absolute URLs, like NATS_URL
+//   - log_level: a level of slog
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		name, _, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+
+		return strings.ToUpper(name)
+	})
+
+	v.RegisterValidation("dsn", isDSN)            //nolint:errcheck
+	v.RegisterValidation("urls", isURLs)          //nolint:errcheck
+	v.RegisterValidation("log_level", isLogLevel) //nolint:errcheck
+
+	return v
+}
+
+func isDSN(fl validator.FieldLevel) bool {
+	dsn := strings.TrimSpace(fl.Field().String())
+	if dsn == "" {
+		return true
+	}
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+
+		return err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") && u.Host != ""
+	}
+
+	for _, pair := range strings.Fields(dsn) {
+		if key, _, ok := strings.Cut(pair, "="); !ok || key == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isURLs(fl validator.FieldLevel) bool {
+	for _, raw := range strings.Split(fl.Field().String(), ",") {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isLogLevel(fl validator.FieldLevel) bool {
+	var level slog.Level
+
+	return level.UnmarshalText([]byte(fl.Field().String())) == nil
+}
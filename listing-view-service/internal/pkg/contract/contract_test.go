@@ -0,0 +1,121 @@
+//go:build unit
+
+package contract
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.created/v2.json":    {Data: []byte(`{"id": 1, "name": "Jane", "email": "jane@example.com"}`)},
+		"user.created/v1.json":    {Data: []byte(`{"id": 1, "name": "Jane"}`)},
+		"listing.created/v2.json": {Data: []byte(`{"id": 1}`)},
+		"user.deleted/v1.json":    {Data: []byte(`{"id": `)},
+		"user.updated/v1.yaml":    {Data: []byte(`id: 1`)},
+	}
+
+	fixtures, err := Load(fsys, "user.created")
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 2)
+	assert.Equal(t, "user.created/v1", fixtures[0].String())
+	assert.Equal(t, 2, fixtures[1].Version)
+
+	latest, err := Latest(fsys, "user.created")
+	assert.NoError(t, err)
+	assert.Equal(t, fixtures[1], latest)
+
+	_, err = Load(fsys, "listing.created")
+	assert.EqualError(t, err, "fixtures of listing.created have no v1")
+
+	_, err = Load(fsys, "user.deleted")
+	assert.EqualError(t, err, "fixture user.deleted/v1.json isn't valid JSON")
+
+	_, err = Load(fsys, "user.updated")
+	assert.EqualError(t, err, "unexpected fixture user.updated/v1.yaml, want v<N>.json")
+
+	_, err = Load(fsys, "listing.deleted")
+	assert.Error(t, err)
+}
+
+func TestSameShape(t *testing.T) {
+	fixture := Fixture{
+		Subject: "user.created",
+		Version: 1,
+		Payload: []byte(`{"id": 42, "name": "Jane", "tags": ["a"], "address": {"city": "Jakarta"}}`),
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		wantErr string
+	}{
+		{
+			name:    "other values",
+			payload: `{"id": 7, "name": "John", "tags": [], "address": {"city": "Bandung"}}`,
+		},
+		{
+			name:    "missing field",
+			payload: `{"id": 7, "tags": [], "address": {"city": "Bandung"}}`,
+			wantErr: "name: missing",
+		},
+		{
+			name:    "extra nested field",
+			payload: `{"id": 7, "name": "John", "tags": [], "address": {"city": "Bandung", "zip": "40111"}}`,
+			wantErr: "address.zip: not in the fixture",
+		},
+		{
+			name:    "other types",
+			payload: `{"id": "7", "name": "John", "tags": [1], "address": null}`,
+			wantErr: "address: is null, want object\nid: is string, want number\ntags[0]: is number, want string",
+		},
+		{
+			name:    "not an object",
+			payload: `[]`,
+			wantErr: "payload: is array, want object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SameShape(fixture, []byte(tt.payload))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPreserved(t *testing.T) {
+	fixture := Fixture{
+		Subject: "user.created",
+		Version: 1,
+		Payload: []byte(`{"id": 9007199254740993, "name": "Jane", "created_at": 1720512000000000}`),
+	}
+
+	type consumer struct {
+		ID        int64  `json:"id"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		CreatedAt int32  `json:"created_at"`
+	}
+
+	// created_at overflows the field of the consumer
+	err := Preserved(fixture, consumer{ID: 9007199254740993, Name: "Jane", CreatedAt: 0})
+	assert.EqualError(t, err, "created_at: decoded as 0, sent 1720512000000000")
+
+	type idOnly struct {
+		ID int64 `json:"id"`
+	}
+
+	assert.NoError(t, Preserved(fixture, &idOnly{ID: 9007199254740993}))
+	assert.EqualError(t, Preserved(fixture, &idOnly{ID: 9007199254740992}),
+		"id: decoded as 9007199254740992, sent 9007199254740993")
+}
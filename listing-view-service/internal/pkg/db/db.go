@@ -1,25 +1,60 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/config"
-	// Register PostgreSQL driver.
-	_ "github.com/lib/pq"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/configcheck"
+	"github.com/lib/pq"
 )
 
+// maxConnectBackoff caps the wait between two connection attempts.
+const maxConnectBackoff = 5 * time.Second
+
 // InitDB initialises PG database connection.
 func InitDB(cfg config.Config) *sql.DB {
-	slog.Debug("connecting to DB...", slog.String("dsn", cfg.DB.DSN))
-
-	database, err := sql.Open("postgres", cfg.DB.DSN)
+	database, err := open(cfg)
 	if err != nil {
 		slog.Error("could not connect to DB", slog.String("error", err.Error()))
 
 		panic(err)
 	}
 
+	return database
+}
+
+// Connect opens the database of cfg and pings it until it answers, waiting
+// between the attempts with an exponential backoff from DB_CONNECT_BACKOFF,
+// for DB_CONNECT_TIMEOUT at most. The errors that retrying can't fix, like a
+// wrong password or an unknown database, fail at once.
+func Connect(ctx context.Context, cfg config.Config) (*sql.DB, error) {
+	database, err := open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DB: %w", err)
+	}
+
+	if err := waitForDB(ctx, database, cfg.DB.ConnectTimeout, cfg.DB.ConnectBackoff); err != nil {
+		database.Close()
+
+		return nil, fmt.Errorf("failed to connect to DB %s: %w", configcheck.Mask(cfg.DB.DSN), err)
+	}
+
+	return database, nil
+}
+
+func open(cfg config.Config) (*sql.DB, error) {
+	slog.Debug("connecting to DB...", slog.String("dsn", configcheck.Mask(cfg.DB.DSN)))
+
+	database, err := sql.Open("postgres", cfg.DB.DSN)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
 	// Sets the maximum number of open connections to the database.
 	database.SetMaxOpenConns(cfg.DB.MaxOpenConnections)
 	// Sets the maximum number of connections in the idle connection pool.
@@ -29,5 +64,64 @@ func InitDB(cfg config.Config) *sql.DB {
 	// Sets the maximum amount of time a connection may be idle.
 	database.SetConnMaxIdleTime(cfg.DB.MaxIdleConnectionTime)
 
-	return database
+	return database, nil
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// waitForDB pings database until it answers or timeout elapses, the wait
+// between the attempts starts at backoff and doubles up to maxConnectBackoff.
+func waitForDB(ctx context.Context, database pinger, timeout, backoff time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := database.PingContext(ctx)
+		if err == nil {
+			slog.Info("connected to DB", slog.Int("attempts", attempt),
+				slog.Duration("duration", time.Since(start)))
+
+			return nil
+		}
+
+		if permanent(err) {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("gave up after %d attempts in %s: %w", attempt, timeout, err)
+		}
+
+		slog.Warn("cannot connect to DB, retrying", slog.Int("attempt", attempt),
+			slog.Duration("retry_in", backoff), slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up after %d attempts in %s: %w", attempt, timeout, err)
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// permanent reports whether err is rejected by Postgres for a reason retrying
+// can't fix: the credentials or the database name.
+func permanent(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code.Class() {
+	case "28", // invalid authorization specification
+		"3D": // invalid catalog name
+		return true
+	default:
+		return false
+	}
 }
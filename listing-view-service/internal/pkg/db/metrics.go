@@ -0,0 +1,32 @@
+package db
+
+import (
+	"database/sql"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/metrics"
+)
+
+// RegisterMetrics exports the connection pool statistics of database, they
+// are read from sql.DB.Stats on every scrape.
+func RegisterMetrics(registry *metrics.Registry, database *sql.DB) {
+	registry.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.",
+		func() float64 { return float64(database.Stats().MaxOpenConnections) })
+	registry.NewGaugeFunc("db_open_connections", "Number of established connections, in use and idle.",
+		func() float64 { return float64(database.Stats().OpenConnections) })
+	registry.NewGaugeFunc("db_in_use_connections", "Number of connections currently in use.",
+		func() float64 { return float64(database.Stats().InUse) })
+	registry.NewGaugeFunc("db_idle_connections", "Number of idle connections.",
+		func() float64 { return float64(database.Stats().Idle) })
+	registry.NewCounterFunc("db_wait_count_total", "Total number of connections waited for.",
+		func() float64 { return float64(database.Stats().WaitCount) })
+	registry.NewCounterFunc("db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.",
+		func() float64 { return database.Stats().WaitDuration.Seconds() })
+	registry.NewCounterFunc("db_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.",
+		func() float64 { return float64(database.Stats().MaxIdleClosed) })
+	registry.NewCounterFunc("db_max_idle_time_closed_total",
+		"Total number of connections closed due to SetConnMaxIdleTime.",
+		func() float64 { return float64(database.Stats().MaxIdleTimeClosed) })
+	registry.NewCounterFunc("db_max_lifetime_closed_total",
+		"Total number of connections closed due to SetConnMaxLifetime.",
+		func() float64 { return float64(database.Stats().MaxLifetimeClosed) })
+}
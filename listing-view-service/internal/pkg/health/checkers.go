@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Pinger is implemented by *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// DBChecker pings the database.
+func DBChecker(db Pinger) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping database: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// NATSChecker fails while the connection is not connected, including while
+// it is reconnecting.
+func NATSChecker(conn *nats.Conn) Checker {
+	return CheckerFunc(func(_ context.Context) error {
+		if status := conn.Status(); status != nats.CONNECTED {
+			return fmt.Errorf("NATS connection is %s", status)
+		}
+
+		return nil
+	})
+}
+
+// JetStreamChecker requests the account info, which fails when JetStream is
+// not enabled or not reachable.
+func JetStreamChecker(js jetstream.JetStream) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if _, err := js.AccountInfo(ctx); err != nil {
+			return fmt.Errorf("get JetStream account info: %w", err)
+		}
+
+		return nil
+	})
+}
+
+var errUnhealthyResponse = errors.New("unhealthy response")
+
+// HTTPChecker fails when url does not respond with a 2xx status code.
+func HTTPChecker(client *http.Client, url string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		io.Copy(io.Discard, resp.Body) //nolint:errcheck
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("%w: status code %d", errUnhealthyResponse, resp.StatusCode)
+		}
+
+		return nil
+	})
+}
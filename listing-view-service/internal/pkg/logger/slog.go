@@ -1,14 +1,69 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
 
+type contextKey string
+
+// attrsContextKey is the context.Context key to store the log attributes.
+var attrsContextKey = contextKey("log_attrs")
+
+// InitStructuredLogger installs the default logger, its level is controlled
+// by DefaultLevel so it can change at runtime.
 func InitStructuredLogger(level slog.Leveler) {
+	DefaultLevel.SetConfigured(level.Level())
+
 	jsonHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
+		Level: DefaultLevel,
 	})
 
-	slog.SetDefault(slog.New(jsonHandler))
+	slog.SetDefault(slog.New(NewContextHandler(jsonHandler)))
+}
+
+// ContextWithAttrs returns a copy of ctx carrying attrs, they are added to
+// every record logged with that context.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := AttrsFromContext(ctx)
+
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attrsContextKey, merged)
+}
+
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsContextKey).([]slog.Attr)
+
+	return attrs
+}
+
+// ContextHandler decorates a slog.Handler with the attributes stored in the
+// record context.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := AttrsFromContext(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+
+	return h.Handler.Handle(ctx, record) //nolint:wrapcheck
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
 }
@@ -0,0 +1,165 @@
+//go:build unit
+
+package memdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type row struct {
+	ID   int64
+	Name string
+}
+
+func byID(a, b row) bool { return a.ID < b.ID }
+
+func TestDB_WithTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commit", func(t *testing.T) {
+		db := New()
+		table := NewTable[int64, row](db)
+
+		err := db.WithTransaction(ctx, func(ctx context.Context) error {
+			table.Put(ctx, 1, row{ID: 1, Name: "a"})
+
+			got, ok := table.Get(ctx, 1)
+			assert.True(t, ok)
+			assert.Equal(t, "a", got.Name)
+
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []row{{ID: 1, Name: "a"}}, table.Select(ctx, nil, byID))
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		db := New()
+		table := NewTable[int64, row](db)
+		table.Put(ctx, 1, row{ID: 1, Name: "a"})
+
+		err := db.WithTransaction(ctx, func(ctx context.Context) error {
+			table.Put(ctx, 1, row{ID: 1, Name: "b"})
+			table.Put(ctx, 2, row{ID: 2, Name: "c"})
+
+			return errors.New("failed")
+		})
+
+		assert.EqualError(t, err, "failed")
+		assert.Equal(t, []row{{ID: 1, Name: "a"}}, table.Select(ctx, nil, byID))
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		db := New()
+		table := NewTable[int64, row](db)
+
+		assert.Panics(t, func() {
+			db.WithTransaction(ctx, func(ctx context.Context) error { //nolint:errcheck
+				table.Put(ctx, 1, row{ID: 1})
+
+				panic("boom")
+			})
+		})
+
+		_, ok := table.Get(ctx, 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("nested transaction joins the outer one", func(t *testing.T) {
+		db := New()
+		table := NewTable[int64, row](db)
+
+		err := db.WithTransaction(ctx, func(ctx context.Context) error {
+			assert.NoError(t, db.WithTransaction(ctx, func(ctx context.Context) error {
+				table.Put(ctx, 1, row{ID: 1})
+
+				return nil
+			}))
+
+			return errors.New("failed")
+		})
+
+		assert.Error(t, err)
+		assert.Empty(t, table.Select(ctx, nil, byID))
+	})
+}
+
+func TestDB_TransactionsAreSerialized(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	counter := NewTable[string, int](db)
+
+	var wg sync.WaitGroup
+
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			db.WithTransaction(ctx, func(ctx context.Context) error { //nolint:errcheck
+				count, _ := counter.Get(ctx, "count")
+				counter.Put(ctx, "count", count+1)
+
+				return nil
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	count, _ := counter.Get(ctx, "count")
+	assert.Equal(t, 50, count)
+}
+
+func TestDB_ReadsDontWaitForTransactions(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	table := NewTable[int64, row](db)
+	table.Put(ctx, 1, row{ID: 1, Name: "a"})
+
+	inTx := make(chan struct{})
+	commit := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- db.WithTransaction(ctx, func(ctx context.Context) error {
+			table.Put(ctx, 1, row{ID: 1, Name: "b"})
+			table.Put(ctx, 2, row{ID: 2, Name: "c"})
+
+			assert.Equal(t, []row{{ID: 1, Name: "b"}, {ID: 2, Name: "c"}}, table.Select(ctx, nil, byID))
+
+			// like a transaction waiting for the ack of an event
+			close(inTx)
+			<-commit
+
+			return nil
+		})
+	}()
+
+	<-inTx
+
+	// the open transaction neither blocks the readers nor shows them its rows
+	assert.Equal(t, []row{{ID: 1, Name: "a"}}, table.Select(ctx, nil, byID))
+
+	_, ok := table.Get(ctx, 2)
+	assert.False(t, ok)
+
+	close(commit)
+	assert.NoError(t, <-done)
+	assert.Equal(t, []row{{ID: 1, Name: "b"}, {ID: 2, Name: "c"}}, table.Select(ctx, nil, byID))
+}
+
+func TestPage(t *testing.T) {
+	rows := []int{1, 2, 3, 4, 5}
+
+	assert.Equal(t, []int{1, 2}, Page(rows, 2, 0))
+	assert.Equal(t, []int{5}, Page(rows, 2, 4))
+	assert.Equal(t, []int{}, Page(rows, 2, 5))
+}
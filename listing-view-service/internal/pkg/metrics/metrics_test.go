@@ -0,0 +1,61 @@
+//go:build unit
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.NewCounterVec("requests_total", "Requests.", "route", "status")
+	requests.WithLabelValues("/users", "200").Inc()
+	requests.WithLabelValues("/users", "200").Add(2)
+	requests.WithLabelValues(`/a"b`, "500").Inc()
+
+	duration := registry.NewHistogramVec("duration_seconds", "Duration.", []float64{0.1, 1}, "route")
+	duration.WithLabelValues("/users").Observe(0.05)
+	duration.WithLabelValues("/users").Observe(0.5)
+	duration.WithLabelValues("/users").Observe(3)
+
+	registry.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return 4 })
+
+	var buf bytes.Buffer
+	assert.NoError(t, registry.Write(&buf))
+	assert.Equal(t, `# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/users",le="0.1"} 1
+duration_seconds_bucket{route="/users",le="1"} 2
+duration_seconds_bucket{route="/users",le="+Inf"} 3
+duration_seconds_sum{route="/users"} 3.55
+duration_seconds_count{route="/users"} 3
+# HELP open_connections Open connections.
+# TYPE open_connections gauge
+open_connections 4
+# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="/a\"b",status="500"} 1
+requests_total{route="/users",status="200"} 3
+`, buf.String())
+}
+
+func TestRegistry_RegisterTwice(t *testing.T) {
+	registry := NewRegistry()
+
+	first := registry.NewCounterVec("requests_total", "Requests.", "route")
+	second := registry.NewCounterVec("requests_total", "Requests.", "route")
+
+	first.WithLabelValues("/").Inc()
+	assert.Equal(t, float64(1), testutil.ToFloat64(second.WithLabelValues("/")))
+}
+
+func TestDefault_RuntimeMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Default.Write(&buf))
+	assert.Contains(t, buf.String(), "# TYPE go_goroutines gauge")
+}
@@ -0,0 +1,420 @@
+// Package migrate applies the SQL migrations embedded in the service binary.
+//
+// The files are named NNNNNN_name.up.sql and NNNNNN_name.down.sql, the layout
+// of golang-migrate, and the version is tracked in its schema_migrations
+// table, so a database migrated with the migrate CLI keeps working.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultTable = "schema_migrations"
+
+// ErrDirty is returned when a migration failed half way outside a
+// transaction, the schema has to be fixed by hand before migrating again.
+var ErrDirty = errors.New("database is dirty")
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+	hasDown bool
+}
+
+// Load reads the migrations at the root of fsys sorted by version, a
+// migration may have no down file but must have an up one.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	hasUp := make(map[uint64]bool)
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version %q", match[1])
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration: %w", err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(data)
+			hasUp[version] = true
+		} else {
+			migration.Down = string(data)
+			migration.hasDown = true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if !hasUp[migration.Version] {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator moves the schema of a Postgres database between the versions of
+// its migrations. The replicas migrating at the same time are serialized by
+// an advisory lock.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	table      string
+	lockKey    int64
+}
+
+type Option func(*Migrator)
+
+// WithTable tracks the version in table instead of schema_migrations.
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+func New(db *sql.DB, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{
+		db:         db,
+		migrations: migrations,
+		table:      defaultTable,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte("migrate:" + m.table))
+	m.lockKey = int64(hash.Sum64()) //nolint:gosec // any key will do as long as it is stable
+
+	return m, nil
+}
+
+// Latest is the version of the last migration, 0 without migration.
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies the pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.migrate(ctx, func(uint64) (uint64, error) {
+		return m.Latest(), nil
+	})
+}
+
+// Down reverts the last n applied migrations.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.migrate(ctx, func(current uint64) (uint64, error) {
+		index := m.index(current)
+
+		return m.versionAt(index - n), nil
+	})
+}
+
+// Goto migrates up or down to version, 0 reverts every migration.
+func (m *Migrator) Goto(ctx context.Context, version uint64) error {
+	return m.migrate(ctx, func(uint64) (uint64, error) {
+		if version != 0 && m.index(version) < 0 {
+			return 0, fmt.Errorf("unknown migration version %d", version)
+		}
+
+		return version, nil
+	})
+}
+
+type MigrationStatus struct {
+	Version uint64
+	Name    string
+	Applied bool
+}
+
+type Status struct {
+	Version    uint64
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+// Pending counts the migrations not applied yet.
+func (s Status) Pending() int {
+	pending := 0
+
+	for _, migration := range s.Migrations {
+		if !migration.Applied {
+			pending++
+		}
+	}
+
+	return pending
+}
+
+// Status reports the version of the database and the migrations applied, it
+// doesn't take the lock nor create the version table.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var exists bool
+
+	err := m.db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", m.table).Scan(&exists)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to look up %s: %w", m.table, err)
+	}
+
+	var status Status
+
+	if exists {
+		status.Version, status.Dirty, err = m.version(ctx, m.db)
+		if err != nil {
+			return Status{}, err
+		}
+	}
+
+	for _, migration := range m.migrations {
+		status.Migrations = append(status.Migrations, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= status.Version,
+		})
+	}
+
+	return status, nil
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (m *Migrator) version(ctx context.Context, q querier) (uint64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := q.QueryRowContext(ctx, "SELECT version, dirty FROM "+m.table+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return uint64(version), dirty, nil //nolint:gosec // versions are positive
+}
+
+// migrate holds the advisory lock on a dedicated connection, the lock belongs
+// to the session, and applies the steps to the version returned by target.
+func (m *Migrator) migrate(ctx context.Context, target func(current uint64) (uint64, error)) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	defer func() {
+		// the session is closed anyway if unlocking fails
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)",
+			m.lockKey); err != nil {
+			slog.ErrorContext(ctx, "failed to release migration lock", slog.String("error", err.Error()))
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+m.table+
+		" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", m.table, err)
+	}
+
+	// read under the lock, another replica may just have migrated
+	current, dirty, err := m.version(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("%w at version %d, fix the schema and clear the dirty flag of %s",
+			ErrDirty, current, m.table)
+	}
+
+	version, err := target(current)
+	if err != nil {
+		return err
+	}
+
+	steps, err := m.plan(current, version)
+	if err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		slog.InfoContext(ctx, "schema is up to date", slog.Uint64("version", current))
+
+		return nil
+	}
+
+	for _, step := range steps {
+		if err := m.apply(ctx, conn, step); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type step struct {
+	migration Migration
+	up        bool
+	// version is the schema version once the step is applied.
+	version uint64
+}
+
+// plan lists the migrations to apply, in order, to move from current to
+// target.
+func (m *Migrator) plan(current, target uint64) ([]step, error) {
+	from := m.index(current)
+	if current != 0 && from < 0 {
+		return nil, fmt.Errorf("database is at version %d, which has no migration", current)
+	}
+
+	to := m.index(target)
+
+	var steps []step
+
+	for i := from + 1; i <= to; i++ {
+		steps = append(steps, step{migration: m.migrations[i], up: true, version: m.migrations[i].Version})
+	}
+
+	for i := from; i > to; i-- {
+		if !m.migrations[i].hasDown {
+			return nil, fmt.Errorf("migration %d_%s can't be reverted, it has no down file",
+				m.migrations[i].Version, m.migrations[i].Name)
+		}
+
+		steps = append(steps, step{migration: m.migrations[i], version: m.versionAt(i - 1)})
+	}
+
+	return steps, nil
+}
+
+// index returns the position of version in the migrations, -1 for version 0
+// or an unknown one.
+func (m *Migrator) index(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// versionAt returns the version of the migration at index, 0 before the first
+// one, and the latest past the last one.
+func (m *Migrator) versionAt(index int) uint64 {
+	if index < 0 {
+		return 0
+	}
+
+	return m.migrations[min(index, len(m.migrations)-1)].Version
+}
+
+// apply runs a step and records the version in one transaction, so a failed
+// migration leaves the schema as it was.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, step step) (err error) {
+	direction, script := "up", step.migration.Up
+	if !step.up {
+		direction, script = "down", step.migration.Down
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback() //nolint:errcheck
+		}
+	}()
+
+	if strings.TrimSpace(script) != "" {
+		if _, err = tx.ExecContext(ctx, script); err != nil {
+			return fmt.Errorf("failed to migrate %s %d_%s: %w", direction, step.migration.Version, step.migration.Name, err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM "+m.table); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+
+	if step.version != 0 {
+		_, err = tx.ExecContext(ctx, "INSERT INTO "+m.table+" (version, dirty) VALUES ($1, false)", step.version)
+		if err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	slog.InfoContext(ctx, "migrated",
+		slog.String("direction", direction),
+		slog.Uint64("migration", step.migration.Version),
+		slog.String("name", step.migration.Name),
+		slog.Uint64("version", step.version),
+	)
+
+	return nil
+}
@@ -45,7 +45,7 @@ func ErrorResponse(ctx context.Context, err error, respWriter http.ResponseWrite
 	)
 
 	respWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
-	slog.Info("error", "error", err)
+	slog.InfoContext(ctx, "error", "error", err)
 
 	if errors.As(err, &appErr) {
 		respWriter.WriteHeader(appErr.StatusCode)
@@ -54,7 +54,7 @@ func ErrorResponse(ctx context.Context, err error, respWriter http.ResponseWrite
 		// in case of failure to get request context, default language will be used
 		message = appErr.Localize(reqContext.Language)
 
-		slog.Default().Debug("error", "cause", err.Error())
+		slog.Default().DebugContext(ctx, "error", "cause", err.Error())
 	} else {
 		respWriter.WriteHeader(http.StatusInternalServerError)
 
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultBodyLimit = 64 << 10
+	redactedValue    = "[REDACTED]"
+	truncatedMarker  = "...[truncated]"
+)
+
+type LoggingOption func(*bodyLogger)
+
+// WithBodyLimit caps how much of the request and response bodies are
+// logged, the rest is replaced by a marker.
+func WithBodyLimit(limit int) LoggingOption {
+	return func(l *bodyLogger) {
+		if limit > 0 {
+			l.limit = limit
+		}
+	}
+}
+
+// WithRedactedFields replaces the values of the fields at paths before
+// logging. A path is a dot separated list of JSON keys, * matching any key or
+// array element, like users.*.name. The first key of a path also matches the
+// fields of form encoded bodies.
+func WithRedactedFields(paths ...string) LoggingOption {
+	return func(l *bodyLogger) {
+		for _, path := range paths {
+			if path = strings.TrimSpace(path); path != "" {
+				l.redact = append(l.redact, strings.Split(path, "."))
+			}
+		}
+	}
+}
+
+// WithContentTypes only logs the bodies of these media types, the others are
+// replaced by a marker. A body without content type is always logged.
+func WithContentTypes(mediaTypes ...string) LoggingOption {
+	return func(l *bodyLogger) {
+		for _, mediaType := range mediaTypes {
+			if mediaType = strings.TrimSpace(mediaType); mediaType != "" {
+				l.contentTypes = append(l.contentTypes, strings.ToLower(mediaType))
+			}
+		}
+	}
+}
+
+// WithSuccessSampleRate logs only this fraction of the successful requests,
+// error responses are always logged.
+func WithSuccessSampleRate(rate float64) LoggingOption {
+	return func(l *bodyLogger) {
+		l.sampleRate = rate
+	}
+}
+
+// bodyLogger formats the bodies of LoggingMiddleware.
+type bodyLogger struct {
+	limit        int
+	redact       [][]string
+	contentTypes []string
+	sampleRate   float64
+}
+
+func newBodyLogger(opts []LoggingOption) *bodyLogger {
+	l := &bodyLogger{
+		limit:      defaultBodyLimit,
+		sampleRate: 1,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+func (l *bodyLogger) sampled() bool {
+	return l.sampleRate >= 1 || rand.Float64() < l.sampleRate //nolint:gosec
+}
+
+// readRequestBody reads the loggable part of the request body, the handler
+// still reads the whole body.
+func (l *bodyLogger) readRequestBody(req *http.Request) ([]byte, bool) {
+	if req.Body == nil {
+		return nil, false
+	}
+
+	head, _ := io.ReadAll(io.LimitReader(req.Body, int64(l.limit)+1))
+
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+
+	if len(head) > l.limit {
+		return head[:l.limit], true
+	}
+
+	return head, false
+}
+
+// format returns the body as logged, size is the length of the whole body.
+func (l *bodyLogger) format(body []byte, truncated bool, size int, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	mediaType := mediaTypeOf(contentType)
+
+	if !l.allowed(mediaType) {
+		return fmt.Sprintf("[%s body omitted, %d bytes]", mediaType, size)
+	}
+
+	if truncated {
+		// a partial body can't be parsed to find the fields to redact
+		if len(l.redact) > 0 {
+			return fmt.Sprintf("[body omitted, %d bytes over the log limit]", size)
+		}
+
+		return string(body) + truncatedMarker
+	}
+
+	if len(l.redact) > 0 {
+		return string(l.redactBody(body, mediaType))
+	}
+
+	return string(compactJSON(body))
+}
+
+func (l *bodyLogger) allowed(mediaType string) bool {
+	if len(l.contentTypes) == 0 || mediaType == "" {
+		return true
+	}
+
+	for _, allowed := range l.contentTypes {
+		if allowed == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *bodyLogger) redactBody(body []byte, mediaType string) []byte {
+	if mediaType == "application/x-www-form-urlencoded" {
+		return l.redactForm(body)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		// not JSON, nothing to redact by path
+		return compactJSON(body)
+	}
+
+	for _, path := range l.redact {
+		value = redactPath(value, path)
+	}
+
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return compactJSON(body)
+	}
+
+	return redacted
+}
+
+func (l *bodyLogger) redactForm(body []byte) []byte {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+
+	for _, path := range l.redact {
+		for key := range values {
+			if path[0] == "*" || path[0] == key {
+				values[key] = []string{redactedValue}
+			}
+		}
+	}
+
+	return []byte(values.Encode())
+}
+
+func redactPath(value any, path []string) any {
+	if len(path) == 0 {
+		return redactedValue
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if path[0] == "*" || path[0] == key {
+				v[key] = redactPath(child, path[1:])
+			}
+		}
+	case []any:
+		if path[0] == "*" {
+			for i, child := range v {
+				v[i] = redactPath(child, path[1:])
+			}
+		}
+	}
+
+	return value
+}
+
+func mediaTypeOf(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	return mediaType
+}
@@ -2,16 +2,26 @@ package http
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
-	"io"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/logger"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/metrics"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/timing"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/tracing"
 )
 
 type MiddlewareFunc func(http.Handler) http.Handler
@@ -19,12 +29,21 @@ type MiddlewareFunc func(http.Handler) http.Handler
 type loggingResponseWriter struct {
 	http.ResponseWriter // original response writer
 	body                []byte
+	limit               int
+	size                int
 	statusCode          int
 }
 
 func (r *loggingResponseWriter) Write(b []byte) (int, error) {
 	size, err := r.ResponseWriter.Write(b) // write response using original http.ResponseWriter
-	r.body = b                             // capture response body
+
+	// capture the response body up to the limit, handlers may write it in
+	// several chunks
+	if room := r.limit - len(r.body); room > 0 {
+		r.body = append(r.body, b[:min(room, size)]...)
+	}
+
+	r.size += size
 
 	return size, err //nolint:wrapcheck
 }
@@ -34,33 +53,45 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.statusCode = statusCode                // capture status code
 }
 
-func LoggingMiddleware(logger *slog.Logger) MiddlewareFunc {
+func (r *loggingResponseWriter) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func LoggingMiddleware(logger *slog.Logger, opts ...LoggingOption) MiddlewareFunc {
+	bodyLogger := newBodyLogger(opts)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
 			var (
 				start             = time.Now()
-				loggingRespWriter = loggingResponseWriter{ResponseWriter: respWriter}
-				reqBytes          []byte
+				loggingRespWriter = loggingResponseWriter{ResponseWriter: respWriter, limit: bodyLogger.limit}
 			)
 
-			if req.Body != nil {
-				defer req.Body.Close()
-				reqBytes, _ = io.ReadAll(req.Body)
-				req.Body = io.NopCloser(bytes.NewReader(reqBytes))
-			}
+			reqBytes, reqTruncated := bodyLogger.readRequestBody(req)
 
 			next.ServeHTTP(&loggingRespWriter, req)
 
-			var (
-				respBytes  = loggingRespWriter.body
-				statusCode = loggingRespWriter.statusCode
-			)
-
+			statusCode := loggingRespWriter.statusCode
 			if statusCode == 0 {
 				// use default status code
 				statusCode = http.StatusOK
 			}
 
+			if statusCode < http.StatusBadRequest && !bodyLogger.sampled() {
+				return
+			}
+
+			reqSize := len(reqBytes)
+			if reqTruncated && req.ContentLength > 0 {
+				reqSize = int(req.ContentLength)
+			}
+
 			attrs := []any{
 				slog.String("type", "inbound"),
 				slog.String("transport", "http"),
@@ -68,8 +99,11 @@ func LoggingMiddleware(logger *slog.Logger) MiddlewareFunc {
 				slog.String("url", req.URL.String()),
 				slog.String("method", req.Method),
 				slog.Int("status_code", statusCode),
-				slog.String("request", string(compactJSON(reqBytes))),
-				slog.String("response", string(compactJSON(respBytes))),
+				slog.String("request", bodyLogger.format(reqBytes, reqTruncated, reqSize,
+					req.Header.Get("Content-Type"))),
+				slog.String("response", bodyLogger.format(loggingRespWriter.body,
+					loggingRespWriter.size > len(loggingRespWriter.body), loggingRespWriter.size,
+					loggingRespWriter.Header().Get("Content-Type"))),
 			}
 
 			if statusCode >= http.StatusBadRequest {
@@ -81,6 +115,118 @@ func LoggingMiddleware(logger *slog.Logger) MiddlewareFunc {
 	}
 }
 
+// MetricsMiddleware counts requests and observes their latency by method,
+// route pattern and status code.
+func MetricsMiddleware(registry *metrics.Registry) MiddlewareFunc {
+	requests := registry.NewCounterVec("http_requests_total",
+		"Total number of HTTP requests.", "method", "route", "status")
+	duration := registry.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency in seconds.", metrics.DefBuckets, "method", "route", "status")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			statusRespWriter := &statusResponseWriter{ResponseWriter: respWriter, statusCode: http.StatusOK}
+
+			next.ServeHTTP(statusRespWriter, req)
+
+			// use the pattern, not the path, to keep the label cardinality low
+			route := routePattern(req)
+			status := strconv.Itoa(statusRespWriter.statusCode)
+
+			requests.WithLabelValues(req.Method, route, status).Inc()
+			duration.WithLabelValues(req.Method, route, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// TracingMiddleware starts a server span per request, continuing the trace of
+// the caller when it sent a traceparent header.
+func TracingMiddleware() MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			ctx := tracing.Extract(req.Context(), req.Header)
+
+			ctx, span := tracing.Start(ctx, req.Method, tracing.SpanKindServer)
+			defer span.End()
+
+			if sc := span.SpanContext(); sc.IsValid() {
+				ctx = logger.ContextWithAttrs(ctx, slog.String("trace_id", sc.TraceID().String()))
+			}
+
+			statusRespWriter := &statusResponseWriter{ResponseWriter: respWriter, statusCode: http.StatusOK}
+
+			next.ServeHTTP(statusRespWriter, req.WithContext(ctx))
+
+			route := routePattern(req)
+
+			span.SetName(req.Method + " " + route)
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.route", route)
+			span.SetAttribute("http.target", req.URL.Path)
+			span.SetAttribute("http.status_code", statusRespWriter.statusCode)
+
+			if statusRespWriter.statusCode >= http.StatusInternalServerError {
+				span.RecordError(fmt.Errorf("status code: %d", statusRespWriter.statusCode))
+			}
+		})
+	}
+}
+
+// SlowRequestMiddleware logs requests slower than threshold at WARN with the
+// time they spent in the database and in downstream services, a zero
+// threshold disables it.
+func SlowRequestMiddleware(logger *slog.Logger, threshold time.Duration) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if threshold <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			ctx, breakdown := timing.ContextWithBreakdown(req.Context())
+			statusRespWriter := &statusResponseWriter{ResponseWriter: respWriter, statusCode: http.StatusOK}
+
+			next.ServeHTTP(statusRespWriter, req.WithContext(ctx))
+
+			duration := time.Since(start)
+			if duration <= threshold {
+				return
+			}
+
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.String("route", routePattern(req)),
+				slog.Int("status_code", statusRespWriter.statusCode),
+				slog.Duration("duration_ns", duration),
+				slog.Duration("threshold_ns", threshold),
+			}
+
+			logger.WarnContext(ctx, "slow request", append(attrs, breakdown.Attrs()...)...)
+		})
+	}
+}
+
+// routePattern returns the chi pattern that matched req, it is only known
+// once the request went through the router.
+func routePattern(req *http.Request) string {
+	if routeCtx := chi.RouteContext(req.Context()); routeCtx != nil && routeCtx.RoutePattern() != "" {
+		return routeCtx.RoutePattern()
+	}
+
+	return "unmatched"
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusResponseWriter) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
 func compactJSON(jsonBytes []byte) []byte {
 	if len(jsonBytes) == 0 {
 		return jsonBytes
@@ -117,15 +263,122 @@ func Recoverer(logger *slog.Logger) MiddlewareFunc {
 	}
 }
 
-// CORSMiddleware set CORS related headers.
-func CORSMiddleware(allowedOrigins []string) func(next http.Handler) http.Handler {
-	return cors.Handler(cors.Options{
+// CORS sets CORS related headers, the allowed origins can change while
+// serving.
+type CORS struct {
+	cors atomic.Pointer[cors.Cors]
+}
+
+func NewCORS(allowedOrigins []string) *CORS {
+	c := &CORS{}
+	c.SetAllowedOrigins(allowedOrigins)
+
+	return c
+}
+
+func (c *CORS) SetAllowedOrigins(allowedOrigins []string) {
+	c.cors.Store(cors.New(cors.Options{
 		AllowedOrigins: allowedOrigins, // allow swagger
 		AllowedMethods: []string{"GET", "POST", "PATCH", "PUT", "OPTIONS", "DELETE"},
 		AllowedHeaders: []string{"Authorization", "Origin", "Content-Type", "X-Timestamp", "X-Transaction-Id"},
+		ExposedHeaders: []string{"X-Transaction-Id"},
+	}))
+}
+
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		c.cors.Load().Handler(next).ServeHTTP(respWriter, req)
+	})
+}
+
+// ServiceTokenMiddleware only lets through the requests with a bearer token
+// listed in serviceTokens, a comma separated list. Every request is rejected
+// when the list is empty.
+func ServiceTokenMiddleware(serviceTokens string) func(next http.Handler) http.Handler {
+	return NewServiceTokens(serviceTokens).Handler
+}
+
+// defaultServiceTokenName names the tokens listed without a name.
+const defaultServiceTokenName = "service"
+
+// ServiceTokens is the ServiceTokenMiddleware whose tokens can change while
+// serving, for instance when a secret file is rotated.
+type ServiceTokens struct {
+	tokens atomic.Pointer[[]serviceToken]
+}
+
+// serviceToken is a token of the list and the name of the caller it
+// authenticates.
+type serviceToken struct {
+	name  string
+	token []byte
+}
+
+func NewServiceTokens(serviceTokens string) *ServiceTokens {
+	s := &ServiceTokens{}
+	s.SetTokens(serviceTokens)
+
+	return s
+}
+
+// SetTokens replaces the accepted tokens, a comma separated list of tokens
+// optionally prefixed with the name of their caller, like name:token.
+func (s *ServiceTokens) SetTokens(serviceTokens string) {
+	var tokens []serviceToken
+
+	for _, entry := range strings.Split(serviceTokens, ",") {
+		name, token, named := strings.Cut(strings.TrimSpace(entry), ":")
+		if !named {
+			name, token = defaultServiceTokenName, name
+		}
+
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, serviceToken{name: strings.TrimSpace(name), token: []byte(token)})
+		}
+	}
+
+	s.tokens.Store(&tokens)
+}
+
+func (s *ServiceTokens) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		if _, ok := s.authenticate(req); !ok {
+			ErrorResponse(req.Context(), exception.ErrUnauthorized, respWriter)
+
+			return
+		}
+
+		next.ServeHTTP(respWriter, req)
 	})
 }
 
+// authenticate returns the name of the bearer token of req, false when it
+// isn't listed.
+func (s *ServiceTokens) authenticate(req *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+
+	return validToken(*s.tokens.Load(), []byte(token))
+}
+
+func validToken(tokens []serviceToken, token []byte) (string, bool) {
+	var (
+		name  string
+		valid bool
+	)
+
+	// compare with every token so the time doesn't tell which one is close
+	for _, candidate := range tokens {
+		if subtle.ConstantTimeCompare(candidate.token, token) == 1 {
+			name, valid = candidate.name, true
+		}
+	}
+
+	return name, valid
+}
+
 func HeaderMiddleware() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
@@ -136,6 +389,9 @@ func HeaderMiddleware() func(next http.Handler) http.Handler {
 				return
 			}
 
+			reqContext, _ := dto.RequestFromContext(newReq.Context())
+			respWriter.Header().Set(dto.RequestIDHeader, reqContext.RequestID)
+
 			next.ServeHTTP(respWriter, newReq)
 		})
 	}
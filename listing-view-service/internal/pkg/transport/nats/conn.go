@@ -0,0 +1,94 @@
+package nats
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ConnOptions configures the connections made by Connect.
+type ConnOptions struct {
+	URL string
+	// Name identifies the connection in the server monitoring.
+	Name string
+	// MaxReconnects is the number of reconnection attempts after the
+	// connection is lost, -1 retries forever.
+	MaxReconnects int
+	ReconnectWait time.Duration
+	// ReconnectBufSize is the number of bytes published while reconnecting
+	// kept until the connection is back, -1 fails those publications at
+	// once, zero keeps the nats.go default.
+	ReconnectBufSize int
+	// Timeout is how long dialing a server may take, zero keeps the nats.go
+	// default.
+	Timeout time.Duration
+}
+
+// Connect connects to NATS with opts, the disconnections, the reconnections,
+// the close of the connection and the asynchronous errors are logged.
+func Connect(opts ConnOptions) (*nats.Conn, error) {
+	options := []nats.Option{
+		nats.MaxReconnects(opts.MaxReconnects),
+		nats.ReconnectWait(opts.ReconnectWait),
+		nats.DisconnectErrHandler(logDisconnect),
+		nats.ReconnectHandler(logReconnect),
+		nats.ClosedHandler(logClosed),
+		nats.ErrorHandler(logAsyncError),
+	}
+
+	if opts.Name != "" {
+		options = append(options, nats.Name(opts.Name))
+	}
+
+	if opts.ReconnectBufSize != 0 {
+		options = append(options, nats.ReconnectBufSize(opts.ReconnectBufSize))
+	}
+
+	if opts.Timeout > 0 {
+		options = append(options, nats.Timeout(opts.Timeout))
+	}
+
+	conn, err := nats.Connect(opts.URL, options...)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	slog.Info("connected to NATS", slog.String("url", conn.ConnectedUrlRedacted()))
+
+	return conn, nil
+}
+
+func logDisconnect(_ *nats.Conn, err error) {
+	if err == nil {
+		slog.Info("disconnected from NATS")
+
+		return
+	}
+
+	slog.Warn("disconnected from NATS", slog.String("error", err.Error()))
+}
+
+func logReconnect(conn *nats.Conn) {
+	slog.Info("reconnected to NATS", slog.String("url", conn.ConnectedUrlRedacted()),
+		slog.Uint64("reconnects", conn.Stats().Reconnects))
+}
+
+func logClosed(conn *nats.Conn) {
+	if err := conn.LastError(); err != nil {
+		slog.Error("NATS connection closed", slog.String("error", err.Error()))
+
+		return
+	}
+
+	slog.Info("NATS connection closed")
+}
+
+func logAsyncError(_ *nats.Conn, sub *nats.Subscription, err error) {
+	attrs := []any{slog.String("error", err.Error())}
+	if sub != nil {
+		attrs = append(attrs, slog.String("subject", sub.Subject))
+	}
+
+	slog.Error("NATS async error", attrs...)
+}
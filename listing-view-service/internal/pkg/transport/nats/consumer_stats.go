@@ -0,0 +1,77 @@
+package nats
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// errors older than errorHistory are forgotten, status windows can't be
+	// longer.
+	errorHistory = time.Hour
+	maxErrors    = 1000
+)
+
+// consumerStats is what a consumer remembers of the messages it handled.
+type consumerStats struct {
+	mu              sync.Mutex
+	lastSequence    uint64
+	lastProcessedAt time.Time
+	redeliveries    uint64
+	errors          []time.Time
+	lastError       string
+}
+
+// processed records a message handled successfully, numDelivered is the
+// delivery attempt of the message.
+func (s *consumerStats) processed(sequence, numDelivered uint64, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSequence = sequence
+	s.lastProcessedAt = at
+
+	if numDelivered > 1 {
+		s.redeliveries++
+	}
+}
+
+func (s *consumerStats) failed(err error, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errors = append(s.errors, at)
+	s.lastError = err.Error()
+	s.prune(at)
+}
+
+// recentErrors counts the errors of the last window.
+func (s *consumerStats) recentErrors(window time.Duration, now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	count := 0
+
+	for _, at := range s.errors {
+		if now.Sub(at) <= window {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (s *consumerStats) prune(now time.Time) {
+	keep := 0
+	for keep < len(s.errors) && now.Sub(s.errors[keep]) > errorHistory {
+		keep++
+	}
+
+	if overflow := len(s.errors) - keep - maxErrors; overflow > 0 {
+		keep += overflow
+	}
+
+	s.errors = s.errors[keep:]
+}
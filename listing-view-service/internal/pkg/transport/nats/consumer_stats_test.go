@@ -0,0 +1,31 @@
+//go:build unit
+
+package nats
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerStats(t *testing.T) {
+	now := time.Now()
+
+	var stats consumerStats
+
+	stats.processed(10, 1, now.Add(-time.Minute))
+	stats.processed(11, 3, now)
+	stats.failed(errors.New("old"), now.Add(-2*errorHistory))
+	stats.failed(errors.New("ten minutes ago"), now.Add(-10*time.Minute))
+	stats.failed(errors.New("connection refused"), now.Add(-time.Minute))
+
+	assert.Equal(t, uint64(11), stats.lastSequence)
+	assert.Equal(t, now, stats.lastProcessedAt)
+	assert.Equal(t, uint64(1), stats.redeliveries)
+	assert.Equal(t, "connection refused", stats.lastError)
+	assert.Equal(t, 1, stats.recentErrors(5*time.Minute, now))
+	assert.Equal(t, 2, stats.recentErrors(errorHistory, now))
+	assert.Len(t, stats.errors, 2)
+}
@@ -0,0 +1,85 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/contract"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+// contractsDir holds the golden event fixtures shared by the services.
+const contractsDir = "../../../../../contracts/events"
+
+// decodeFixture delivers the payload of fixture through JetStream to decoder.
+func decodeFixture[T any](t *testing.T, fixture contract.Fixture, decoder Decoder[T]) (*T, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{fixture.Subject}})
+	assert.NoError(t, err)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{Durable: "contract"})
+	assert.NoError(t, err)
+
+	var (
+		decoded    *T
+		decodeErr  error
+		deliveries int
+	)
+
+	_, err = cons.Consume(func(msg jetstream.Msg) {
+		deliveries++
+		decoded, decodeErr = decoder(ctx, msg)
+		msg.Ack() //nolint:errcheck
+	})
+	assert.NoError(t, err)
+
+	_, err = js.Publish(ctx, fixture.Subject, fixture.Payload)
+	assert.NoError(t, err)
+
+	js.Flush()
+	assert.Equal(t, 1, deliveries)
+
+	return decoded, decodeErr
+}
+
+func TestDecoder_AcceptsEveryEventVersion(t *testing.T) {
+	decoders := map[string]func(*testing.T, contract.Fixture) (any, error){
+		dto.UserCreatedSubject: func(t *testing.T, fixture contract.Fixture) (any, error) {
+			return decodeFixture(t, fixture, NewDecoder[dto.UserCreated]())
+		},
+		dto.ListingCreatedSubject: func(t *testing.T, fixture contract.Fixture) (any, error) {
+			return decodeFixture(t, fixture, NewDecoder[dto.ListingCreated]())
+		},
+	}
+
+	fsys := os.DirFS(contractsDir)
+
+	for _, event := range dto.EventCatalog.Consumes {
+		decode, ok := decoders[event.Subject]
+		if !assert.True(t, ok, "no decoder tested for %s", event.Subject) {
+			continue
+		}
+
+		fixtures, err := contract.Load(fsys, event.Subject)
+		assert.NoError(t, err)
+
+		for _, fixture := range fixtures {
+			t.Run(fixture.String(), func(t *testing.T) {
+				decoded, err := decode(t, fixture)
+				if assert.NoError(t, err) {
+					assert.NoError(t, contract.Preserved(fixture, decoded))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package nats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// EventDecoder decodes a message to the event type of its subject.
+type EventDecoder func(ctx context.Context, msg jetstream.Msg) (any, error)
+
+// AnyDecoder adapts the Decoder of a consumer to an EventDecoder.
+func AnyDecoder[T any](dec Decoder[T]) EventDecoder {
+	return func(ctx context.Context, msg jetstream.Msg) (any, error) {
+		return dec(ctx, msg)
+	}
+}
+
+// FieldFilter matches the events whose JSON field at Path equals Value.
+type FieldFilter struct {
+	Path  []string
+	Value string
+}
+
+// ParseFieldFilter parses a path=value filter, the path is dot separated like
+// user.name.
+func ParseFieldFilter(expr string) (FieldFilter, error) {
+	path, value, ok := strings.Cut(expr, "=")
+	if !ok || path == "" {
+		return FieldFilter{}, fmt.Errorf("invalid filter %q, want field=value", expr)
+	}
+
+	return FieldFilter{Path: strings.Split(path, "."), Value: value}, nil
+}
+
+func (f FieldFilter) Match(fields map[string]any) bool {
+	var value any = fields
+
+	for _, key := range f.Path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		if value, ok = object[key]; !ok {
+			return false
+		}
+	}
+
+	if value == nil {
+		return f.Value == "null"
+	}
+
+	return fmt.Sprint(value) == f.Value
+}
+
+// Inspector prints the messages of a stream decoded like their consumers
+// decode them, a subject without decoder is printed as is.
+type Inspector struct {
+	out      io.Writer
+	decoders map[string]EventDecoder
+	filters  []FieldFilter
+}
+
+func NewInspector(out io.Writer, decoders map[string]EventDecoder, filters ...FieldFilter) *Inspector {
+	return &Inspector{
+		out:      out,
+		decoders: decoders,
+		filters:  filters,
+	}
+}
+
+// Print writes msg unless a filter rejects it, it reports whether msg was
+// printed.
+func (i *Inspector) Print(ctx context.Context, msg jetstream.Msg) (bool, error) {
+	data := msg.Data()
+
+	if dec, ok := i.decoders[msg.Subject()]; ok {
+		event, err := dec(ctx, msg)
+		if err != nil {
+			return false, fmt.Errorf("decode %s: %w", msg.Subject(), err)
+		}
+
+		if data, err = json.Marshal(event); err != nil {
+			return false, fmt.Errorf("encode %s: %w", msg.Subject(), err)
+		}
+	}
+
+	if len(i.filters) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var fields map[string]any
+		if err := decoder.Decode(&fields); err != nil {
+			return false, nil //nolint:nilerr // an event that isn't an object can't match
+		}
+
+		for _, filter := range i.filters {
+			if !filter.Match(fields) {
+				return false, nil
+			}
+		}
+	}
+
+	fmt.Fprintln(i.out, header(msg))
+
+	var body bytes.Buffer
+	if err := json.Indent(&body, data, "", "  "); err != nil {
+		body.Reset()
+		body.Write(data)
+	}
+
+	fmt.Fprintf(i.out, "%s\n\n", body.String())
+
+	return true, nil
+}
+
+func header(msg jetstream.Msg) string {
+	line := msg.Subject()
+
+	if meta, err := msg.Metadata(); err == nil {
+		line = fmt.Sprintf("#%d %s %s", meta.Sequence.Stream, line, meta.Timestamp.Format(time.RFC3339Nano))
+	}
+
+	if id := msg.Headers().Get(dto.RequestIDHeader); id != "" {
+		line += " request_id=" + id
+	}
+
+	return line
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package nats
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMsg struct {
+	jetstream.Msg
+	subject string
+	data    []byte
+	header  nats.Header
+	seq     uint64
+}
+
+func (m *fakeMsg) Subject() string      { return m.subject }
+func (m *fakeMsg) Data() []byte         { return m.data }
+func (m *fakeMsg) Headers() nats.Header { return m.header }
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{
+		Sequence:  jetstream.SequencePair{Stream: m.seq},
+		Timestamp: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func TestInspector(t *testing.T) {
+	decoders := map[string]EventDecoder{
+		"listing.created": AnyDecoder(NewDecoder[dto.ListingCreated]()),
+	}
+
+	msg := &fakeMsg{
+		subject: "listing.created",
+		data:    []byte(`{"id":7,"listing_type":"rent","price":1000,"user_id":42,"unknown":true}`),
+		header:  nats.Header{dto.RequestIDHeader: []string{"abc-123"}},
+		seq:     12,
+	}
+
+	t.Run("prints the decoded event", func(t *testing.T) {
+		out := new(bytes.Buffer)
+
+		printed, err := NewInspector(out, decoders).Print(context.Background(), msg)
+		assert.NoError(t, err)
+		assert.True(t, printed)
+
+		assert.Contains(t, out.String(), "#12 listing.created 2026-10-01T00:00:00Z request_id=abc-123\n")
+		assert.Contains(t, out.String(), `  "user_id": 42`)
+		assert.NotContains(t, out.String(), "unknown")
+	})
+
+	t.Run("filters by field", func(t *testing.T) {
+		matching, err := ParseFieldFilter("user_id=42")
+		assert.NoError(t, err)
+
+		other, err := ParseFieldFilter("listing_type=sale")
+		assert.NoError(t, err)
+
+		printed, err := NewInspector(new(bytes.Buffer), decoders, matching).Print(context.Background(), msg)
+		assert.NoError(t, err)
+		assert.True(t, printed)
+
+		printed, err = NewInspector(new(bytes.Buffer), decoders, matching, other).Print(context.Background(), msg)
+		assert.NoError(t, err)
+		assert.False(t, printed)
+	})
+
+	t.Run("subject without decoder is printed as is", func(t *testing.T) {
+		out := new(bytes.Buffer)
+
+		_, err := NewInspector(out, decoders).Print(context.Background(), &fakeMsg{
+			subject: "user.deleted",
+			data:    []byte(`{"id":1}`),
+			header:  nats.Header{},
+		})
+		assert.NoError(t, err)
+
+		assert.Contains(t, out.String(), `  "id": 1`)
+	})
+
+	t.Run("undecodable event", func(t *testing.T) {
+		_, err := NewInspector(new(bytes.Buffer), decoders).Print(context.Background(), &fakeMsg{
+			subject: "listing.created",
+			data:    []byte(`not json`),
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseFieldFilter(t *testing.T) {
+	filter, err := ParseFieldFilter("user.name=John Doe")
+	assert.NoError(t, err)
+
+	assert.True(t, filter.Match(map[string]any{"user": map[string]any{"name": "John Doe"}}))
+	assert.False(t, filter.Match(map[string]any{"user": "John Doe"}))
+
+	_, err = ParseFieldFilter("user.name")
+	assert.Error(t, err)
+}
@@ -0,0 +1,371 @@
+package jetstreamtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Consumer is a fake pull consumer, its messages are delivered by Flush once
+// Consume is called.
+type Consumer struct {
+	jetstream.Consumer
+
+	stream  *Stream
+	name    string
+	cfg     jetstream.ConsumerConfig
+	created time.Time
+
+	// nextSeq is the stream sequence the next new message is looked up from.
+	nextSeq      uint64
+	lastSequence uint64
+	redeliver    []*storedMsg
+	ackPending   map[uint64]*Msg
+	delivered    map[uint64]uint64
+	// buffered are the messages pulled by the client but not handed to the
+	// handler yet.
+	buffered   []*Msg
+	consumeCtx *consumeContext
+}
+
+// Consume makes Flush deliver the messages to handler, the options are
+// ignored.
+func (c *Consumer) Consume(handler jetstream.MessageHandler, _ ...jetstream.PullConsumeOpt) (jetstream.ConsumeContext, error) {
+	c.stream.js.mu.Lock()
+	defer c.stream.js.mu.Unlock()
+
+	if c.consumeCtx != nil {
+		c.buffered = nil
+		c.consumeCtx.close()
+	}
+
+	c.consumeCtx = &consumeContext{
+		consumer: c,
+		handler:  handler,
+		closed:   make(chan struct{}),
+	}
+
+	return c.consumeCtx, nil
+}
+
+func (c *Consumer) Info(context.Context) (*jetstream.ConsumerInfo, error) {
+	c.stream.js.mu.Lock()
+	defer c.stream.js.mu.Unlock()
+
+	if _, ok := c.stream.consumers[c.name]; !ok {
+		return nil, jetstream.ErrConsumerNotFound
+	}
+
+	return c.info(), nil
+}
+
+func (c *Consumer) CachedInfo() *jetstream.ConsumerInfo {
+	c.stream.js.mu.Lock()
+	defer c.stream.js.mu.Unlock()
+
+	return c.info()
+}
+
+func (c *Consumer) info() *jetstream.ConsumerInfo {
+	info := &jetstream.ConsumerInfo{
+		Stream:        c.stream.cfg.Name,
+		Name:          c.name,
+		Created:       c.created,
+		Config:        c.cfg,
+		Delivered:     jetstream.SequenceInfo{Stream: c.lastSequence},
+		NumAckPending: len(c.ackPending),
+		NumPending:    c.numPending(),
+	}
+
+	if c.paused() {
+		info.Paused = true
+		info.PauseRemaining = c.cfg.PauseUntil.Sub(c.stream.js.now())
+	}
+
+	for _, msg := range c.ackPending {
+		if msg.numDelivered > 1 {
+			info.NumRedelivered++
+		}
+	}
+
+	for _, stored := range c.redeliver {
+		if c.delivered[stored.sequence] > 0 {
+			info.NumRedelivered++
+		}
+	}
+
+	return info
+}
+
+func (c *Consumer) filters() []string {
+	if c.cfg.FilterSubject != "" {
+		return []string{c.cfg.FilterSubject}
+	}
+
+	return c.cfg.FilterSubjects
+}
+
+func (c *Consumer) accepts(stored *storedMsg) bool {
+	filters := c.filters()
+
+	return len(filters) == 0 || matchesAny(filters, stored.subject)
+}
+
+// paused reports whether the server holds the deliveries of the consumer.
+func (c *Consumer) paused() bool {
+	return c.cfg.PauseUntil != nil && c.stream.js.now().Before(*c.cfg.PauseUntil)
+}
+
+func (c *Consumer) pauseResponse() *jetstream.ConsumerPauseResponse {
+	response := &jetstream.ConsumerPauseResponse{Paused: c.paused()}
+
+	if response.Paused {
+		response.PauseUntil = *c.cfg.PauseUntil
+		response.PauseRemaining = c.cfg.PauseUntil.Sub(c.stream.js.now())
+	}
+
+	return response
+}
+
+// numPending counts the new messages left to deliver.
+func (c *Consumer) numPending() uint64 {
+	var pending uint64
+
+	for seq := c.nextSeq; seq <= uint64(len(c.stream.msgs)); seq++ {
+		if c.accepts(c.stream.msgs[seq-1]) {
+			pending++
+		}
+	}
+
+	return pending
+}
+
+// next takes the next message to deliver, the buffered ones first then the
+// naked ones, nil when there is none.
+func (c *Consumer) next() *Msg {
+	if len(c.buffered) > 0 {
+		msg := c.buffered[0]
+		c.buffered = c.buffered[1:]
+
+		return msg
+	}
+
+	return c.pull()
+}
+
+// pull takes the next message from the server, the naked ones first, nil
+// while the consumer is paused.
+func (c *Consumer) pull() *Msg {
+	if c.paused() {
+		return nil
+	}
+
+	if len(c.redeliver) > 0 {
+		stored := c.redeliver[0]
+		c.redeliver = c.redeliver[1:]
+
+		return c.deliver(stored)
+	}
+
+	for c.nextSeq <= uint64(len(c.stream.msgs)) {
+		stored := c.stream.msgs[c.nextSeq-1]
+		c.nextSeq++
+
+		if c.accepts(stored) {
+			return c.deliver(stored)
+		}
+	}
+
+	return nil
+}
+
+func (c *Consumer) deliver(stored *storedMsg) *Msg {
+	c.delivered[stored.sequence]++
+	c.lastSequence = max(c.lastSequence, stored.sequence)
+
+	msg := &Msg{
+		stored:       stored,
+		consumer:     c,
+		numDelivered: c.delivered[stored.sequence],
+		numPending:   c.numPending(),
+	}
+
+	if c.cfg.AckPolicy != jetstream.AckNonePolicy {
+		c.ackPending[stored.sequence] = msg
+	}
+
+	return msg
+}
+
+// settle ends the delivery of msg, a message to redeliver is dropped once
+// delivered MaxDeliver times.
+func (c *Consumer) settle(msg *Msg, redeliver bool) error {
+	c.stream.js.mu.Lock()
+	defer c.stream.js.mu.Unlock()
+
+	// the server doesn't wait for the acks of these consumers
+	if c.cfg.AckPolicy == jetstream.AckNonePolicy {
+		return nil
+	}
+
+	if msg.done {
+		return jetstream.ErrMsgAlreadyAckd
+	}
+
+	msg.done = true
+
+	if c.ackPending[msg.stored.sequence] == msg {
+		delete(c.ackPending, msg.stored.sequence)
+	}
+
+	if redeliver && (c.cfg.MaxDeliver <= 0 || msg.numDelivered < uint64(c.cfg.MaxDeliver)) {
+		c.redeliver = append(c.redeliver, msg.stored)
+	}
+
+	return nil
+}
+
+// ExpireAckWait redelivers the messages delivered but neither acked nor
+// naked, like the server does when their AckWait elapses.
+func (js *JetStream) ExpireAckWait() {
+	js.mu.Lock()
+
+	var expired []*Msg
+
+	for _, stream := range js.streams {
+		for _, consumer := range stream.consumers {
+			for _, msg := range consumer.ackPending {
+				expired = append(expired, msg)
+			}
+		}
+	}
+
+	js.mu.Unlock()
+
+	for _, msg := range expired {
+		msg.consumer.settle(msg, true) //nolint:errcheck
+	}
+}
+
+type consumeContext struct {
+	consumer *Consumer
+	handler  jetstream.MessageHandler
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// Stop drops the buffered messages, they stay pending until their ack wait
+// expires like with the real client.
+func (cc *consumeContext) Stop() {
+	cc.consumer.stream.js.mu.Lock()
+	defer cc.consumer.stream.js.mu.Unlock()
+
+	cc.consumer.buffered = nil
+	cc.close()
+}
+
+// Drain hands the buffered messages to the handler, in the goroutine of the
+// caller, then stops.
+func (cc *consumeContext) Drain() {
+	for {
+		cc.consumer.stream.js.mu.Lock()
+
+		if cc.consumer.consumeCtx != cc || len(cc.consumer.buffered) == 0 {
+			cc.close()
+			cc.consumer.stream.js.mu.Unlock()
+
+			return
+		}
+
+		msg := cc.consumer.buffered[0]
+		cc.consumer.buffered = cc.consumer.buffered[1:]
+		cc.consumer.stream.js.mu.Unlock()
+
+		cc.handler(msg)
+	}
+}
+
+func (cc *consumeContext) Closed() <-chan struct{} {
+	return cc.closed
+}
+
+func (cc *consumeContext) close() {
+	cc.once.Do(func() {
+		if cc.consumer.consumeCtx == cc {
+			cc.consumer.consumeCtx = nil
+		}
+
+		close(cc.closed)
+	})
+}
+
+// Msg is a message delivered by a fake consumer.
+type Msg struct {
+	stored       *storedMsg
+	consumer     *Consumer
+	numDelivered uint64
+	numPending   uint64
+	done         bool
+}
+
+func (m *Msg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{
+		Sequence: jetstream.SequencePair{
+			Stream:   m.stored.sequence,
+			Consumer: m.stored.sequence,
+		},
+		NumDelivered: m.numDelivered,
+		NumPending:   m.numPending,
+		Timestamp:    m.stored.time,
+		Stream:       m.consumer.stream.cfg.Name,
+		Consumer:     m.consumer.name,
+	}, nil
+}
+
+func (m *Msg) Data() []byte {
+	return m.stored.data
+}
+
+func (m *Msg) Headers() nats.Header {
+	return m.stored.header
+}
+
+func (m *Msg) Subject() string {
+	return m.stored.subject
+}
+
+func (m *Msg) Reply() string {
+	return ""
+}
+
+func (m *Msg) Ack() error {
+	return m.consumer.settle(m, false)
+}
+
+func (m *Msg) DoubleAck(context.Context) error {
+	return m.Ack()
+}
+
+func (m *Msg) Nak() error {
+	return m.consumer.settle(m, true)
+}
+
+// NakWithDelay redelivers in the next round of Flush, the delay is ignored.
+func (m *Msg) NakWithDelay(time.Duration) error {
+	return m.Nak()
+}
+
+func (m *Msg) InProgress() error {
+	return nil
+}
+
+func (m *Msg) Term() error {
+	return m.consumer.settle(m, false)
+}
+
+func (m *Msg) TermWithReason(string) error {
+	return m.Term()
+}
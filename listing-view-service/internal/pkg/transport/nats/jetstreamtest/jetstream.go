@@ -0,0 +1,535 @@
+// Package jetstreamtest provides an in-process JetStream for unit tests of the
+// code publishing and consuming events, without NATS server.
+//
+// It implements the subset of the jetstream interfaces the services use:
+// streams, synchronous and asynchronous publishing with PubAck sequences and
+// Nats-Msg-Id deduplication, durable consumers with filter subjects, ack, nak,
+// redelivery, MaxDeliver and pause. The other methods panic.
+// Prefetch fills the client-side buffer of the consumers to test Stop and
+// Drain.
+//
+// Delivery is deterministic: the handlers of the consumers are only called by
+// Flush, in the goroutine of the test, so a test publishes, flushes and then
+// asserts without waiting.
+package jetstreamtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// maxFlushRounds bounds Flush when handlers keep failing a message of a
+// consumer without MaxDeliver.
+const maxFlushRounds = 100
+
+// JetStream is the fake server and client, its zero value isn't usable, use
+// New.
+type JetStream struct {
+	jetstream.JetStream
+
+	mu         sync.Mutex
+	streams    map[string]*Stream
+	publishErr error
+	holdAcks   bool
+	lostAcks   int
+	now        func() time.Time
+}
+
+func New() *JetStream {
+	return &JetStream{
+		streams: make(map[string]*Stream),
+		now:     time.Now,
+	}
+}
+
+// FailPublish makes the next publications fail with err, like when NATS is
+// down, nil restores them.
+func (js *JetStream) FailPublish(err error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.publishErr = err
+}
+
+// HoldAcks makes the asynchronous publications never get their ack, like when
+// NATS is too slow to answer, false restores them.
+func (js *JetStream) HoldAcks(hold bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.holdAcks = hold
+}
+
+// LoseAcks stores the next n asynchronous publications but never resolves
+// their ack, like when the ack of a stored message doesn't reach the client.
+func (js *JetStream) LoseAcks(n int) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.lostAcks = n
+}
+
+func (js *JetStream) AccountInfo(context.Context) (*jetstream.AccountInfo, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	return &jetstream.AccountInfo{Tier: jetstream.Tier{Streams: len(js.streams)}}, nil
+}
+
+// CreateStream returns the existing stream of the same name, the real server
+// only does so when the configs are identical.
+func (js *JetStream) CreateStream(ctx context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	return js.CreateOrUpdateStream(ctx, cfg)
+}
+
+func (js *JetStream) CreateOrUpdateStream(_ context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if stream, ok := js.streams[cfg.Name]; ok {
+		stream.cfg = cfg
+
+		return stream, nil
+	}
+
+	stream := &Stream{
+		js:        js,
+		cfg:       cfg,
+		consumers: make(map[string]*Consumer),
+	}
+	js.streams[cfg.Name] = stream
+
+	return stream, nil
+}
+
+func (js *JetStream) Stream(_ context.Context, name string) (jetstream.Stream, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	stream, ok := js.streams[name]
+	if !ok {
+		return nil, jetstream.ErrStreamNotFound
+	}
+
+	return stream, nil
+}
+
+func (js *JetStream) CreateOrUpdateConsumer(ctx context.Context, stream string,
+	cfg jetstream.ConsumerConfig,
+) (jetstream.Consumer, error) {
+	s, err := js.Stream(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CreateOrUpdateConsumer(ctx, cfg)
+}
+
+func (js *JetStream) Publish(ctx context.Context, subject string, data []byte,
+	opts ...jetstream.PublishOpt,
+) (*jetstream.PubAck, error) {
+	return js.PublishMsg(ctx, &nats.Msg{Subject: subject, Data: data}, opts...)
+}
+
+// PublishMsg stores msg in the stream whose subjects match, the options are
+// ignored. A message with the Nats-Msg-Id of a stored one is acked as a
+// duplicate and not stored again, whatever the time since the first.
+func (js *JetStream) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if js.publishErr != nil {
+		return nil, js.publishErr
+	}
+
+	for _, stream := range js.streams {
+		if !matchesAny(stream.cfg.Subjects, msg.Subject) {
+			continue
+		}
+
+		if id := msg.Header.Get(jetstream.MsgIDHeader); id != "" {
+			for _, stored := range stream.msgs {
+				if stored.header.Get(jetstream.MsgIDHeader) == id {
+					return &jetstream.PubAck{Stream: stream.cfg.Name, Sequence: stored.sequence, Duplicate: true}, nil
+				}
+			}
+		}
+
+		header := nats.Header{}
+		for key, values := range msg.Header {
+			header[key] = append([]string(nil), values...)
+		}
+
+		stored := &storedMsg{
+			subject:  msg.Subject,
+			data:     append([]byte(nil), msg.Data...),
+			header:   header,
+			sequence: uint64(len(stream.msgs)) + 1,
+			time:     js.now(),
+		}
+		stream.msgs = append(stream.msgs, stored)
+
+		return &jetstream.PubAck{Stream: stream.cfg.Name, Sequence: stored.sequence}, nil
+	}
+
+	return nil, jetstream.ErrNoStreamResponse
+}
+
+func (js *JetStream) PublishAsync(subject string, data []byte,
+	opts ...jetstream.PublishOpt,
+) (jetstream.PubAckFuture, error) {
+	return js.PublishMsgAsync(&nats.Msg{Subject: subject, Data: data}, opts...)
+}
+
+// PublishMsgAsync publishes like PublishMsg, the returned future is already
+// resolved with its ack or error, unless the acks are held or lost. A held
+// message isn't stored, a message whose ack is lost is.
+func (js *JetStream) PublishMsgAsync(msg *nats.Msg, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	future := &pubAckFuture{
+		msg:  msg,
+		ok:   make(chan *jetstream.PubAck, 1),
+		errs: make(chan error, 1),
+	}
+
+	js.mu.Lock()
+	hold := js.holdAcks
+	lost := js.lostAcks > 0
+
+	if lost {
+		js.lostAcks--
+	}
+	js.mu.Unlock()
+
+	if hold {
+		return future, nil
+	}
+
+	ack, err := js.PublishMsg(context.Background(), msg, opts...)
+
+	switch {
+	case lost:
+	case err != nil:
+		future.errs <- err
+	default:
+		future.ok <- ack
+	}
+
+	return future, nil
+}
+
+type pubAckFuture struct {
+	msg  *nats.Msg
+	ok   chan *jetstream.PubAck
+	errs chan error
+}
+
+func (f *pubAckFuture) Ok() <-chan *jetstream.PubAck { return f.ok }
+
+func (f *pubAckFuture) Err() <-chan error { return f.errs }
+
+func (f *pubAckFuture) Msg() *nats.Msg { return f.msg }
+
+// Messages returns the messages stored in stream, nil when it doesn't exist.
+func (js *JetStream) Messages(stream string) []*nats.Msg {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	s, ok := js.streams[stream]
+	if !ok {
+		return nil
+	}
+
+	msgs := make([]*nats.Msg, len(s.msgs))
+	for i, stored := range s.msgs {
+		msgs[i] = &nats.Msg{Subject: stored.subject, Data: stored.data, Header: stored.header}
+	}
+
+	return msgs
+}
+
+// Flush delivers the messages available to the consuming consumers until none
+// is left, a naked message is redelivered in the next round. Handlers may
+// publish, ack and nak. It returns the number of deliveries.
+func (js *JetStream) Flush() int {
+	deliveries := 0
+
+	for range maxFlushRounds {
+		round := js.nextRound()
+		if len(round) == 0 {
+			break
+		}
+
+		for _, delivery := range round {
+			delivery.handler(delivery.msg)
+		}
+
+		deliveries += len(round)
+	}
+
+	return deliveries
+}
+
+// Prefetch pulls up to n available messages of every consuming consumer into
+// its client-side buffer without handling them, like a pull consumer fetching
+// ahead of its handler. Flush hands them over first. It returns the number of
+// messages pulled.
+func (js *JetStream) Prefetch(n int) int {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	pulled := 0
+
+	for _, stream := range js.streams {
+		for _, consumer := range stream.consumers {
+			if consumer.consumeCtx == nil {
+				continue
+			}
+
+			for range n {
+				msg := consumer.pull()
+				if msg == nil {
+					break
+				}
+
+				consumer.buffered = append(consumer.buffered, msg)
+				pulled++
+			}
+		}
+	}
+
+	return pulled
+}
+
+type delivery struct {
+	handler jetstream.MessageHandler
+	msg     *Msg
+}
+
+// nextRound takes the next message of every consuming consumer.
+func (js *JetStream) nextRound() []delivery {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	var round []delivery
+
+	for _, stream := range js.streams {
+		for _, consumer := range stream.consumers {
+			if consumer.consumeCtx == nil {
+				continue
+			}
+
+			if msg := consumer.next(); msg != nil {
+				round = append(round, delivery{handler: consumer.consumeCtx.handler, msg: msg})
+			}
+		}
+	}
+
+	return round
+}
+
+// Stream is a fake stream keeping every message, it has no limits.
+type Stream struct {
+	jetstream.Stream
+
+	js        *JetStream
+	cfg       jetstream.StreamConfig
+	msgs      []*storedMsg
+	consumers map[string]*Consumer
+}
+
+type storedMsg struct {
+	subject  string
+	data     []byte
+	header   nats.Header
+	sequence uint64
+	time     time.Time
+}
+
+// CreateOrUpdateConsumer keeps the delivery state of an existing consumer, and
+// its pause when cfg has no PauseUntil, a new one starts at its deliver
+// policy.
+func (s *Stream) CreateOrUpdateConsumer(_ context.Context, cfg jetstream.ConsumerConfig) (jetstream.Consumer, error) {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	name := cfg.Durable
+	if name == "" {
+		name = cfg.Name
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("consumer_%d", len(s.consumers)+1)
+	}
+
+	if consumer, ok := s.consumers[name]; ok {
+		if cfg.PauseUntil == nil {
+			cfg.PauseUntil = consumer.cfg.PauseUntil
+		}
+
+		consumer.cfg = cfg
+
+		return consumer, nil
+	}
+
+	consumer := &Consumer{
+		stream:     s,
+		name:       name,
+		cfg:        cfg,
+		nextSeq:    s.startSequence(cfg),
+		ackPending: make(map[uint64]*Msg),
+		delivered:  make(map[uint64]uint64),
+		created:    s.js.now(),
+	}
+	s.consumers[name] = consumer
+
+	return consumer, nil
+}
+
+func (s *Stream) startSequence(cfg jetstream.ConsumerConfig) uint64 {
+	switch cfg.DeliverPolicy {
+	case jetstream.DeliverNewPolicy:
+		return uint64(len(s.msgs)) + 1
+	case jetstream.DeliverByStartSequencePolicy:
+		return max(cfg.OptStartSeq, 1)
+	case jetstream.DeliverLastPolicy:
+		return max(uint64(len(s.msgs)), 1)
+	default:
+		return 1
+	}
+}
+
+func (s *Stream) Consumer(_ context.Context, name string) (jetstream.Consumer, error) {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	consumer, ok := s.consumers[name]
+	if !ok {
+		return nil, jetstream.ErrConsumerNotFound
+	}
+
+	return consumer, nil
+}
+
+func (s *Stream) DeleteConsumer(_ context.Context, name string) error {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	consumer, ok := s.consumers[name]
+	if !ok {
+		return jetstream.ErrConsumerNotFound
+	}
+
+	if consumer.consumeCtx != nil {
+		consumer.consumeCtx.close()
+	}
+
+	delete(s.consumers, name)
+
+	return nil
+}
+
+// PauseConsumer stops the server from delivering messages to the consumer
+// until pauseUntil, the messages its clients already pulled stay buffered.
+func (s *Stream) PauseConsumer(_ context.Context, name string,
+	pauseUntil time.Time,
+) (*jetstream.ConsumerPauseResponse, error) {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	consumer, ok := s.consumers[name]
+	if !ok {
+		return nil, jetstream.ErrConsumerNotFound
+	}
+
+	consumer.cfg.PauseUntil = &pauseUntil
+
+	return consumer.pauseResponse(), nil
+}
+
+func (s *Stream) ResumeConsumer(_ context.Context, name string) (*jetstream.ConsumerPauseResponse, error) {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	consumer, ok := s.consumers[name]
+	if !ok {
+		return nil, jetstream.ErrConsumerNotFound
+	}
+
+	consumer.cfg.PauseUntil = nil
+
+	return consumer.pauseResponse(), nil
+}
+
+// Info reports the state of the stream with the count of every subject, the
+// options are ignored.
+func (s *Stream) Info(context.Context, ...jetstream.StreamInfoOpt) (*jetstream.StreamInfo, error) {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	return s.info(), nil
+}
+
+func (s *Stream) CachedInfo() *jetstream.StreamInfo {
+	s.js.mu.Lock()
+	defer s.js.mu.Unlock()
+
+	return s.info()
+}
+
+func (s *Stream) info() *jetstream.StreamInfo {
+	state := jetstream.StreamState{
+		Msgs:      uint64(len(s.msgs)),
+		Consumers: len(s.consumers),
+		Subjects:  make(map[string]uint64),
+	}
+
+	for _, msg := range s.msgs {
+		state.Bytes += uint64(len(msg.data))
+		state.Subjects[msg.subject]++
+	}
+
+	if len(s.msgs) > 0 {
+		first, last := s.msgs[0], s.msgs[len(s.msgs)-1]
+		state.FirstSeq, state.FirstTime = first.sequence, first.time
+		state.LastSeq, state.LastTime = last.sequence, last.time
+	}
+
+	state.NumSubjects = uint64(len(state.Subjects))
+
+	return &jetstream.StreamInfo{Config: s.cfg, State: state}
+}
+
+// matchesAny reports whether subject matches one of the patterns, which may
+// use the * and > wildcards.
+func matchesAny(patterns []string, subject string) bool {
+	for _, pattern := range patterns {
+		if matches(pattern, subject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matches(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+
+	return len(patternTokens) == len(subjectTokens)
+}
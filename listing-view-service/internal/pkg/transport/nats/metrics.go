@@ -0,0 +1,45 @@
+package nats
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/metrics"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/timing"
+)
+
+// consumer metrics are labelled with the subscribed subject, not the message
+// subject, to keep the label cardinality bounded.
+var (
+	messagesProcessed = metrics.Default.NewCounterVec("nats_messages_processed_total",
+		"Total number of NATS messages handled successfully.", "subject")
+	messagesFailed = metrics.Default.NewCounterVec("nats_messages_failed_total",
+		"Total number of NATS messages that failed to decode or to be handled.", "subject")
+	messagesNaked = metrics.Default.NewCounterVec("nats_messages_nak_total",
+		"Total number of NATS messages negatively acknowledged for redelivery.", "subject")
+	handlerDuration = metrics.Default.NewHistogramVec("nats_handler_duration_seconds",
+		"NATS message handler latency in seconds.", metrics.DefBuckets, "subject")
+)
+
+// observeHandler records the latency of a message handler and logs it at WARN
+// with its DB and downstream time when slower than threshold, a zero
+// threshold disables the log.
+func observeHandler(ctx context.Context, subject string, start time.Time,
+	threshold time.Duration, breakdown *timing.Breakdown,
+) {
+	duration := time.Since(start)
+	handlerDuration.WithLabelValues(subject).Observe(duration.Seconds())
+
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+
+	attrs := []any{
+		slog.String("subject", subject),
+		slog.Duration("duration_ns", duration),
+		slog.Duration("threshold_ns", threshold),
+	}
+
+	slog.WarnContext(ctx, "slow message handler", append(attrs, breakdown.Attrs()...)...)
+}
@@ -0,0 +1,62 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoAckMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantDelivered int
+	}{
+		{
+			name:          "acks on success",
+			wantDelivered: 1,
+		},
+		{
+			name:          "naks on error so the message is redelivered",
+			err:           errors.New("failed"),
+			wantDelivered: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			js := jetstreamtest.New()
+
+			stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.created"}})
+			assert.NoError(t, err)
+
+			cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{Durable: "users", MaxDeliver: 2})
+			assert.NoError(t, err)
+
+			ep := AutoAckMiddleware()(func(context.Context, interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+
+			_, err = cons.Consume(func(msg jetstream.Msg) {
+				ep(context.WithValue(ctx, "nats-msg", msg), nil) //nolint:errcheck,staticcheck
+			})
+			assert.NoError(t, err)
+
+			_, err = js.Publish(ctx, "user.created", []byte("{}"))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantDelivered, js.Flush())
+
+			info, err := cons.Info(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, info.NumAckPending)
+		})
+	}
+}
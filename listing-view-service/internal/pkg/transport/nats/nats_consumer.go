@@ -2,27 +2,70 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/timing"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+var (
+	errNotStarted   = errors.New("consumer is not started")
+	errNotConsuming = errors.New("consumer stopped consuming")
+)
+
+const (
+	// maxDeliver is how many times a message is delivered, a failed message is
+	// not redelivered.
+	maxDeliver = 1
+	// pauseDuration is how long Pause pauses the consumer on the server, which
+	// has no pause without deadline.
+	pauseDuration = 100 * 365 * 24 * time.Hour
+)
+
+// ConsumerOption configures a Consumer.
+type ConsumerOption func(*consumerOptions)
+
+type consumerOptions struct {
+	failed endpoint.Endpoint
+}
+
+// WithFailedEndpoint calls ep with a *dto.EventFailed for every message whose
+// last delivery failed, so the application can record what it never applied.
+func WithFailedEndpoint(ep endpoint.Endpoint) ConsumerOption {
+	return func(o *consumerOptions) {
+		o.failed = ep
+	}
+}
+
 func NewSubscriber[T any](
 	ctx context.Context,
 	js jetstream.Stream,
+	name string,
 	subject string,
+	slowThreshold time.Duration,
 	ep endpoint.Endpoint,
 	dec Decoder[T],
 	mw []endpoint.Middleware,
+	opts ...ConsumerOption,
 ) (*Consumer[T], error) {
 	c := &Consumer[T]{
-		js:      js,
-		subject: subject,
-		ep:      ep,
-		dec:     dec,
+		js:            js,
+		name:          name,
+		subject:       subject,
+		slowThreshold: slowThreshold,
+		ep:            ep,
+		dec:           dec,
+	}
+
+	for _, opt := range opts {
+		opt(&c.consumerOptions)
 	}
 
 	if err := c.createConsumer(ctx); err != nil {
@@ -32,24 +75,34 @@ func NewSubscriber[T any](
 	return c, nil
 }
 
+// Consumer is a durable JetStream consumer, it can be paused and keeps
+// statistics for the admin API.
 type Consumer[T any] struct {
-	js           jetstream.Stream
-	subject      string
-	ep           endpoint.Endpoint
-	dec          Decoder[T]
-	mw           []endpoint.Middleware
-	consumerName string
-	consumer     jetstream.Consumer
-	consumerCtx  jetstream.ConsumeContext
+	js            jetstream.Stream
+	name          string
+	subject       string
+	slowThreshold time.Duration
+	ep            endpoint.Endpoint
+	dec           Decoder[T]
+	mw            []endpoint.Middleware
+	consumer      jetstream.Consumer
+	stats         consumerStats
+	consumerOptions
+
+	mu          sync.Mutex
+	ctx         context.Context //nolint:containedctx // the context messages are handled with on resume
+	consumerCtx jetstream.ConsumeContext
+	paused      bool
 }
 
+// createConsumer creates the durable or updates it, the server keeps the pause
+// of an existing one so a process starting doesn't undo it.
 func (c *Consumer[T]) createConsumer(ctx context.Context) error {
-
 	cons, err := c.js.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
-		Name:          c.consumerName,
+		Durable:       c.name,
 		FilterSubject: c.subject,
 		AckPolicy:     jetstream.AckExplicitPolicy,
-		MaxDeliver:    1,
+		MaxDeliver:    maxDeliver,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create or update consumer: %w", err)
@@ -61,27 +114,79 @@ func (c *Consumer[T]) createConsumer(ctx context.Context) error {
 }
 
 func (c *Consumer[T]) Start(ctx context.Context) error {
-	slog.Info("starting nats consumer", "subject", c.subject)
+	slog.Info("starting nats consumer", "consumer", c.name, "subject", c.subject)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ctx = ctx
+
+	return c.consume()
+}
+
+func (c *Consumer[T]) consume() error {
 	var err error
 
 	c.consumerCtx, err = c.consumer.Consume(func(msg jetstream.Msg) {
-		request, err := c.dec(ctx, msg)
+		msgCtx := dto.ContextWithRequestID(c.ctx, requestID(msg))
+		msgCtx = context.WithValue(msgCtx, "nats-msg", msg) //nolint:staticcheck
+
+		meta, metaErr := msg.Metadata()
+		if metaErr == nil {
+			msgCtx = dto.ContextWithEventMeta(msgCtx, dto.EventMeta{
+				Subject:  msg.Subject(),
+				Sequence: meta.Sequence.Stream,
+			})
+		}
+
+		msgCtx, span := startHandlerSpan(msgCtx, msg.Subject(), msg.Headers())
+		defer span.End()
+
+		msgCtx, breakdown := timing.ContextWithBreakdown(msgCtx)
+		start := time.Now()
+
+		defer func() {
+			observeHandler(msgCtx, c.subject, start, c.slowThreshold, breakdown)
+		}()
+
+		request, err := c.dec(msgCtx, msg)
 		if err != nil {
-			slog.Error("failed to decode message", "error", err)
+			slog.ErrorContext(msgCtx, "failed to decode message", "subject", msg.Subject(), "error", err)
+			span.RecordError(err)
+			c.stats.failed(err, time.Now())
+			messagesFailed.WithLabelValues(c.subject).Inc()
+			messagesNaked.WithLabelValues(c.subject).Inc()
+			c.recordFailure(msgCtx, msg, meta, err)
 			msg.Nak()
 
 			return
 		}
 
-		_, err = c.ep(ctx, request)
+		_, err = c.ep(msgCtx, request)
 		if err != nil {
-			slog.Error("failed to execute endpoint", "error", err)
+			slog.ErrorContext(msgCtx, "failed to execute endpoint", "subject", msg.Subject(), "error", err)
+			span.RecordError(err)
+			c.stats.failed(err, time.Now())
+			messagesFailed.WithLabelValues(c.subject).Inc()
+			messagesNaked.WithLabelValues(c.subject).Inc()
+			c.recordFailure(msgCtx, msg, meta, err)
 			msg.Nak()
 
 			return
 		}
 
 		msg.Ack()
+		messagesProcessed.WithLabelValues(c.subject).Inc()
+
+		if metaErr == nil {
+			c.stats.processed(meta.Sequence.Stream, meta.NumDelivered, time.Now())
+		}
+
+		slog.InfoContext(msgCtx, "successfully processing message",
+			slog.String("type", "inbound"),
+			slog.String("transport", "nats"),
+			slog.String("subject", msg.Subject()),
+		)
 	})
 	if err != nil {
 		return fmt.Errorf("failed to consume: %w", err)
@@ -90,7 +195,173 @@ func (c *Consumer[T]) Start(ctx context.Context) error {
 	return nil
 }
 
+// recordFailure hands a message that won't be redelivered to the failed
+// endpoint, meta is nil when the message has no stream metadata.
+func (c *Consumer[T]) recordFailure(ctx context.Context, msg jetstream.Msg, meta *jetstream.MsgMetadata, err error) {
+	if c.failed == nil || meta == nil || meta.NumDelivered < maxDeliver {
+		return
+	}
+
+	_, failedErr := c.failed(ctx, &dto.EventFailed{
+		Subject:  msg.Subject(),
+		Sequence: meta.Sequence.Stream,
+		Error:    err.Error(),
+	})
+	if failedErr != nil {
+		slog.ErrorContext(ctx, "failed to record failed message", "subject", msg.Subject(), "error", failedErr)
+	}
+}
+
 func (c *Consumer[T]) Stop() {
-	slog.Info("stopping nats consumer", "subject", c.subject)
-	c.consumerCtx.Drain()
+	slog.Info("stopping nats consumer", "consumer", c.name, "subject", c.subject)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused && c.consumerCtx != nil {
+		c.consumerCtx.Drain()
+	}
+}
+
+func (c *Consumer[T]) Name() string {
+	return c.name
+}
+
+// Pause pauses the durable on the server, so no process sharing it gets new
+// messages, then stops fetching and waits until the messages already pulled
+// by this process are handled, they would never be redelivered with
+// MaxDeliver 1. The pause survives restarts until Resume.
+func (c *Consumer[T]) Pause(ctx context.Context) error {
+	slog.Info("pausing nats consumer", "consumer", c.name, "subject", c.subject)
+
+	if _, err := c.js.PauseConsumer(ctx, c.name, time.Now().Add(pauseDuration)); err != nil {
+		return fmt.Errorf("pause consumer on the server: %w", err)
+	}
+
+	c.mu.Lock()
+
+	if c.paused || c.consumerCtx == nil {
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	consumerCtx := c.consumerCtx
+	c.paused = true
+	c.mu.Unlock()
+
+	consumerCtx.Drain()
+
+	select {
+	case <-consumerCtx.Closed():
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for in-flight messages: %w", ctx.Err())
+	}
+}
+
+// Resume resumes the durable on the server, for every process sharing it, and
+// the consumption of this process.
+func (c *Consumer[T]) Resume(ctx context.Context) error {
+	slog.Info("resuming nats consumer", "consumer", c.name, "subject", c.subject)
+
+	if _, err := c.js.ResumeConsumer(ctx, c.name); err != nil {
+		return fmt.Errorf("resume consumer on the server: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		return nil
+	}
+
+	if err := c.consume(); err != nil {
+		return err
+	}
+
+	c.paused = false
+
+	return nil
+}
+
+// Status combines the server view of the consumer with the messages this
+// process handled, errorWindow is how far back errors are counted. The
+// consumer is paused when the server holds it, whichever process paused it.
+func (c *Consumer[T]) Status(ctx context.Context, errorWindow time.Duration) (dto.ConsumerStatus, error) {
+	info, err := c.consumer.Info(ctx)
+	if err != nil {
+		return dto.ConsumerStatus{}, fmt.Errorf("get consumer info: %w", err)
+	}
+
+	c.mu.Lock()
+	paused := c.paused
+	c.mu.Unlock()
+
+	c.stats.mu.Lock()
+	status := dto.ConsumerStatus{
+		Name:           c.name,
+		Subject:        c.subject,
+		Paused:         paused || info.Paused,
+		NumPending:     info.NumPending,
+		NumAckPending:  info.NumAckPending,
+		NumRedelivered: info.NumRedelivered,
+		Redeliveries:   c.stats.redeliveries,
+		LastSequence:   c.stats.lastSequence,
+		ErrorWindow:    errorWindow.String(),
+		LastError:      c.stats.lastError,
+	}
+
+	if !c.stats.lastProcessedAt.IsZero() {
+		status.LastProcessedAt = c.stats.lastProcessedAt.UnixMicro()
+	}
+
+	if info.Paused && info.Config.PauseUntil != nil {
+		status.PausedUntil = info.Config.PauseUntil.UnixMicro()
+	}
+	c.stats.mu.Unlock()
+
+	status.RecentErrors = c.stats.recentErrors(errorWindow, time.Now())
+
+	return status, nil
+}
+
+// Check fails when the consumer stopped consuming without being paused or is
+// gone from the server.
+func (c *Consumer[T]) Check(ctx context.Context) error {
+	c.mu.Lock()
+	consumerCtx, paused := c.consumerCtx, c.paused
+	c.mu.Unlock()
+
+	// a paused consumer is stopped on purpose
+	if paused {
+		return nil
+	}
+
+	if consumerCtx == nil {
+		return errNotStarted
+	}
+
+	select {
+	case <-consumerCtx.Closed():
+		return errNotConsuming
+	default:
+	}
+
+	if _, err := c.consumer.Info(ctx); err != nil {
+		return fmt.Errorf("get consumer info: %w", err)
+	}
+
+	return nil
+}
+
+// requestID returns the correlation ID the publisher put in the message
+// headers, or a new one for publishers that do not propagate it or send an
+// invalid one.
+func requestID(msg jetstream.Msg) string {
+	if id := msg.Headers().Get(dto.RequestIDHeader); dto.ValidRequestID(id) {
+		return id
+	}
+
+	return dto.NewRequestID()
 }
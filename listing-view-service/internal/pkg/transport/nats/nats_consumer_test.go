@@ -0,0 +1,246 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/listing-view-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+type userCreated struct {
+	ID int64 `json:"id"`
+}
+
+func newTestConsumer(t *testing.T, ep func(ctx context.Context, request interface{}) (interface{}, error),
+	opts ...ConsumerOption,
+) (*jetstreamtest.JetStream, *Consumer[userCreated]) {
+	t.Helper()
+
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     "listing_view_event",
+		Subjects: []string{"user.created", "listing.created"},
+	})
+	assert.NoError(t, err)
+
+	consumer, err := NewSubscriber(ctx, stream, "listing_view_user_created", "user.created",
+		0, ep, NewDecoder[userCreated](), nil, opts...)
+	assert.NoError(t, err)
+	assert.NoError(t, consumer.Start(ctx))
+
+	return js, consumer
+}
+
+func publishTestMsg(t *testing.T, js jetstream.JetStream, subject, data, requestID string) {
+	t.Helper()
+
+	msg := nats.NewMsg(subject)
+	msg.Data = []byte(data)
+
+	if requestID != "" {
+		msg.Header.Set(dto.RequestIDHeader, requestID)
+	}
+
+	_, err := js.PublishMsg(context.Background(), msg)
+	assert.NoError(t, err)
+}
+
+func TestConsumer_AcksHandledMessages(t *testing.T) {
+	var (
+		requests []userCreated
+		metas    []dto.EventMeta
+		reqIDs   []string
+	)
+
+	js, consumer := newTestConsumer(t, func(ctx context.Context, request interface{}) (interface{}, error) {
+		requests = append(requests, *request.(*userCreated))
+
+		meta, _ := dto.EventMetaFromContext(ctx)
+		metas = append(metas, meta)
+
+		reqContext, _ := dto.RequestFromContext(ctx)
+		reqIDs = append(reqIDs, reqContext.RequestID)
+
+		_, ok := ctx.Value("nats-msg").(jetstream.Msg)
+		assert.True(t, ok)
+
+		return nil, nil
+	})
+
+	publishTestMsg(t, js, "listing.created", `{"id": 7}`, "")
+	publishTestMsg(t, js, "user.created", `{"id": 42}`, "req-1")
+	publishTestMsg(t, js, "user.created", `{"id": 43}`, "req 2")
+
+	assert.Equal(t, 2, js.Flush())
+	assert.Equal(t, []userCreated{{ID: 42}, {ID: 43}}, requests)
+	assert.Equal(t, []dto.EventMeta{
+		{Subject: "user.created", Sequence: 2},
+		{Subject: "user.created", Sequence: 3},
+	}, metas)
+	// the invalid request ID is replaced
+	assert.Equal(t, "req-1", reqIDs[0])
+	assert.Len(t, reqIDs[1], 36)
+
+	status, err := consumer.Status(context.Background(), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), status.LastSequence)
+	assert.Equal(t, 0, status.NumAckPending)
+	assert.Equal(t, 0, status.RecentErrors)
+}
+
+func TestConsumer_NaksFailedMessages(t *testing.T) {
+	calls := 0
+
+	js, consumer := newTestConsumer(t, func(context.Context, interface{}) (interface{}, error) {
+		calls++
+
+		return nil, errors.New("user repository down")
+	})
+
+	publishTestMsg(t, js, "user.created", `{"id": 42}`, "")
+	publishTestMsg(t, js, "user.created", `not json`, "")
+
+	// the consumer delivers once, a failed message isn't redelivered
+	assert.Equal(t, 2, js.Flush())
+	assert.Equal(t, 1, calls)
+
+	status, err := consumer.Status(context.Background(), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, status.RecentErrors)
+	assert.Equal(t, 0, status.NumAckPending)
+	assert.Contains(t, status.LastError, "failed to unmarshal data")
+}
+
+func TestConsumer_RecordsFailedMessages(t *testing.T) {
+	var failed []dto.EventFailed
+
+	js, _ := newTestConsumer(t, func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New("user repository down")
+	}, WithFailedEndpoint(func(_ context.Context, request interface{}) (interface{}, error) {
+		failed = append(failed, *request.(*dto.EventFailed))
+
+		return nil, nil
+	}))
+
+	publishTestMsg(t, js, "user.created", `{"id": 42}`, "")
+	publishTestMsg(t, js, "listing.created", `{"id": 7}`, "")
+	publishTestMsg(t, js, "user.created", `not json`, "")
+
+	assert.Equal(t, 2, js.Flush())
+
+	if assert.Len(t, failed, 2) {
+		assert.Equal(t, dto.EventFailed{Subject: "user.created", Sequence: 1, Error: "user repository down"}, failed[0])
+		assert.Equal(t, "user.created", failed[1].Subject)
+		assert.Equal(t, uint64(3), failed[1].Sequence)
+		assert.Contains(t, failed[1].Error, "failed to unmarshal data")
+	}
+}
+
+func TestConsumer_PauseAndResume(t *testing.T) {
+	calls := 0
+
+	js, consumer := newTestConsumer(t, func(context.Context, interface{}) (interface{}, error) {
+		calls++
+
+		return nil, nil
+	})
+
+	assert.NoError(t, consumer.Pause(context.Background()))
+	assert.NoError(t, consumer.Check(context.Background()))
+
+	publishTestMsg(t, js, "user.created", `{"id": 42}`, "")
+	assert.Equal(t, 0, js.Flush())
+
+	status, err := consumer.Status(context.Background(), time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, status.Paused)
+	assert.Equal(t, uint64(1), status.NumPending)
+
+	assert.NoError(t, consumer.Resume(context.Background()))
+	assert.Equal(t, 1, js.Flush())
+	assert.Equal(t, 1, calls)
+
+	consumer.Stop()
+	assert.ErrorIs(t, consumer.Check(context.Background()), errNotConsuming)
+}
+
+func TestConsumer_PauseIsSharedByProcesses(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+
+	js, consumer := newTestConsumer(t, func(context.Context, interface{}) (interface{}, error) {
+		calls++
+
+		return nil, nil
+	})
+
+	assert.NoError(t, consumer.Pause(ctx))
+
+	// another process, or this one restarted, shares the durable
+	stream, err := js.Stream(ctx, "listing_view_event")
+	assert.NoError(t, err)
+
+	other, err := NewSubscriber(ctx, stream, "listing_view_user_created", "user.created",
+		0, func(context.Context, interface{}) (interface{}, error) {
+			calls++
+
+			return nil, nil
+		}, NewDecoder[userCreated](), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, other.Start(ctx))
+
+	publishTestMsg(t, js, "user.created", `{"id": 42}`, "")
+	assert.Equal(t, 0, js.Flush())
+
+	status, err := other.Status(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, status.Paused)
+	assert.Greater(t, status.PausedUntil, time.Now().UnixMicro())
+
+	assert.NoError(t, other.Resume(ctx))
+	assert.Equal(t, 1, js.Flush())
+	assert.Equal(t, 1, calls)
+
+	status, err = other.Status(ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, status.Paused)
+	assert.Zero(t, status.PausedUntil)
+}
+
+func TestConsumer_PauseHandlesBufferedMessages(t *testing.T) {
+	var requests []userCreated
+
+	js, consumer := newTestConsumer(t, func(_ context.Context, request interface{}) (interface{}, error) {
+		requests = append(requests, *request.(*userCreated))
+
+		return nil, nil
+	})
+
+	publishTestMsg(t, js, "user.created", `{"id": 1}`, "")
+	publishTestMsg(t, js, "user.created", `{"id": 2}`, "")
+	publishTestMsg(t, js, "user.created", `{"id": 3}`, "")
+
+	// the client pulled two messages ahead of the handler when pausing
+	assert.Equal(t, 2, js.Prefetch(2))
+	assert.NoError(t, consumer.Pause(context.Background()))
+	assert.Equal(t, []userCreated{{ID: 1}, {ID: 2}}, requests)
+
+	status, err := consumer.Status(context.Background(), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status.NumAckPending)
+	assert.Equal(t, uint64(1), status.NumPending)
+
+	assert.NoError(t, consumer.Resume(context.Background()))
+	assert.Equal(t, 1, js.Flush())
+	assert.Equal(t, []userCreated{{ID: 1}, {ID: 2}, {ID: 3}}, requests)
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/asyncapi"
+	"github.com/spf13/cobra"
+)
+
+var asyncapiValidate []string
+
+var asyncapiCmd = &cobra.Command{
+	Use:   "asyncapi",
+	Short: "Print the AsyncAPI document of the events of the service",
+	Long: "Print the AsyncAPI document generated from the event types of the service.\n" +
+		"With --validate the payloads are also checked against the documents of the other services,\n" +
+		"the command fails when a consumer can't decode what a producer sends.",
+	Example: "  app asyncapi > ../docs/user-service/asyncapi.json\n" +
+		"  app asyncapi --validate ../docs/listing-view-service/asyncapi.json",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		doc := model.EventCatalog.Document()
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("encode AsyncAPI document: %w", err)
+		}
+
+		if len(asyncapiValidate) == 0 {
+			return nil
+		}
+
+		return validateAsyncAPI(cmd, doc, asyncapiValidate)
+	},
+}
+
+func init() { //nolint:gochecknoinits
+	asyncapiCmd.Flags().StringArrayVar(&asyncapiValidate, "validate", nil,
+		"AsyncAPI document of another service to check the payloads against, repeatable")
+}
+
+func validateAsyncAPI(cmd *cobra.Command, doc asyncapi.Document, paths []string) error {
+	docs := []asyncapi.Document{doc}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read AsyncAPI document: %w", err)
+		}
+
+		var other asyncapi.Document
+		if err := json.Unmarshal(data, &other); err != nil {
+			return fmt.Errorf("parse AsyncAPI document %s: %w", path, err)
+		}
+
+		docs = append(docs, other)
+	}
+
+	breaking := 0
+
+	for _, issue := range asyncapi.Validate(docs...) {
+		fmt.Fprintln(cmd.ErrOrStderr(), issue)
+
+		if issue.Breaking {
+			breaking++
+		}
+	}
+
+	if breaking > 0 {
+		return fmt.Errorf("%d breaking event payload changes", breaking)
+	}
+
+	return nil
+}
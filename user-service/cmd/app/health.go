@@ -0,0 +1,21 @@
+package app
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/health"
+)
+
+// drainReadiness fails the readiness probe and gives the load balancers delay
+// to stop routing requests before the server is shut down.
+func drainReadiness(checks *health.Health, delay time.Duration) {
+	checks.Shutdown()
+
+	if delay <= 0 {
+		return
+	}
+
+	slog.Info("failing readiness probe before shutdown", slog.Duration("delay", delay))
+	time.Sleep(delay)
+}
@@ -14,35 +14,46 @@ import (
 
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/endpoint"
-	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/repository"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/router"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/service"
-	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/db"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/health"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/lang"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/logger"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/metrics"
 	natstransport "github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/transport/nats"
-	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/spf13/cobra"
 )
 
 var timeout = 30 * time.Second
 
+var (
+	auditStreamName = model.AuditStreamName
+	auditSubjects   = model.AuditSubjects
+)
+
 var httpServerCmd = &cobra.Command{
 	Use:   "http",
 	Short: "Serve incoming requests from REST HTTP/JSON API",
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
 		slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
 		cfg := config.MustInitConfig(cfgFilePath)
+		applyStorageFlag(&cfg)
 
 		logger.InitStructuredLogger(cfg.LogLevel)
+		reloadLogLevel()
+
+		shutdownTracing := initTracing(cfg)
+		defer shutdownTracing()
 
-		runHTTPServer(cfg)
+		runHTTPServer(cmd.Context(), cfg)
 	},
 }
 
-func runHTTPServer(cfg config.Config) {
-	ctx, cancel := context.WithCancel(context.Background())
+// runHTTPServer serves until a signal is received or parent is done.
+func runHTTPServer(parent context.Context, cfg config.Config) {
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 
 	var waitGroup sync.WaitGroup
@@ -59,13 +70,16 @@ func runHTTPServer(cfg config.Config) {
 
 	sigChannel := make(chan os.Signal, 1)
 	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigChannel)
 
 	select {
 	case sig := <-sigChannel:
 		cancel()
 		slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
 	case <-ctx.Done():
-		slog.ErrorContext(ctx, "failed to start HTTP server")
+		if parent.Err() == nil {
+			slog.ErrorContext(ctx, "failed to start HTTP server")
+		}
 	}
 
 	waitGroup.Wait()
@@ -87,12 +101,12 @@ func startHTTPServer(ctx context.Context, cfg config.Config,
 		}
 	}()
 
-	if cfg.HTTP.PprofEnabled {
+	if cfg.HTTP.PprofEnabled || cfg.Metrics.Enabled {
 		waitGroup.Add(1)
 
 		go func() {
 			defer waitGroup.Done()
-			startPprof(ctx, cfg)
+			startInternalServer(ctx, cfg)
 		}()
 	}
 
@@ -103,23 +117,64 @@ func startMainApp(ctx context.Context, cfg config.Config) error {
 	lang.SetSupportedLanguages(cfg.Locales.SupportedLanguages)
 	lang.SetBasePath(cfg.Locales.BasePath)
 
-	natsConn, err := nats.Connect(cfg.Nats.URL)
+	natsConn, err := natstransport.Connect(natstransport.ConnOptions{
+		URL:              cfg.NATS.URL,
+		Name:             "user-service",
+		MaxReconnects:    cfg.NATS.MaxReconnects,
+		ReconnectWait:    cfg.NATS.ReconnectWait,
+		ReconnectBufSize: cfg.NATS.ReconnectBufferSize,
+		Timeout:          cfg.NATS.ConnectTimeout,
+	})
 	if err != nil {
 		slog.Error("failed to connect to NATS", slog.String("error", err.Error()))
 		return err
 	}
 
-	js, err := jetstream.New(natsConn)
+	// the acks of the publications are awaited asynchronously, so a slow or
+	// unreachable NATS fails the requests instead of piling them up
+	js, err := jetstream.New(natsConn,
+		jetstream.WithPublishAsyncMaxPending(cfg.NATS.PublishMaxPending),
+		jetstream.WithPublishAsyncTimeout(cfg.NATS.PublishTimeout))
 	if err != nil {
 		slog.Error("failed to create JetStream context", slog.String("error", err.Error()))
 		return err
 	}
 
-	endpts := makeEndpoints(cfg, js)
+	// audit events are kept apart from the domain events, for the consumers
+	// that only follow the audit trail
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     auditStreamName,
+		Subjects: []string{auditSubjects},
+	})
+	if err != nil {
+		slog.Error("failed to create audit stream", slog.String("error", err.Error()))
+		return err
+	}
+
+	checks := health.New(cfg.Health.CheckTimeout)
+
+	repos, err := makeRepositories(ctx, cfg, checks)
+	if err != nil {
+		slog.Error("failed to open storage", slog.String("error", err.Error()))
+		return err
+	}
+
+	if cfg.DB.AutoMigrate && repos.db != nil {
+		if err := autoMigrate(ctx, repos.db); err != nil {
+			slog.Error("failed to migrate database", slog.String("error", err.Error()))
+			return err
+		}
+	}
+
+	endpts := makeEndpoints(repos, js, cfg)
+
+	checks.Register("nats", health.NATSChecker(natsConn))
+	checks.Register("jetstream", health.JetStreamChecker(js))
 
 	router := router.MakeHTTPRouter(
 		endpts,
 		cfg,
+		checks,
 	)
 
 	server := &http.Server{
@@ -139,6 +194,8 @@ func startMainApp(ctx context.Context, cfg config.Config) error {
 
 	<-ctx.Done()
 
+	drainReadiness(checks, cfg.Health.ShutdownDelay)
+
 	natsConn.Close()
 	// shutdown ctx
 	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
@@ -153,35 +210,49 @@ func startMainApp(ctx context.Context, cfg config.Config) error {
 	return nil
 }
 
-func makeEndpoints(cfg config.Config, js jetstream.JetStream) endpoint.Endpoint {
-	dbConn := db.InitDB(cfg)
-
-	// init all repo
-	userRepository := repository.NewUserRepository(dbConn)
-
+func makeEndpoints(repos repositories, js jetstream.JetStream, cfg config.Config) endpoint.Endpoint {
 	// nats publisher
-	publisher := natstransport.NewPublisher(js, natstransport.JSONEncoder)
+	publisher := natstransport.NewPublisher(js, natstransport.JSONEncoder,
+		natstransport.WithPublishTimeout(cfg.NATS.PublishTimeout),
+		natstransport.WithPublishRetries(cfg.NATS.PublishRetries))
+
+	auditSvc := service.NewAuditService(repos.audit, publisher)
 
 	return endpoint.Endpoint{
-		User: makeUserEndpoints(userRepository, publisher, cfg),
+		User:     makeUserEndpoints(repos.user, publisher, auditSvc),
+		LogLevel: endpoint.NewLogLevelEndpoint(service.NewLogLevelService(logger.DefaultLevel)),
+		Audit:    endpoint.NewAuditEndpoint(auditSvc),
 	}
 }
 
-func makeUserEndpoints(userRepository *repository.UserRepository,
-	publisher *natstransport.Publisher, cfg config.Config) endpoint.User {
-	userSvc := service.NewUserService(userRepository, publisher)
+func makeUserEndpoints(userRepository service.UserRepository,
+	publisher *natstransport.Publisher, auditSvc *service.AuditService) endpoint.User {
+	userSvc := service.NewUserService(userRepository, publisher, auditSvc)
 
 	return endpoint.NewUserEndpoint(userSvc)
 }
 
-func startPprof(ctx context.Context, cfg config.Config) {
-	// manually register pprof handlers with custom path.
-	http.HandleFunc("/internal/pprof/", pprof.Index)
-	http.HandleFunc("/internal/pprof/cmdline", pprof.Cmdline)
-	http.HandleFunc("/internal/pprof/profile", pprof.Profile)
-	http.HandleFunc("/internal/pprof/symbol", pprof.Symbol)
-	http.HandleFunc("/internal/pprof/trace", pprof.Trace)
-	slog.Info("running pprof...", slog.Int("port", cfg.HTTP.PprofPort))
+// startInternalServer serves pprof and the Prometheus metrics on the
+// internal port.
+func startInternalServer(ctx context.Context, cfg config.Config) {
+	if cfg.HTTP.PprofEnabled {
+		// manually register pprof handlers with custom path.
+		http.HandleFunc("/internal/pprof/", pprof.Index)
+		http.HandleFunc("/internal/pprof/cmdline", pprof.Cmdline)
+		http.HandleFunc("/internal/pprof/profile", pprof.Profile)
+		http.HandleFunc("/internal/pprof/symbol", pprof.Symbol)
+		http.HandleFunc("/internal/pprof/trace", pprof.Trace)
+	}
+
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Addr != "" {
+			go startMetricsServer(ctx, cfg)
+		} else {
+			http.Handle("/metrics", metrics.Default.Handler())
+		}
+	}
+
+	slog.Info("running internal server...", slog.Int("port", cfg.HTTP.PprofPort))
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf("localhost:%d", cfg.HTTP.PprofPort),
@@ -191,7 +262,7 @@ func startPprof(ctx context.Context, cfg config.Config) {
 	// limit access by binding port to localhost only.
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("pprof server error", slog.String("error", err.Error()))
+			slog.Error("internal server error", slog.String("error", err.Error()))
 		}
 	}()
 
@@ -201,8 +272,41 @@ func startPprof(ctx context.Context, cfg config.Config) {
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		slog.Error("failed to shutdown pprof server", slog.String("error", err.Error()))
+		slog.Error("failed to shutdown internal server", slog.String("error", err.Error()))
+	}
+
+	slog.Info("internal server stopped")
+}
+
+// startMetricsServer serves the Prometheus metrics on METRICS_ADDR, unlike
+// the internal port it is reachable from other hosts, pprof isn't served.
+func startMetricsServer(ctx context.Context, cfg config.Config) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+
+	slog.Info("running metrics server...", slog.String("addr", cfg.Metrics.Addr))
+
+	server := &http.Server{
+		Addr:              cfg.Metrics.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.HTTP.Timeout,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server error", slog.String("error", err.Error()))
+		}
+	}()
+
+	<-ctx.Done()
+
+	// ctx is done, the shutdown gets its own deadline
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shutdown metrics server", slog.String("error", err.Error()))
 	}
 
-	slog.Info("pprof server stopped")
+	slog.Info("metrics server stopped")
 }
@@ -0,0 +1,14 @@
+package app
+
+import (
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/logger"
+)
+
+// reloadLogLevel applies the LOG_LEVEL changes of the config file, an
+// override set through the admin API keeps precedence until it reverts.
+func reloadLogLevel() {
+	config.OnChange(func(cfg config.Config) {
+		logger.DefaultLevel.SetConfigured(cfg.LogLevel.Level())
+	})
+}
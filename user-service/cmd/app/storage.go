@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/config"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/repository"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/service"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/db"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/health"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/memdb"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/metrics"
+)
+
+// storageFlag overrides the STORAGE config key when set.
+var storageFlag string
+
+type repositories struct {
+	user  service.UserRepository
+	audit service.AuditRepository
+	// db is the Postgres database, nil with the memory storage.
+	db *sql.DB
+}
+
+// applyStorageFlag gives --storage precedence over the config file.
+func applyStorageFlag(cfg *config.Config) {
+	if storageFlag != "" {
+		cfg.DB.Storage = storageFlag
+	}
+}
+
+// makeRepositories opens the storage of cfg, a Postgres storage is waited for
+// until it answers and then checked by the readiness probe.
+func makeRepositories(ctx context.Context, cfg config.Config, checks *health.Health) (repositories, error) {
+	switch cfg.DB.Storage {
+	case config.StoragePostgres:
+		dbConn, err := db.Connect(ctx, cfg)
+		if err != nil {
+			return repositories{}, err //nolint:wrapcheck
+		}
+
+		db.RegisterMetrics(metrics.Default, dbConn)
+		checks.Register("postgres", health.DBChecker(dbConn))
+
+		return repositories{
+			user:  repository.NewUserRepository(dbConn, cfg.DB.SlowQueryThreshold),
+			audit: repository.NewAuditRepository(dbConn, cfg.DB.SlowQueryThreshold),
+			db:    dbConn,
+		}, nil
+	case config.StorageMemory:
+		slog.Warn("data is stored in memory and lost when the process stops")
+
+		memDB := memdb.New()
+
+		return repositories{
+			user:  repository.NewMemoryUserRepository(memDB),
+			audit: repository.NewMemoryAuditRepository(memDB),
+		}, nil
+	default:
+		return repositories{}, fmt.Errorf("unknown storage %q, want %s or %s",
+			cfg.DB.Storage, config.StoragePostgres, config.StorageMemory)
+	}
+}
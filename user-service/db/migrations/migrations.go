@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations of the service, so the binary
+// migrates its database without the files nor the migrate CLI.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,21 @@
+//go:build unit
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS(t *testing.T) {
+	migrations, err := migrate.Load(FS)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		assert.Equal(t, uint64(i+1), migration.Version, "migration versions must be sequential")
+		assert.NotEmpty(t, migration.Down, "migration %d_%s has no down file", migration.Version, migration.Name)
+	}
+}
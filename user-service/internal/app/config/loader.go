@@ -4,15 +4,54 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/configcheck"
 	"github.com/spf13/viper"
 )
 
-// MustInitConfig initializes configuration from .env file and returns config structure.
+// MustInitConfig initializes configuration from .env file and returns config
+// structure, it panics listing every problem when the config is invalid.
 func MustInitConfig(configFile string) Config {
-	var (
-		vpr = viper.New()
-		cfg Config
-	)
+	vpr, err := readConfig(configFile)
+	if err != nil {
+		slog.Error("cannot read local config file", slog.String("error", err.Error()))
+
+		panic(err)
+	}
+
+	cfg, report := decode(vpr)
+
+	for _, problem := range report.Warnings {
+		slog.Warn("config warning", slog.String("key", problem.Key), slog.String("problem", problem.Message))
+	}
+
+	if err := report.Err(); err != nil {
+		slog.Error("invalid config, run the config check command for details", slog.String("error", err.Error()))
+
+		panic(err)
+	}
+
+	watch(vpr, cfg)
+
+	slog.Debug("config loaded", slog.Any("config", cfg))
+
+	return cfg
+}
+
+// Load reads the config like MustInitConfig, without watching it, and reports
+// its problems instead of panicking.
+func Load(configFile string) (Config, configcheck.Report, error) {
+	vpr, err := readConfig(configFile)
+	if err != nil {
+		return Config{}, configcheck.Report{}, err
+	}
+
+	cfg, report := decode(vpr)
+
+	return cfg, report, nil
+}
+
+func readConfig(configFile string) (*viper.Viper, error) {
+	vpr := viper.New()
 
 	vpr.SetConfigFile(configFile)
 	vpr.SetConfigType("env")
@@ -20,22 +59,37 @@ func MustInitConfig(configFile string) Config {
 
 	// default values
 	vpr.SetDefault("LOG_LEVEL", "info")
+	vpr.SetDefault("STORAGE", StoragePostgres)
+	vpr.SetDefault("DB_CONNECT_TIMEOUT", "30s")
+	vpr.SetDefault("DB_CONNECT_BACKOFF", "500ms")
+	vpr.SetDefault("LOG_BODY_LIMIT", 64<<10)
+	vpr.SetDefault("LOG_SUCCESS_SAMPLE_RATE", 1)
+	vpr.SetDefault("NATS_MAX_RECONNECTS", 60)
+	vpr.SetDefault("NATS_RECONNECT_WAIT", "2s")
+	vpr.SetDefault("NATS_RECONNECT_BUFFER_SIZE", -1)
+	vpr.SetDefault("NATS_CONNECT_TIMEOUT", "2s")
+	vpr.SetDefault("NATS_PUBLISH_TIMEOUT", "5s")
+	vpr.SetDefault("NATS_PUBLISH_RETRIES", 2)
+	vpr.SetDefault("NATS_PUBLISH_MAX_PENDING", 256)
 
 	if err := vpr.ReadInConfig(); err != nil {
-		slog.Error("cannot read local config file", slog.String("error", err.Error()))
-
-		panic(err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	if err := vpr.Unmarshal(&cfg); err != nil {
-		slog.Error("cannot unmarshal config file", slog.String("error", err.Error()))
+	return vpr, nil
+}
 
-		panic(err)
-	}
+// decode unmarshals the config, reads the secret files it names and checks it
+// against the validate tags of Config, the keys of the file matching no field
+// are reported as warnings.
+func decode(vpr *viper.Viper) (Config, configcheck.Report) {
+	var cfg Config
 
-	vpr.WatchConfig()
+	err := vpr.Unmarshal(&cfg)
+	problems := configcheck.ReadSecretFiles(&cfg, vpr.GetString)
 
-	slog.Debug("cfg", slog.String("config", fmt.Sprintf("%v", cfg)))
+	report := configcheck.Check(cfg, vpr.AllKeys(), err)
+	report.Errors = append(problems, report.Errors...)
 
-	return cfg
+	return cfg, report
 }
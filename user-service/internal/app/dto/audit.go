@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type GetAuditLogsRequest struct {
+	Entity     string `json:"entity"`
+	Actor      string `json:"actor"`
+	PageNumber int    `json:"page_number" validate:"required,min=1"`
+	PageSize   int    `json:"page_size" validate:"required,min=1,max=100"`
+}
+
+func (r *GetAuditLogsRequest) Bind(req *http.Request) error {
+	var err error
+
+	query := req.URL.Query()
+
+	r.Entity = query.Get("entity")
+	r.Actor = query.Get("actor")
+
+	r.PageNumber = 1
+	if pageNumberStr := query.Get("page_num"); pageNumberStr != "" {
+		r.PageNumber, err = strconv.Atoi(pageNumberStr)
+		if err != nil {
+			return NewInvalidRequestError(fmt.Errorf("invalid page number: %w", err))
+		}
+	}
+
+	r.PageSize = 10
+	if pageSizeStr := query.Get("page_size"); pageSizeStr != "" {
+		r.PageSize, err = strconv.Atoi(pageSizeStr)
+		if err != nil {
+			return NewInvalidRequestError(fmt.Errorf("invalid page size: %w", err))
+		}
+	}
+
+	if err := validate.Struct(r); err != nil {
+		return NewInvalidRequestError(err)
+	}
+
+	return nil
+}
+
+type AuditChangeResponse struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditLogResponse struct {
+	ID        int64                          `json:"id"`
+	Actor     string                         `json:"actor"`
+	Action    string                         `json:"action"`
+	Entity    string                         `json:"entity"`
+	EntityID  string                         `json:"entity_id"`
+	Changes   map[string]AuditChangeResponse `json:"changes"`
+	RequestID string                         `json:"request_id"`
+	CreatedAt int64                          `json:"created_at"`
+}
+
+type GetAuditLogsResponse struct {
+	Result    bool               `json:"result"`
+	AuditLogs []AuditLogResponse `json:"audit_logs"`
+}
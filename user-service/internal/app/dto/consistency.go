@@ -0,0 +1,12 @@
+package dto
+
+import "fmt"
+
+// ConsistencyTokenHeader returns the position of a write in the event stream,
+// readers send it back to wait until the projections have applied the write.
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// NewConsistencyToken formats the token as <subject>:<stream sequence>.
+func NewConsistencyToken(subject string, sequence uint64) string {
+	return fmt.Sprintf("%s:%d", subject, sequence)
+}
@@ -84,8 +84,20 @@ type UserResponse struct {
 }
 
 type CreateUserResponse struct {
-	Result bool         `json:"result"`
-	User   UserResponse `json:"user"`
+	Result           bool         `json:"result"`
+	User             UserResponse `json:"user"`
+	ConsistencyToken string       `json:"-"`
+}
+
+// Headers implements kithttp.Headerer.
+func (r CreateUserResponse) Headers() http.Header {
+	headers := http.Header{}
+
+	if r.ConsistencyToken != "" {
+		headers.Set(ConsistencyTokenHeader, r.ConsistencyToken)
+	}
+
+	return headers
 }
 
 type GetUserByIDResponse struct {
@@ -0,0 +1,35 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+)
+
+type AuditService interface {
+	GetAuditLogs(ctx context.Context, req dto.GetAuditLogsRequest) (dto.GetAuditLogsResponse, error)
+}
+
+func NewAuditEndpoint(auditService AuditService) Audit {
+	return Audit{
+		GetAll: makeGetAuditLogsEndpoint(auditService),
+	}
+}
+
+func makeGetAuditLogsEndpoint(auditService AuditService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetAuditLogsRequest)
+		if !ok {
+			return nil, fmt.Errorf("invalid request type: %w", ErrInvalidType)
+		}
+
+		res, err := auditService.GetAuditLogs(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("audit service: %w", err)
+		}
+
+		return res, nil
+	}
+}
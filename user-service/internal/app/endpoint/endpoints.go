@@ -20,6 +20,18 @@ type User struct {
 	GetUserByID endpoint.Endpoint
 }
 
+type LogLevel struct {
+	Get   endpoint.Endpoint
+	Set   endpoint.Endpoint
+	Reset endpoint.Endpoint
+}
+
+type Audit struct {
+	GetAll endpoint.Endpoint
+}
+
 type Endpoint struct {
 	User
+	LogLevel
+	Audit
 }
@@ -0,0 +1,69 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+)
+
+type LogLevelService interface {
+	GetLogLevel(ctx context.Context) (dto.LogLevelResponse, error)
+	SetLogLevel(ctx context.Context, req dto.SetLogLevelRequest) (dto.LogLevelResponse, error)
+	ResetLogLevel(ctx context.Context) (dto.LogLevelResponse, error)
+}
+
+func NewLogLevelEndpoint(svc LogLevelService) LogLevel {
+	return LogLevel{
+		Get:   MakeGetLogLevelEndpoint(svc),
+		Set:   MakeSetLogLevelEndpoint(svc),
+		Reset: MakeResetLogLevelEndpoint(svc),
+	}
+}
+
+func MakeGetLogLevelEndpoint(svc LogLevelService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if _, ok := request.(*dto.GetLogLevelRequest); !ok {
+			return nil, fmt.Errorf("log level service: %w", ErrInvalidType)
+		}
+
+		res, err := svc.GetLogLevel(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("log level service: %w", err)
+		}
+
+		return res, nil
+	}
+}
+
+func MakeSetLogLevelEndpoint(svc LogLevelService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.SetLogLevelRequest)
+		if !ok {
+			return nil, fmt.Errorf("log level service: %w", ErrInvalidType)
+		}
+
+		res, err := svc.SetLogLevel(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("log level service: %w", err)
+		}
+
+		return res, nil
+	}
+}
+
+func MakeResetLogLevelEndpoint(svc LogLevelService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if _, ok := request.(*dto.ResetLogLevelRequest); !ok {
+			return nil, fmt.Errorf("log level service: %w", ErrInvalidType)
+		}
+
+		res, err := svc.ResetLogLevel(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("log level service: %w", err)
+		}
+
+		return res, nil
+	}
+}
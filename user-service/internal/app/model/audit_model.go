@@ -0,0 +1,33 @@
+package model
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+
+	// AuditActorAnonymous is the actor of the requests that don't name one.
+	AuditActorAnonymous = "anonymous"
+
+	// AuditEventPrefix is followed by the entity and the action, like
+	// audit.user.create.
+	AuditEventPrefix = "audit."
+)
+
+// AuditLog records a change of an entity, rows are never updated or deleted.
+type AuditLog struct {
+	ID        int64                  `json:"id"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	Entity    string                 `json:"entity"`
+	EntityID  string                 `json:"entity_id"`
+	Changes   map[string]AuditChange `json:"changes"`
+	RequestID string                 `json:"request_id"`
+	CreatedAt int64                  `json:"created_at"`
+}
+
+// AuditChange holds the values of a field before and after the change, nil
+// when the entity didn't exist before or doesn't exist after.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
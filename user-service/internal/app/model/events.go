@@ -0,0 +1,37 @@
+package model
+
+import "github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/asyncapi"
+
+const (
+	// AuditStreamName keeps the audit events apart from the domain events.
+	AuditStreamName = "audit"
+	AuditSubjects   = AuditEventPrefix + ">"
+
+	// listingViewStreamName is created by listing-view-service, the consumer
+	// of the user events.
+	listingViewStreamName = "listing_view_event"
+)
+
+// EventCatalog documents the events published by the service, generated as
+// AsyncAPI by the asyncapi command and served on /docs/asyncapi.json.
+var EventCatalog = asyncapi.Catalog{
+	Service:     "user-service",
+	Version:     "1.0.0",
+	Description: "Manages the users and publishes their changes.",
+	Publishes: []asyncapi.Event{
+		{
+			Subject:     UserCreatedEvent,
+			Stream:      listingViewStreamName,
+			Description: "A user was created.",
+			Message:     "UserCreated",
+			Payload:     User{},
+		},
+		{
+			Subject:     AuditEventPrefix + "user." + AuditActionCreate,
+			Stream:      AuditStreamName,
+			Description: "The audit entry of a created user.",
+			Message:     "AuditLog",
+			Payload:     AuditLog{},
+		},
+	},
+}
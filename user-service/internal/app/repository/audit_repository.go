@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+	errorMapper
+	queryTimer
+}
+
+func NewAuditRepository(db *sql.DB, slowQueryThreshold time.Duration) *AuditRepository {
+	return &AuditRepository{
+		db:         db,
+		queryTimer: queryTimer{slowQueryThreshold: slowQueryThreshold},
+	}
+}
+
+// GetAll returns the most recent entries first, an empty entity or actor
+// matches every entry.
+func (r *AuditRepository) GetAll(ctx context.Context, entity, actor string, limit, offset int) ([]model.AuditLog, error) {
+	ctx, endQuery := r.startQuery(ctx, "AuditRepository.GetAll", entity, actor, limit, offset)
+	defer endQuery()
+
+	query := `
+		SELECT id, actor, action, entity, entity_id, changes, request_id, created_at
+		FROM audit_log
+		WHERE ($1 = '' OR entity = $1) AND ($2 = '' OR actor = $2)
+		ORDER BY id DESC
+		LIMIT $3
+		OFFSET $4
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, entity, actor, limit, offset)
+	if err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	defer rows.Close()
+
+	entries := []model.AuditLog{}
+	for rows.Next() {
+		var (
+			entry   model.AuditLog
+			changes []byte
+		)
+
+		err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.Entity, &entry.EntityID,
+			&changes, &entry.RequestID, &entry.CreatedAt)
+		if err != nil {
+			return nil, r.errorMapper.mapError(err)
+		}
+
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			return nil, fmt.Errorf("decode audit changes: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.errorMapper.mapError(err)
+	}
+
+	return entries, nil
+}
+
+// CreateTx writes entry in the transaction of the change it records, so
+// neither is saved without the other.
+func (r *AuditRepository) CreateTx(ctx context.Context, tx *sql.Tx, entry *model.AuditLog) error {
+	ctx, endQuery := r.startQuery(ctx, "AuditRepository.CreateTx", entry.Actor, entry.Action, entry.Entity,
+		entry.EntityID, entry.RequestID, entry.CreatedAt)
+	defer endQuery()
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("encode audit changes: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_log (actor, action, entity, entity_id, changes, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return r.errorMapper.mapError(err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, entry.Actor, entry.Action, entry.Entity, entry.EntityID,
+		changes, entry.RequestID, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return r.errorMapper.mapError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/memdb"
+)
+
+// MemoryAuditRepository keeps the audit log in memory, it must share the DB of
+// the repositories of the audited entities to be written in their
+// transactions.
+type MemoryAuditRepository struct {
+	entries *memdb.Table[int64, model.AuditLog]
+}
+
+func NewMemoryAuditRepository(db *memdb.DB) *MemoryAuditRepository {
+	return &MemoryAuditRepository{
+		entries: memdb.NewTable[int64, model.AuditLog](db),
+	}
+}
+
+func (r *MemoryAuditRepository) GetAll(ctx context.Context, entity, actor string,
+	limit, offset int,
+) ([]model.AuditLog, error) {
+	entries := r.entries.Select(ctx,
+		func(entry model.AuditLog) bool {
+			return (entity == "" || entry.Entity == entity) && (actor == "" || entry.Actor == actor)
+		},
+		func(a, b model.AuditLog) bool {
+			return a.ID > b.ID
+		},
+	)
+
+	return memdb.Page(entries, limit, offset), nil
+}
+
+func (r *MemoryAuditRepository) CreateTx(ctx context.Context, _ *sql.Tx, entry *model.AuditLog) error {
+	entry.ID = r.entries.NextID()
+	r.entries.Put(ctx, entry.ID, *entry)
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/memdb"
+)
+
+// MemoryUserRepository keeps the users in memory, for running the service
+// without Postgres. The data is lost when the process stops.
+type MemoryUserRepository struct {
+	users *memdb.Table[int64, model.User]
+	memoryTransactable
+}
+
+func NewMemoryUserRepository(db *memdb.DB) *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: memdb.NewTable[int64, model.User](db),
+		memoryTransactable: memoryTransactable{
+			db: db,
+		},
+	}
+}
+
+func (r *MemoryUserRepository) GetAll(ctx context.Context, limit, offset int) ([]model.User, error) {
+	users := r.users.Select(ctx, nil, func(a, b model.User) bool {
+		return a.CreatedAt > b.CreatedAt
+	})
+
+	return memdb.Page(users, limit, offset), nil
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id int64) (model.User, error) {
+	user, ok := r.users.Get(ctx, id)
+	if !ok {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "user",
+		}
+
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *MemoryUserRepository) CreateTx(ctx context.Context, _ *sql.Tx, user *model.User) error {
+	user.ID = r.users.NextID()
+	r.users.Put(ctx, user.ID, *user)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/memdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	ctx := context.Background()
+	db := memdb.New()
+	users := NewMemoryUserRepository(db)
+	audit := NewMemoryAuditRepository(db)
+
+	for i, name := range []string{"John", "Jane", "Joe"} {
+		err := users.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			return users.CreateTx(ctx, tx, &model.User{Name: name, CreatedAt: int64(i)})
+		})
+		assert.NoError(t, err)
+	}
+
+	t.Run("get all latest first", func(t *testing.T) {
+		got, err := users.GetAll(ctx, 2, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.User{{ID: 3, Name: "Joe", CreatedAt: 2}, {ID: 2, Name: "Jane", CreatedAt: 1}}, got)
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		got, err := users.GetByID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "John", got.Name)
+
+		_, err = users.GetByID(ctx, 42)
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("rollback undoes the user and its audit entry", func(t *testing.T) {
+		err := users.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			user := &model.User{Name: "Jim"}
+			if err := users.CreateTx(ctx, tx, user); err != nil {
+				return err
+			}
+
+			if err := audit.CreateTx(ctx, tx, &model.AuditLog{Entity: "user", Action: model.AuditActionCreate}); err != nil {
+				return err
+			}
+
+			return errors.New("publish failed")
+		})
+		assert.Error(t, err)
+
+		all, _ := users.GetAll(ctx, 10, 0)
+		assert.Len(t, all, 3)
+
+		entries, _ := audit.GetAll(ctx, "user", "", 10, 0)
+		assert.Empty(t, entries)
+	})
+}
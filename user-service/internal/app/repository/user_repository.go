@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/exception"
@@ -13,16 +14,21 @@ type UserRepository struct {
 	db *sql.DB
 	errorMapper
 	transactable
+	queryTimer
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db *sql.DB, slowQueryThreshold time.Duration) *UserRepository {
 	return &UserRepository{
 		db:           db,
 		transactable: transactable{db: db},
+		queryTimer:   queryTimer{slowQueryThreshold: slowQueryThreshold},
 	}
 }
 
 func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]model.User, error) {
+	ctx, endQuery := r.startQuery(ctx, "UserRepository.GetAll", limit, offset)
+	defer endQuery()
+
 	query := `
 		SELECT id, name, created_at, updated_at
 		FROM users
@@ -59,6 +65,9 @@ func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]model
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (model.User, error) {
+	ctx, endQuery := r.startQuery(ctx, "UserRepository.GetByID", id)
+	defer endQuery()
+
 	query := `
 		SELECT id, name, created_at, updated_at
 		FROM users
@@ -91,6 +100,9 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (model.User, err
 }
 
 func (r *UserRepository) CreateTx(ctx context.Context, tx *sql.Tx, user *model.User) error {
+	ctx, endQuery := r.startQuery(ctx, "UserRepository.CreateTx", user.Name, user.CreatedAt, user.UpdatedAt)
+	defer endQuery()
+
 	query := `
 		INSERT INTO users (name, created_at, updated_at)
 		VALUES ($1, $2, $3)
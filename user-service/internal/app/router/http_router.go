@@ -2,13 +2,16 @@ package router
 
 import (
 	"log/slog"
-	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/asyncapi"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/health"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/metrics"
 	httptransport "github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/transport/http"
 )
 
@@ -16,18 +19,39 @@ import (
 func MakeHTTPRouter(
 	endpts endpoint.Endpoint,
 	cfg config.Config,
+	checks *health.Health,
 ) *chi.Mux {
 	// Initialize Router
 	router := chi.NewRouter()
 
-	router.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusNoContent)
+	// /health is kept for the probes configured before /health/live existed
+	router.Get("/health", checks.LiveHandler())
+	router.Get("/health/live", checks.LiveHandler())
+	router.Get("/health/ready", checks.ReadyHandler())
+
+	router.Get("/docs/asyncapi.json", asyncapi.Handler(model.EventCatalog.Document()))
+
+	corsMiddleware := httptransport.NewCORS(cfg.HTTP.AllowedOrigin)
+	serviceTokens := httptransport.NewServiceTokens(cfg.ServiceTokens)
+	config.OnChange(func(cfg config.Config) {
+		corsMiddleware.SetAllowedOrigins(cfg.HTTP.AllowedOrigin)
+		serviceTokens.SetTokens(cfg.ServiceTokens)
 	})
 
 	router.Route("/", func(router chi.Router) {
 		router.Use(
-			httptransport.LoggingMiddleware(slog.Default()),
-			httptransport.CORSMiddleware(cfg.HTTP.AllowedOrigin),
+			httptransport.HeaderMiddleware(),
+			serviceTokens.ActorHandler,
+			httptransport.TracingMiddleware(),
+			httptransport.SlowRequestMiddleware(slog.Default(), cfg.RequestTimeThreshold),
+			httptransport.LoggingMiddleware(slog.Default(),
+				httptransport.WithBodyLimit(cfg.Logging.BodyLimit),
+				httptransport.WithRedactedFields(cfg.Logging.RedactFields...),
+				httptransport.WithContentTypes(cfg.Logging.ContentTypes...),
+				httptransport.WithSuccessSampleRate(cfg.Logging.SuccessSampleRate),
+			),
+			httptransport.MetricsMiddleware(metrics.Default),
+			corsMiddleware.Handler,
 			httptransport.Recoverer(slog.Default()),
 			render.SetContentType(render.ContentTypeJSON),
 		)
@@ -52,6 +76,36 @@ func MakeHTTPRouter(
 			))
 
 		})
+
+		router.Route("/admin", func(router chi.Router) {
+			router.Use(serviceTokens.Handler)
+
+			router.Get("/audit", httptransport.MakeHandlerFunc(
+				endpts.Audit.GetAll,
+				httptransport.DecodeRequest[dto.GetAuditLogsRequest],
+				httptransport.ResponseWithBody,
+			))
+
+			router.Route("/log-level", func(router chi.Router) {
+				router.Get("/", httptransport.MakeHandlerFunc(
+					endpts.LogLevel.Get,
+					httptransport.DecodeRequest[dto.GetLogLevelRequest],
+					httptransport.ResponseWithBody,
+				))
+
+				router.Put("/", httptransport.MakeHandlerFunc(
+					endpts.LogLevel.Set,
+					httptransport.DecodeRequest[dto.SetLogLevelRequest],
+					httptransport.ResponseWithBody,
+				))
+
+				router.Delete("/", httptransport.MakeHandlerFunc(
+					endpts.LogLevel.Reset,
+					httptransport.DecodeRequest[dto.ResetLogLevelRequest],
+					httptransport.ResponseWithBody,
+				))
+			})
+		})
 	})
 
 	return router
@@ -5,10 +5,12 @@ package router
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/config"
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/endpoint"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/health"
 )
 
 func TestConfigRoute(t *testing.T) {
@@ -27,6 +29,7 @@ func TestConfigRoute(t *testing.T) {
 			User: endpoint.User{},
 		},
 		cfg,
+		health.New(time.Second),
 	)
 
 	testCases := []struct {
@@ -41,6 +44,48 @@ func TestConfigRoute(t *testing.T) {
 			path:        "/health",
 			shouldMatch: true,
 		},
+		{
+			name:        "Liveness",
+			method:      http.MethodGet,
+			path:        "/health/live",
+			shouldMatch: true,
+		},
+		{
+			name:        "Readiness",
+			method:      http.MethodGet,
+			path:        "/health/ready",
+			shouldMatch: true,
+		},
+		{
+			name:        "AsyncAPI Document",
+			method:      http.MethodGet,
+			path:        "/docs/asyncapi.json",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Log Level",
+			method:      http.MethodGet,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Set Log Level",
+			method:      http.MethodPut,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Reset Log Level",
+			method:      http.MethodDelete,
+			path:        "/admin/log-level",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Audit Logs",
+			method:      http.MethodGet,
+			path:        "/admin/audit",
+			shouldMatch: true,
+		},
 		{
 			name:        "Create User",
 			method:      http.MethodPost,
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+)
+
+type AuditRepository interface {
+	GetAll(ctx context.Context, entity, actor string, limit, offset int) ([]model.AuditLog, error)
+	CreateTx(ctx context.Context, tx *sql.Tx, entry *model.AuditLog) error
+}
+
+type AuditService struct {
+	auditRepository AuditRepository
+	publisher       Publisher
+}
+
+func NewAuditService(auditRepository AuditRepository, publisher Publisher) *AuditService {
+	return &AuditService{
+		auditRepository: auditRepository,
+		publisher:       publisher,
+	}
+}
+
+// RecordTx writes the audit entry of a change in tx and returns it, it is
+// published with Publish once tx is committed. before is nil for a creation
+// and after is nil for a deletion, the entry only keeps the fields that
+// differ.
+func (s *AuditService) RecordTx(ctx context.Context, tx *sql.Tx,
+	action, entity, entityID string, before, after any,
+) (model.AuditLog, error) {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return model.AuditLog{}, fmt.Errorf("failed to diff %s: %w", entity, err)
+	}
+
+	reqContext, _ := dto.RequestFromContext(ctx)
+
+	actor := reqContext.Actor
+	if actor == "" {
+		actor = model.AuditActorAnonymous
+	}
+
+	entry := model.AuditLog{
+		Actor:     actor,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		Changes:   changes,
+		RequestID: reqContext.RequestID,
+		CreatedAt: time.Now().UnixMicro(),
+	}
+
+	if err := s.auditRepository.CreateTx(ctx, tx, &entry); err != nil {
+		return model.AuditLog{}, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Publish publishes a committed audit entry on audit.<entity>.<action>, an
+// entry whose transaction rolled back must never be published.
+func (s *AuditService) Publish(ctx context.Context, entry model.AuditLog) error {
+	if _, err := s.publisher.Publish(ctx, model.AuditEventPrefix+entry.Entity+"."+entry.Action, entry); err != nil {
+		return fmt.Errorf("failed to publish audit event: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuditService) GetAuditLogs(ctx context.Context, req dto.GetAuditLogsRequest) (dto.GetAuditLogsResponse, error) {
+	limit := req.PageSize
+	offset := (req.PageNumber - 1) * req.PageSize
+
+	entries, err := s.auditRepository.GetAll(ctx, req.Entity, req.Actor, limit, offset)
+	if err != nil {
+		return dto.GetAuditLogsResponse{}, err
+	}
+
+	auditLogs := make([]dto.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		changes := make(map[string]dto.AuditChangeResponse, len(entry.Changes))
+		for field, change := range entry.Changes {
+			changes[field] = dto.AuditChangeResponse{Before: change.Before, After: change.After}
+		}
+
+		auditLogs[i] = dto.AuditLogResponse{
+			ID:        entry.ID,
+			Actor:     entry.Actor,
+			Action:    entry.Action,
+			Entity:    entry.Entity,
+			EntityID:  entry.EntityID,
+			Changes:   changes,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt,
+		}
+	}
+
+	return dto.GetAuditLogsResponse{
+		Result:    true,
+		AuditLogs: auditLogs,
+	}, nil
+}
+
+// auditChanges compares the JSON fields of before and after.
+func auditChanges(before, after any) (map[string]model.AuditChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]model.AuditChange)
+
+	for field, value := range beforeFields {
+		if afterValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(value, afterValue) {
+			changes[field] = model.AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = model.AuditChange{After: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func jsonFields(entity any) (map[string]any, error) {
+	fields := map[string]any{}
+
+	if entity == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("encode entity: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode entity fields: %w", err)
+	}
+
+	return fields, nil
+}
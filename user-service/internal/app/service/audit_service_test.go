@@ -0,0 +1,107 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditService_RecordTx(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := &MockAuditRepository{}
+		pub := &MockPublisher{}
+		svc := NewAuditService(repo, pub)
+
+		req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+		req.Header.Set(dto.RequestIDHeader, "abc-123")
+		req, _ = dto.RequestWithContext(req)
+
+		ctx := dto.ContextWithActor(req.Context(), "admin")
+		before := model.User{ID: 1, Name: "John Doe", CreatedAt: 10, UpdatedAt: 10}
+		after := model.User{ID: 1, Name: "Jane Doe", CreatedAt: 10, UpdatedAt: 20}
+
+		entry, err := svc.RecordTx(ctx, &sql.Tx{}, model.AuditActionUpdate, "user", "1", before, after)
+		assert.NoError(t, err)
+		assert.Equal(t, repo.entries[0], entry)
+		// the entry is only published once the transaction is committed
+		assert.Empty(t, pub.subjects)
+
+		assert.NoError(t, svc.Publish(ctx, entry))
+
+		assert.Len(t, repo.entries, 1)
+		assert.Equal(t, "admin", repo.entries[0].Actor)
+		assert.Equal(t, "abc-123", repo.entries[0].RequestID)
+		assert.Equal(t, map[string]model.AuditChange{
+			"name":       {Before: "John Doe", After: "Jane Doe"},
+			"updated_at": {Before: float64(10), After: float64(20)},
+		}, repo.entries[0].Changes)
+		assert.Equal(t, []string{"audit.user.update"}, pub.subjects)
+	})
+
+	t.Run("creation has no before values", func(t *testing.T) {
+		repo := &MockAuditRepository{}
+		svc := NewAuditService(repo, &MockPublisher{})
+
+		_, err := svc.RecordTx(context.Background(), &sql.Tx{}, model.AuditActionCreate, "user", "1",
+			nil, model.User{ID: 1, Name: "John Doe"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, model.AuditActorAnonymous, repo.entries[0].Actor)
+		assert.Equal(t, model.AuditChange{After: "John Doe"}, repo.entries[0].Changes["name"])
+	})
+
+	t.Run("db_error", func(t *testing.T) {
+		pub := &MockPublisher{}
+		svc := NewAuditService(&MockAuditRepository{err: ErrMockDB}, pub)
+
+		_, err := svc.RecordTx(context.Background(), &sql.Tx{}, model.AuditActionCreate, "user", "1", nil, mockUsers[0])
+		assert.ErrorIs(t, err, ErrMockDB)
+		assert.Empty(t, pub.subjects)
+	})
+
+	t.Run("publish_error", func(t *testing.T) {
+		svc := NewAuditService(&MockAuditRepository{}, &MockPublisher{err: ErrMockPublish})
+
+		err := svc.Publish(context.Background(), model.AuditLog{Action: model.AuditActionCreate, Entity: "user"})
+		assert.ErrorIs(t, err, ErrMockPublish)
+	})
+}
+
+func TestAuditService_GetAuditLogs(t *testing.T) {
+	repo := &MockAuditRepository{entries: []model.AuditLog{{
+		ID:        1,
+		Actor:     "admin",
+		Action:    model.AuditActionCreate,
+		Entity:    "user",
+		EntityID:  "1",
+		Changes:   map[string]model.AuditChange{"name": {After: "John Doe"}},
+		RequestID: "abc-123",
+		CreatedAt: 10,
+	}}}
+	svc := NewAuditService(repo, &MockPublisher{})
+
+	got, err := svc.GetAuditLogs(context.Background(), dto.GetAuditLogsRequest{Entity: "user", PageNumber: 1, PageSize: 10})
+	assert.NoError(t, err)
+
+	assert.Equal(t, dto.GetAuditLogsResponse{
+		Result: true,
+		AuditLogs: []dto.AuditLogResponse{{
+			ID:        1,
+			Actor:     "admin",
+			Action:    model.AuditActionCreate,
+			Entity:    "user",
+			EntityID:  "1",
+			Changes:   map[string]dto.AuditChangeResponse{"name": {After: "John Doe"}},
+			RequestID: "abc-123",
+			CreatedAt: 10,
+		}},
+	}, got)
+}
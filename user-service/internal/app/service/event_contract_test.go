@@ -0,0 +1,44 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/model"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/contract"
+	natstransport "github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/transport/nats"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+// contractsDir holds the golden event fixtures shared by the services.
+const contractsDir = "../../../../contracts/events"
+
+func TestUserService_CreateUserEmitsContract(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{model.UserCreatedEvent}})
+	assert.NoError(t, err)
+
+	svc := NewUserService(&MockUserRepository{}, natstransport.NewPublisher(js, natstransport.JSONEncoder),
+		&MockAuditLog{})
+
+	_, err = svc.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe"})
+	assert.NoError(t, err)
+
+	fixture, err := contract.Latest(os.DirFS(contractsDir), model.UserCreatedEvent)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	msgs := js.Messages("events")
+	if assert.Len(t, msgs, 1) {
+		assert.NoError(t, contract.SameShape(fixture, msgs[0].Data), fixture.String())
+	}
+}
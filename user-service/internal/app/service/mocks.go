@@ -18,8 +18,9 @@ var (
 
 // MockUserRepository implements UserRepository interface
 type MockUserRepository struct {
-	users []model.User
-	err   error
+	users     []model.User
+	err       error
+	commitErr error
 }
 
 func (m *MockUserRepository) GetAll(ctx context.Context, limit, offset int) ([]model.User, error) {
@@ -54,19 +55,67 @@ func (m *MockUserRepository) WithTransaction(ctx context.Context, txFunc func(co
 	if m.err != nil {
 		return m.err
 	}
-	return txFunc(ctx, &sql.Tx{})
+	if err := txFunc(ctx, &sql.Tx{}); err != nil {
+		return err
+	}
+	return m.commitErr
 }
 
 // MockPublisher implements Publisher interface
 type MockPublisher struct {
-	err error
+	sequence uint64
+	subjects []string
+	err      error
 }
 
 func (m *MockPublisher) Publish(ctx context.Context, subject string, request interface{}) (*jetstream.PubAck, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return &jetstream.PubAck{}, nil
+	m.subjects = append(m.subjects, subject)
+	return &jetstream.PubAck{Sequence: m.sequence}, nil
+}
+
+// MockAuditLog implements AuditLog interface
+type MockAuditLog struct {
+	actions   []string
+	published []string
+	err       error
+}
+
+func (m *MockAuditLog) RecordTx(ctx context.Context, tx *sql.Tx, action, entity, entityID string, before, after any) (model.AuditLog, error) {
+	if m.err != nil {
+		return model.AuditLog{}, m.err
+	}
+	m.actions = append(m.actions, entity+"."+action+":"+entityID)
+	return model.AuditLog{Action: action, Entity: entity, EntityID: entityID}, nil
+}
+
+func (m *MockAuditLog) Publish(ctx context.Context, entry model.AuditLog) error {
+	m.published = append(m.published, entry.Entity+"."+entry.Action+":"+entry.EntityID)
+	return nil
+}
+
+// MockAuditRepository implements AuditRepository interface
+type MockAuditRepository struct {
+	entries []model.AuditLog
+	err     error
+}
+
+func (m *MockAuditRepository) GetAll(ctx context.Context, entity, actor string, limit, offset int) ([]model.AuditLog, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.entries, nil
+}
+
+func (m *MockAuditRepository) CreateTx(ctx context.Context, tx *sql.Tx, entry *model.AuditLog) error {
+	if m.err != nil {
+		return m.err
+	}
+	entry.ID = int64(len(m.entries) + 1)
+	m.entries = append(m.entries, *entry)
+	return nil
 }
 
 // Test data
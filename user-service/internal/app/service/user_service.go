@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
@@ -11,6 +13,8 @@ import (
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+const auditEntityUser = "user"
+
 type UserRepository interface {
 	GetAll(ctx context.Context, limit, offset int) ([]model.User, error)
 	GetByID(ctx context.Context, id int64) (model.User, error)
@@ -24,16 +28,26 @@ type Publisher interface {
 	Publish(ctx context.Context, subject string, request interface{}) (*jetstream.PubAck, error)
 }
 
+// AuditLog records the changes of the entities, in the transaction that
+// changes them, and publishes them once the transaction is committed.
+type AuditLog interface {
+	RecordTx(ctx context.Context, tx *sql.Tx, action, entity, entityID string,
+		before, after any) (model.AuditLog, error)
+	Publish(ctx context.Context, entry model.AuditLog) error
+}
+
 type UserService struct {
 	userRepository UserRepository
 	publisher      Publisher
+	auditLog       AuditLog
 }
 
 func NewUserService(userRepository UserRepository,
-	publisher Publisher) *UserService {
+	publisher Publisher, auditLog AuditLog) *UserService {
 	return &UserService{
 		userRepository: userRepository,
 		publisher:      publisher,
+		auditLog:       auditLog,
 	}
 }
 
@@ -44,14 +58,25 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		UpdatedAt: time.Now().UnixMicro(),
 	}
 
+	var (
+		pubAck     *jetstream.PubAck
+		auditEntry model.AuditLog
+	)
+
 	err := s.userRepository.WithTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		err := s.userRepository.CreateTx(ctx, tx, &user)
 		if err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
 
+		auditEntry, err = s.auditLog.RecordTx(ctx, tx, model.AuditActionCreate, auditEntityUser,
+			strconv.FormatInt(user.ID, 10), nil, user)
+		if err != nil {
+			return fmt.Errorf("failed to audit user: %w", err)
+		}
+
 		// publish event
-		_, err = s.publisher.Publish(ctx, model.UserCreatedEvent, user)
+		pubAck, err = s.publisher.Publish(ctx, model.UserCreatedEvent, user)
 		if err != nil {
 			return fmt.Errorf("failed to publish event: %w", err)
 		}
@@ -62,6 +87,13 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		return dto.CreateUserResponse{}, fmt.Errorf("failed to create user with transaction: %w", err)
 	}
 
+	// the user is created, the audit_log row stays the record of the change
+	// when its event can't be published
+	if err := s.auditLog.Publish(ctx, auditEntry); err != nil {
+		slog.ErrorContext(ctx, "failed to publish audit event",
+			slog.Int64("user_id", user.ID), slog.String("error", err.Error()))
+	}
+
 	return dto.CreateUserResponse{
 		Result: true,
 		User: dto.UserResponse{
@@ -70,6 +102,7 @@ func (s *UserService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: user.UpdatedAt,
 		},
+		ConsistencyToken: dto.NewConsistencyToken(model.UserCreatedEvent, pubAck.Sequence),
 	}, nil
 }
 
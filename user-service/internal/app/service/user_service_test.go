@@ -5,16 +5,18 @@ package service
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 
 	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/exception"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestUserService_GetAllUsers(t *testing.T) {
 	getAllUsersRequest := func(name string, req dto.GetAllUsersRequest, mockRepo *MockUserRepository, want dto.GetAllUsersResponse) func(t *testing.T) {
 		return func(t *testing.T) {
-			svc := NewUserService(mockRepo, &MockPublisher{})
+			svc := NewUserService(mockRepo, &MockPublisher{}, &MockAuditLog{})
 			got, err := svc.GetAllUsers(context.Background(), req)
 			if mockRepo.err != nil {
 				assert.Error(t, err)
@@ -59,7 +61,7 @@ func TestUserService_GetAllUsers(t *testing.T) {
 func TestUserService_GetUserByID(t *testing.T) {
 	getUserByIDRequest := func(name string, req dto.GetUserByIDRequest, mockRepo *MockUserRepository, want dto.GetUserByIDResponse, wantErr error) func(t *testing.T) {
 		return func(t *testing.T) {
-			svc := NewUserService(mockRepo, &MockPublisher{})
+			svc := NewUserService(mockRepo, &MockPublisher{}, &MockAuditLog{})
 			got, err := svc.GetUserByID(context.Background(), req)
 			if wantErr != nil {
 				assert.ErrorIs(t, err, wantErr)
@@ -106,7 +108,8 @@ func TestUserService_GetUserByID(t *testing.T) {
 func TestUserService_CreateUser(t *testing.T) {
 	createUserRequest := func(name string, req dto.CreateUserRequest, mockRepo *MockUserRepository, mockPub *MockPublisher, want dto.CreateUserResponse) func(t *testing.T) {
 		return func(t *testing.T) {
-			svc := NewUserService(mockRepo, mockPub)
+			auditLog := &MockAuditLog{}
+			svc := NewUserService(mockRepo, mockPub, auditLog)
 			got, err := svc.CreateUser(context.Background(), req)
 			if mockRepo.err != nil || mockPub.err != nil {
 				assert.Error(t, err)
@@ -116,9 +119,12 @@ func TestUserService_CreateUser(t *testing.T) {
 			// Since CreatedAt and UpdatedAt are set at runtime, we only compare ID and Name
 			assert.Equal(t, want.Result, got.Result)
 			assert.Equal(t, want.User.Name, got.User.Name)
+			assert.Equal(t, want.ConsistencyToken, got.ConsistencyToken)
 			assert.NotZero(t, got.User.ID)
 			assert.NotZero(t, got.User.CreatedAt)
 			assert.NotZero(t, got.User.UpdatedAt)
+			assert.Equal(t, []string{fmt.Sprintf("user.create:%d", got.User.ID)}, auditLog.actions)
+			assert.Equal(t, auditLog.actions, auditLog.published)
 		}
 	}
 
@@ -126,12 +132,13 @@ func TestUserService_CreateUser(t *testing.T) {
 		"success",
 		dto.CreateUserRequest{Name: "Test User"},
 		&MockUserRepository{users: mockUsers},
-		&MockPublisher{},
+		&MockPublisher{sequence: 5},
 		dto.CreateUserResponse{
 			Result: true,
 			User: dto.UserResponse{
 				Name: "Test User",
 			},
+			ConsistencyToken: "user.created:5",
 		},
 	))
 
@@ -150,4 +157,37 @@ func TestUserService_CreateUser(t *testing.T) {
 		&MockPublisher{err: ErrMockPublish},
 		dto.CreateUserResponse{},
 	))
+
+	t.Run("event_bus_unavailable", func(t *testing.T) {
+		publisher := &MockPublisher{err: fmt.Errorf("%w: nats: connection closed", exception.ErrEventBusUnavailable)}
+		svc := NewUserService(&MockUserRepository{}, publisher, &MockAuditLog{})
+
+		_, err := svc.CreateUser(context.Background(), dto.CreateUserRequest{Name: "Test User"})
+		assert.ErrorIs(t, err, exception.ErrEventBusUnavailable)
+		assert.Equal(t, exception.CodeUnavailable, exception.GetHTTPStatusCodeByErr(err))
+	})
+
+	t.Run("rolled back changes are not published", func(t *testing.T) {
+		auditLog := &MockAuditLog{}
+		svc := NewUserService(&MockUserRepository{}, &MockPublisher{err: ErrMockPublish}, auditLog)
+
+		_, err := svc.CreateUser(context.Background(), dto.CreateUserRequest{Name: "Test User"})
+		assert.ErrorIs(t, err, ErrMockPublish)
+		assert.Len(t, auditLog.actions, 1)
+		assert.Empty(t, auditLog.published)
+
+		auditLog = &MockAuditLog{}
+		svc = NewUserService(&MockUserRepository{commitErr: ErrMockDB}, &MockPublisher{}, auditLog)
+
+		_, err = svc.CreateUser(context.Background(), dto.CreateUserRequest{Name: "Test User"})
+		assert.ErrorIs(t, err, ErrMockDB)
+		assert.Empty(t, auditLog.published)
+	})
+
+	t.Run("audit_error", func(t *testing.T) {
+		svc := NewUserService(&MockUserRepository{}, &MockPublisher{}, &MockAuditLog{err: ErrMockDB})
+
+		_, err := svc.CreateUser(context.Background(), dto.CreateUserRequest{Name: "Test User"})
+		assert.ErrorIs(t, err, ErrMockDB)
+	})
 }
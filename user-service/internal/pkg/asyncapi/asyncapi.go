@@ -0,0 +1,298 @@
+// Package asyncapi documents the NATS events of a service as an AsyncAPI
+// 2.6 document generated from the Go types of their payloads, and checks
+// that the producers and consumers of a subject agree on its payload.
+package asyncapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const Version = "2.6.0"
+
+// Event is a subject of the catalog, Payload is a value of the Go type
+// encoded in or decoded from the messages.
+type Event struct {
+	Subject     string
+	Stream      string
+	Description string
+	// Message names the payload type, like UserCreated.
+	Message string
+	Payload any
+}
+
+// Catalog lists the events a service publishes and consumes.
+type Catalog struct {
+	Service     string
+	Version     string
+	Description string
+	Publishes   []Event
+	Consumes    []Event
+}
+
+type Document struct {
+	AsyncAPI           string             `json:"asyncapi"`
+	Info               Info               `json:"info"`
+	DefaultContentType string             `json:"defaultContentType"`
+	Channels           map[string]Channel `json:"channels"`
+	Components         Components         `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Channel follows the AsyncAPI 2 point of view: subscribe is the operation
+// of the clients receiving what the service sends, publish the one of the
+// clients sending what the service receives.
+type Channel struct {
+	Description string     `json:"description,omitempty"`
+	Subscribe   *Operation `json:"subscribe,omitempty"`
+	Publish     *Operation `json:"publish,omitempty"`
+	// Stream is the JetStream stream keeping the messages of the subject.
+	Stream string `json:"x-stream,omitempty"`
+}
+
+type Operation struct {
+	OperationID string     `json:"operationId"`
+	Message     MessageRef `json:"message"`
+}
+
+type MessageRef struct {
+	Ref string `json:"$ref"`
+}
+
+type Components struct {
+	Messages map[string]Message `json:"messages"`
+}
+
+type Message struct {
+	Name        string  `json:"name"`
+	ContentType string  `json:"contentType"`
+	Headers     *Schema `json:"headers,omitempty"`
+	Payload     *Schema `json:"payload"`
+}
+
+// headers are set on every message by the publishers.
+var headers = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"X-Transaction-Id": {Type: "string", Description: "request ID of the change"},
+		"traceparent":      {Type: "string", Description: "W3C trace context"},
+		"tracestate":       {Type: "string", Description: "W3C trace context"},
+	},
+}
+
+func (c Catalog) Document() Document {
+	doc := Document{
+		AsyncAPI: Version,
+		Info: Info{
+			Title:       c.Service,
+			Version:     c.Version,
+			Description: c.Description,
+		},
+		DefaultContentType: "application/json",
+		Channels:           make(map[string]Channel),
+		Components:         Components{Messages: make(map[string]Message)},
+	}
+
+	for _, event := range c.Publishes {
+		channel := doc.addEvent(event)
+		channel.Subscribe = &Operation{
+			OperationID: "send" + event.Message,
+			Message:     messageRef(event.Message),
+		}
+		doc.Channels[event.Subject] = channel
+	}
+
+	for _, event := range c.Consumes {
+		channel := doc.addEvent(event)
+		channel.Publish = &Operation{
+			OperationID: "receive" + event.Message,
+			Message:     messageRef(event.Message),
+		}
+		doc.Channels[event.Subject] = channel
+	}
+
+	return doc
+}
+
+func (d *Document) addEvent(event Event) Channel {
+	d.Components.Messages[event.Message] = Message{
+		Name:        event.Message,
+		ContentType: "application/json",
+		Headers:     headers,
+		Payload:     SchemaOf(event.Payload),
+	}
+
+	channel := d.Channels[event.Subject]
+	channel.Description = event.Description
+	channel.Stream = event.Stream
+
+	return channel
+}
+
+func messageRef(name string) MessageRef {
+	return MessageRef{Ref: "#/components/messages/" + name}
+}
+
+// payload resolves the message of op.
+func (d Document) payload(op *Operation) *Schema {
+	name := op.Message.Ref[strings.LastIndex(op.Message.Ref, "/")+1:]
+
+	return d.Components.Messages[name].Payload
+}
+
+// Handler serves doc as JSON.
+func Handler(doc Document) http.HandlerFunc {
+	body, err := json.MarshalIndent(doc, "", "  ")
+
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body) //nolint:errcheck
+	}
+}
+
+// Issue is a disagreement between a producer and a consumer of a channel,
+// a breaking one fails the decoding or loses data.
+type Issue struct {
+	Channel  string
+	Path     string
+	Message  string
+	Breaking bool
+}
+
+func (i Issue) String() string {
+	prefix := "warning"
+	if i.Breaking {
+		prefix = "error"
+	}
+
+	if i.Path == "" {
+		return prefix + ": " + i.Channel + ": " + i.Message
+	}
+
+	return prefix + ": " + i.Channel + ": " + i.Path + ": " + i.Message
+}
+
+type side struct {
+	service string
+	payload *Schema
+}
+
+// Validate compares the payload every consumer of a channel expects with the
+// payload of its producers, across the documents of the services.
+func Validate(docs ...Document) []Issue {
+	var (
+		producers = make(map[string][]side)
+		consumers = make(map[string][]side)
+	)
+
+	for _, doc := range docs {
+		for subject, channel := range doc.Channels {
+			if channel.Subscribe != nil {
+				producers[subject] = append(producers[subject], side{doc.Info.Title, doc.payload(channel.Subscribe)})
+			}
+
+			if channel.Publish != nil {
+				consumers[subject] = append(consumers[subject], side{doc.Info.Title, doc.payload(channel.Publish)})
+			}
+		}
+	}
+
+	subjects := make([]string, 0, len(consumers))
+	for subject := range consumers {
+		subjects = append(subjects, subject)
+	}
+
+	sort.Strings(subjects)
+
+	var issues []Issue
+
+	for _, subject := range subjects {
+		if len(producers[subject]) == 0 {
+			for _, consumer := range consumers[subject] {
+				issues = append(issues, Issue{
+					Channel: subject,
+					Message: "consumed by " + consumer.service + " but no document publishes it",
+				})
+			}
+
+			continue
+		}
+
+		for _, producer := range producers[subject] {
+			for _, consumer := range consumers[subject] {
+				for _, issue := range compare(producer.payload, consumer.payload, "") {
+					issue.Channel = subject
+					issue.Message = producer.service + " -> " + consumer.service + ": " + issue.Message
+					issues = append(issues, issue)
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+func compare(producer, consumer *Schema, path string) []Issue {
+	if producer == nil || consumer == nil || producer.Type == "" || consumer.Type == "" {
+		return nil
+	}
+
+	// a JSON number decodes in a float, not a fraction in an integer
+	if producer.Type != consumer.Type && (producer.Type != "integer" || consumer.Type != "number") {
+		return []Issue{{
+			Path:     path,
+			Message:  "sent as " + producer.Type + ", decoded as " + consumer.Type,
+			Breaking: true,
+		}}
+	}
+
+	switch consumer.Type {
+	case "array":
+		return compare(producer.Items, consumer.Items, path+"[]")
+	case "object":
+		var issues []Issue
+
+		for _, field := range sortedKeys(consumer.Properties) {
+			fieldPath := strings.TrimPrefix(path+"."+field, ".")
+
+			sent, ok := producer.Properties[field]
+			if !ok {
+				if producer.Properties != nil {
+					issues = append(issues, Issue{Path: fieldPath, Message: "decoded but never sent"})
+				}
+
+				continue
+			}
+
+			issues = append(issues, compare(sent, consumer.Properties[field], fieldPath)...)
+		}
+
+		return issues
+	default:
+		return nil
+	}
+}
+
+func sortedKeys(properties map[string]*Schema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
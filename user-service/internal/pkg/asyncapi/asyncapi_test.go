@@ -0,0 +1,133 @@
+//go:build unit
+
+package asyncapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type base struct {
+	ID int64 `json:"id"`
+}
+
+type sentUser struct {
+	base
+	Name      string            `json:"name"`
+	Age       int32             `json:"age,omitempty"`
+	Score     float64           `json:"score"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	Address   *address          `json:"address"`
+	CreatedAt time.Time         `json:"created_at"`
+	Password  string            `json:"-"`
+	internal  string
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(sentUser{})
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"id", "name", "score", "tags", "labels", "address", "created_at"}, schema.Required)
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64"}, schema.Properties["id"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32"}, schema.Properties["age"])
+	assert.Equal(t, &Schema{Type: "number"}, schema.Properties["score"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, schema.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, schema.Properties["labels"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created_at"])
+	assert.Equal(t, "string", schema.Properties["address"].Properties["city"].Type)
+	assert.NotContains(t, schema.Properties, "Password")
+	assert.NotContains(t, schema.Properties, "internal")
+}
+
+type consumedUser struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Score   float64  `json:"score"`
+	Address *address `json:"address"`
+}
+
+type consumedCount struct {
+	Count float64 `json:"count"`
+}
+
+type sentCount struct {
+	Count int `json:"count"`
+}
+
+func TestValidate(t *testing.T) {
+	producer := Catalog{
+		Service: "producer",
+		Publishes: []Event{
+			{Subject: "user.created", Message: "UserCreated", Payload: sentUser{}},
+			{Subject: "count.updated", Message: "CountUpdated", Payload: sentCount{}},
+		},
+	}.Document()
+
+	consumer := Catalog{
+		Service: "consumer",
+		Consumes: []Event{
+			{Subject: "user.created", Message: "UserCreated", Payload: consumedUser{}},
+			{Subject: "count.updated", Message: "CountUpdated", Payload: consumedCount{}},
+			{Subject: "listing.created", Message: "ListingCreated", Payload: consumedCount{}},
+		},
+	}.Document()
+
+	issues := Validate(producer, consumer)
+
+	assert.Equal(t, []Issue{
+		{
+			Channel: "listing.created",
+			Message: "consumed by consumer but no document publishes it",
+		},
+		{
+			Channel: "user.created",
+			Path:    "email",
+			Message: "producer -> consumer: decoded but never sent",
+		},
+		{
+			Channel:  "user.created",
+			Path:     "id",
+			Message:  "producer -> consumer: sent as integer, decoded as string",
+			Breaking: true,
+		},
+	}, issues)
+	assert.Equal(t, "error: user.created: id: producer -> consumer: sent as integer, decoded as string",
+		issues[2].String())
+}
+
+func TestCatalog_Document(t *testing.T) {
+	doc := Catalog{
+		Service:   "user-service",
+		Version:   "1.0.0",
+		Publishes: []Event{{Subject: "user.created", Stream: "events", Message: "UserCreated", Payload: sentUser{}}},
+		Consumes:  []Event{{Subject: "user.created", Stream: "events", Message: "UserCreated", Payload: sentUser{}}},
+	}.Document()
+
+	assert.Equal(t, Version, doc.AsyncAPI)
+	assert.Equal(t, "sendUserCreated", doc.Channels["user.created"].Subscribe.OperationID)
+	assert.Equal(t, "receiveUserCreated", doc.Channels["user.created"].Publish.OperationID)
+	assert.Equal(t, "events", doc.Channels["user.created"].Stream)
+	assert.Equal(t, "#/components/messages/UserCreated", doc.Channels["user.created"].Publish.Message.Ref)
+	assert.Contains(t, doc.Components.Messages["UserCreated"].Headers.Properties, "traceparent")
+
+	rec := httptest.NewRecorder()
+	Handler(doc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/asyncapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var served Document
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, doc, served)
+}
@@ -0,0 +1,525 @@
+// Package configcheck validates a config struct decoded by viper from
+// UPPER_SNAKE_CASE keys, with the validate tags of its fields, reports the
+// keys matching no field and dumps the config with the secret fields masked.
+//
+// The key of a field is the name of its mapstructure tag, the fields of the
+// structs tagged ",squash" are keys of the parent. A field tagged
+// secret:"true" is masked in the dumps, the logs and the problems, and can
+// be read from the file named by its key suffixed by FileSuffix.
+package configcheck
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrInvalid is wrapped by the error of a report with errors.
+var ErrInvalid = errors.New("invalid config")
+
+// FileSuffix suffixes the key of a secret field to name the file holding its
+// value, like DB_DSN_FILE=/run/secrets/db_dsn for Docker and Kubernetes
+// secrets.
+const FileSuffix = "_FILE"
+
+const mask = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var decodeErrorPattern = regexp.MustCompile(`'([A-Za-z0-9_]+)'`)
+
+// Problem is an error or a warning about a key of the config.
+type Problem struct {
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Key + ": " + p.Message
+}
+
+// Report lists every problem of a config at once, the errors prevent the
+// service from starting.
+type Report struct {
+	Errors   []Problem
+	Warnings []Problem
+}
+
+// Err returns nil when the report has no error, otherwise an error wrapping
+// ErrInvalid listing them.
+func (r Report) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(r.Errors))
+	for i, problem := range r.Errors {
+		lines[i] = "  " + problem.String()
+	}
+
+	return fmt.Errorf("%w, %d problem(s):\n%s", ErrInvalid, len(r.Errors), strings.Join(lines, "\n"))
+}
+
+// Check validates cfg, a struct or a pointer to one, and warns about the keys
+// matching none of its fields, with the closest known key when it looks like
+// a typo.
+//
+// decodeErr is the error of decoding cfg, nil when it succeeded. The values
+// that couldn't be decoded are reported instead of the rules of their fields,
+// which only see a zero value.
+func Check(cfg any, keys []string, decodeErr error) Report {
+	fields := fieldsOf(cfg)
+
+	var report Report
+
+	undecoded := make(map[string]bool)
+
+	for _, problem := range decodeProblems(decodeErr) {
+		undecoded[problem.Key] = true
+		report.Errors = append(report.Errors, problem)
+	}
+
+	err := validate.Struct(cfg)
+
+	var validationErrs validator.ValidationErrors
+
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			if problem := problemOf(fe, fields); !undecoded[problem.Key] {
+				report.Errors = append(report.Errors, problem)
+			}
+		}
+	case err != nil:
+		report.Errors = append(report.Errors, Problem{Key: "config", Message: err.Error()})
+	}
+
+	known := make([]string, 0, len(fields))
+	for _, f := range fields {
+		known = append(known, f.key)
+	}
+
+	for _, key := range keys {
+		key = strings.ToUpper(key)
+		if _, ok := fields.byKey(key); ok {
+			continue
+		}
+
+		if f, ok := fields.byKey(strings.TrimSuffix(key, FileSuffix)); ok && f.secret {
+			continue
+		}
+
+		message := "unknown key, ignored"
+		if suggestion := closest(key, known); suggestion != "" {
+			message += ", did you mean " + suggestion + "?"
+		}
+
+		report.Warnings = append(report.Warnings, Problem{Key: key, Message: message})
+	}
+
+	sort.Slice(report.Warnings, func(i, j int) bool { return report.Warnings[i].Key < report.Warnings[j].Key })
+
+	return report
+}
+
+// Keys returns the keys of the fields of cfg, in the order of the fields.
+func Keys(cfg any) []string {
+	fields := fieldsOf(cfg)
+
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+
+	return keys
+}
+
+// Entry is a key of the config with its value formatted for display.
+type Entry struct {
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// Dump returns the keys of cfg sorted with their values, the secret ones
+// masked.
+func Dump(cfg any) []Entry {
+	fields := fieldsOf(cfg)
+
+	entries := make([]Entry, len(fields))
+	for i, f := range fields {
+		entries[i] = Entry{Key: f.key, Value: f.display(), Secret: f.secret}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	return entries
+}
+
+// LogValue is the slog value of cfg, a group of its keys with the secret
+// values masked, for the config types to implement slog.LogValuer.
+func LogValue(cfg any) slog.Value {
+	entries := Dump(cfg)
+
+	attrs := make([]slog.Attr, len(entries))
+	for i, entry := range entries {
+		attrs[i] = slog.String(entry.Key, entry.Value)
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// String formats cfg as KEY=VALUE lines with the secret values masked, for
+// the config types to implement fmt.Stringer.
+func String(cfg any) string {
+	var sb strings.Builder
+
+	for _, entry := range Dump(cfg) {
+		sb.WriteString(entry.Key + "=" + entry.Value + "\n")
+	}
+
+	return sb.String()
+}
+
+// ReadSecretFiles sets each secret field of cfg, a pointer to a struct, whose
+// key suffixed by FileSuffix names a file to the content of the file, without
+// the trailing newline most editors add. lookup returns the value of a key in
+// the config, empty when it is not set.
+func ReadSecretFiles(cfg any, lookup func(key string) string) []Problem {
+	var problems []Problem
+
+	for _, f := range fieldsOf(cfg) {
+		path := lookup(f.key + FileSuffix)
+		if !f.secret || path == "" {
+			continue
+		}
+
+		if lookup(f.key) != "" {
+			problems = append(problems, Problem{
+				Key:     f.key + FileSuffix,
+				Message: "can't be set with " + f.key + ", remove one of them",
+			})
+
+			continue
+		}
+
+		if !f.value.CanSet() || f.value.Kind() != reflect.String {
+			problems = append(problems, Problem{Key: f.key + FileSuffix, Message: "can't be read from a file"})
+
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, Problem{
+				Key:     f.key + FileSuffix,
+				Message: "cannot read the secret file, " + err.Error(),
+			})
+
+			continue
+		}
+
+		f.value.SetString(strings.TrimRight(string(data), "\r\n"))
+	}
+
+	return problems
+}
+
+// SecretFiles returns the files named by the keys of the secret fields of cfg
+// suffixed by FileSuffix, lookup returns the value of a key.
+func SecretFiles(cfg any, lookup func(key string) string) []string {
+	var files []string
+
+	for _, f := range fieldsOf(cfg) {
+		if path := lookup(f.key + FileSuffix); f.secret && path != "" {
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
+
+// Secrets returns the values of the secret fields of cfg by key, unmasked,
+// to tell when a secret changes.
+func Secrets(cfg any) map[string]string {
+	secrets := make(map[string]string)
+
+	for _, f := range fieldsOf(cfg) {
+		if f.secret {
+			secrets[f.key] = fmt.Sprint(f.value.Interface())
+		}
+	}
+
+	return secrets
+}
+
+// Mask hides a secret value: only the password of a URL is hidden so the
+// host stays visible, any other non empty value is replaced.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+
+		if u.User == nil && u.RawQuery == "" {
+			return value
+		}
+	}
+
+	return mask
+}
+
+// decodeProblems splits the error of mapstructure, which joins one line per
+// value it couldn't decode, the key is the first quoted name of a line.
+func decodeProblems(err error) []Problem {
+	if err == nil {
+		return nil
+	}
+
+	var problems []Problem
+
+	for _, line := range strings.Split(err.Error(), "\n") {
+		match := decodeErrorPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		key := strings.ToUpper(match[1])
+		message := strings.TrimPrefix(line, "error decoding '"+match[1]+"': ")
+
+		problems = append(problems, Problem{Key: key, Message: message})
+	}
+
+	if len(problems) == 0 {
+		problems = append(problems, Problem{Key: "config", Message: err.Error()})
+	}
+
+	return problems
+}
+
+// field is a key of the config and the struct field decoded from it.
+type field struct {
+	key    string
+	path   string
+	secret bool
+	value  reflect.Value
+}
+
+func (f field) display() string {
+	var value string
+
+	switch {
+	case f.value.Type() == durationType:
+		value = time.Duration(f.value.Int()).String()
+	case f.value.Kind() == reflect.Slice:
+		items := make([]string, f.value.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(f.value.Index(i).Interface())
+		}
+
+		value = strings.Join(items, ",")
+	default:
+		value = fmt.Sprint(f.value.Interface())
+	}
+
+	if f.secret {
+		return Mask(value)
+	}
+
+	return value
+}
+
+type fields []field
+
+func (fs fields) byKey(key string) (field, bool) {
+	for _, f := range fs {
+		if f.key == key {
+			return f, true
+		}
+	}
+
+	return field{}, false
+}
+
+func (fs fields) byPath(path string) (field, bool) {
+	for _, f := range fs {
+		if f.path == path {
+			return f, true
+		}
+	}
+
+	return field{}, false
+}
+
+func fieldsOf(cfg any) fields {
+	value := reflect.ValueOf(cfg)
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	return appendFields(nil, value, "")
+}
+
+func appendFields(fs fields, value reflect.Value, prefix string) fields {
+	for i := range value.NumField() {
+		structField := value.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(structField.Tag.Get("mapstructure"), ",")
+		path := prefix + structField.Name
+
+		if opts == "squash" && structField.Type.Kind() == reflect.Struct {
+			fs = appendFields(fs, value.Field(i), path+".")
+
+			continue
+		}
+
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fs = append(fs, field{
+			key:    strings.ToUpper(name),
+			path:   path,
+			secret: structField.Tag.Get("secret") == "true",
+			value:  value.Field(i),
+		})
+	}
+
+	return fs
+}
+
+// problemOf explains fe with the keys of the fields instead of their Go
+// names.
+func problemOf(fe validator.FieldError, fs fields) Problem {
+	// the namespace starts with the name of the root struct
+	_, path, _ := strings.Cut(fe.StructNamespace(), ".")
+
+	f, ok := fs.byPath(path)
+	if !ok {
+		return Problem{Key: fe.Field(), Message: fe.Error()}
+	}
+
+	message := messageOf(fe, f, fs)
+
+	if value := f.display(); value != "" && fe.Tag() != "required" && fe.Tag() != "required_if" {
+		message += fmt.Sprintf(", got %q", value)
+	}
+
+	return Problem{Key: f.key, Message: message}
+}
+
+func messageOf(fe validator.FieldError, f field, fs fields) string {
+	param := fe.Param()
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return "is required when " + conditionOf(param, f, fs)
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		return "must be at least " + param + durationHint(f, param)
+	case "max", "lte":
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param + durationHint(f, param)
+	case "lt":
+		return "must be less than " + param
+	case "url", "http_url":
+		return "must be an absolute URL like http://host:port"
+	case "urls":
+		return "must be a comma separated list of URLs like nats://host:4222"
+	case "dsn":
+		return "must be a postgres:// URL or a key=value connection string"
+	case "log_level":
+		return "must be debug, info, warn or error"
+	case "hostname_port":
+		return "must be an address like :9090 or host:9090"
+	default:
+		return "fails the " + fe.Tag() + " rule"
+	}
+}
+
+// conditionOf formats the "Field value" param of required_if with the key of
+// the field, a sibling of f.
+func conditionOf(param string, f field, fs fields) string {
+	name, value, _ := strings.Cut(param, " ")
+
+	parent := ""
+	if idx := strings.LastIndex(f.path, "."); idx >= 0 {
+		parent = f.path[:idx+1]
+	}
+
+	if sibling, ok := fs.byPath(parent + name); ok {
+		name = sibling.key
+	}
+
+	return name + " is " + value
+}
+
+// durationHint reminds that a duration without unit is in nanoseconds, as
+// viper parses it.
+func durationHint(f field, param string) string {
+	if f.value.Type() != durationType || param == "0" || param == "0s" {
+		return ""
+	}
+
+	return ", durations need a unit like 30s"
+}
+
+// closest returns the known key within a small edit distance of key, empty
+// when none is.
+func closest(key string, known []string) string {
+	best, bestDistance := "", len(key)/3+1
+
+	for _, candidate := range known {
+		if distance := levenshtein(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
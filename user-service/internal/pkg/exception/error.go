@@ -16,6 +16,7 @@ const (
 	CodeUnauthorized  = http.StatusUnauthorized
 	CodeForbidden     = http.StatusForbidden
 	CodeConflict      = http.StatusConflict
+	CodeUnavailable   = http.StatusServiceUnavailable
 )
 
 var (
@@ -50,6 +51,16 @@ var (
 		},
 		StatusCode: CodeConflict,
 	}
+
+	// ErrEventBusUnavailable is returned when an event can't be published
+	// because NATS is down or too slow to acknowledge it.
+	ErrEventBusUnavailable = ApplicationError{
+		Localizable: lang.Localizable{
+			MessageID: "errors.event_bus_unavailable",
+			Message:   "event bus unavailable",
+		},
+		StatusCode: CodeUnavailable,
+	}
 )
 
 // ApplicationError handles application level errors.
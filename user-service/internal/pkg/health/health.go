@@ -0,0 +1,203 @@
+// Package health serves the liveness and readiness probes of a process, the
+// readiness probe runs the checks of the dependencies the process needs.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+	// StatusShuttingDown is reported by the readiness probe once the process
+	// started its graceful shutdown.
+	StatusShuttingDown = "shutting_down"
+)
+
+// Checker checks a dependency, ctx carries the timeout of the check.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyNs int64  `json:"latency_ns"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the body of the probes.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	critical bool
+}
+
+// Health runs the registered checks on every readiness probe.
+type Health struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// New returns a Health whose checks are cancelled after timeout.
+func New(timeout time.Duration) *Health {
+	return &Health{timeout: timeout}
+}
+
+// Register adds a check the process can't serve without, its failure makes
+// the process not ready.
+func (h *Health) Register(name string, checker Checker) {
+	h.register(check{name: name, checker: checker, critical: true})
+}
+
+// RegisterNonCritical adds a check of a dependency the process can serve
+// without, its failure only degrades the report.
+func (h *Health) RegisterNonCritical(name string, checker Checker) {
+	h.register(check{name: name, checker: checker})
+}
+
+func (h *Health) register(c check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, c)
+}
+
+// Shutdown makes the readiness probe fail, so load balancers stop sending
+// traffic before the server stops accepting it.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// Ready runs all the checks concurrently.
+func (h *Health) Ready(ctx context.Context) Report {
+	if h.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make([]CheckResult, len(checks)),
+	}
+
+	var waitGroup sync.WaitGroup
+
+	for i, c := range checks {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			report.Checks[i] = h.run(ctx, c)
+		}()
+	}
+
+	waitGroup.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusUp {
+			continue
+		}
+
+		if result.Critical {
+			report.Status = StatusDown
+
+			break
+		}
+
+		report.Status = StatusDegraded
+	}
+
+	return report
+}
+
+func (h *Health) run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := safeCheck(ctx, c.checker)
+
+	result := CheckResult{
+		Name:      c.name,
+		Status:    StatusUp,
+		Critical:  c.critical,
+		LatencyNs: time.Since(start).Nanoseconds(),
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// safeCheck keeps a panicking checker from taking the process down with the
+// probe.
+func safeCheck(ctx context.Context, checker Checker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panicked: %v", r)
+		}
+	}()
+
+	return checker.Check(ctx)
+}
+
+// LiveHandler reports that the process is running, it doesn't run any check
+// so a dependency outage doesn't get the process restarted.
+func (h *Health) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusUp})
+	}
+}
+
+// ReadyHandler responds 503 when a critical check fails or the process is
+// shutting down.
+func (h *Health) ReadyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := h.Ready(r.Context())
+
+		statusCode := http.StatusOK
+		if report.Status == StatusDown || report.Status == StatusShuttingDown {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		writeReport(w, statusCode, report)
+	}
+}
+
+func writeReport(w http.ResponseWriter, statusCode int, report Report) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(report) //nolint:errcheck,errchkjson
+}
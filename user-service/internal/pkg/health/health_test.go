@@ -0,0 +1,140 @@
+//go:build unit
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_ReadyHandler(t *testing.T) {
+	up := CheckerFunc(func(context.Context) error { return nil })
+	down := CheckerFunc(func(context.Context) error { return errors.New("connection refused") })
+	slow := CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		name       string
+		setup      func(h *Health)
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name: "all checks up",
+			setup: func(h *Health) {
+				h.Register("postgres", up)
+				h.RegisterNonCritical("nats", up)
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusUp,
+		},
+		{
+			name: "non critical check down",
+			setup: func(h *Health) {
+				h.Register("postgres", up)
+				h.RegisterNonCritical("nats", down)
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusDegraded,
+		},
+		{
+			name: "critical check times out",
+			setup: func(h *Health) {
+				h.Register("postgres", slow)
+				h.RegisterNonCritical("nats", down)
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusDown,
+		},
+		{
+			name: "panicking check",
+			setup: func(h *Health) {
+				h.Register("postgres", CheckerFunc(func(context.Context) error { panic("nil pointer") }))
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusDown,
+		},
+		{
+			name: "shutting down",
+			setup: func(h *Health) {
+				h.Register("postgres", up)
+				h.Shutdown()
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusShuttingDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(10 * time.Millisecond)
+			tt.setup(h)
+
+			rec := httptest.NewRecorder()
+			h.ReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+			var report Report
+			err := json.NewDecoder(rec.Body).Decode(&report)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantStatus, report.Status)
+		})
+	}
+
+	t.Run("report lists each check", func(t *testing.T) {
+		h := New(10 * time.Millisecond)
+		h.Register("postgres", up)
+		h.RegisterNonCritical("user-service", down)
+
+		report := h.Ready(context.Background())
+
+		assert.Len(t, report.Checks, 2)
+		assert.Equal(t, CheckResult{Name: "postgres", Status: StatusUp, Critical: true,
+			LatencyNs: report.Checks[0].LatencyNs}, report.Checks[0])
+		assert.Equal(t, "user-service", report.Checks[1].Name)
+		assert.Equal(t, StatusDown, report.Checks[1].Status)
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+	})
+}
+
+func TestHealth_LiveHandler(t *testing.T) {
+	h := New(time.Second)
+	h.Register("postgres", CheckerFunc(func(context.Context) error { return errors.New("connection refused") }))
+	h.Shutdown()
+
+	rec := httptest.NewRecorder()
+	h.LiveHandler()(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"up"}`, rec.Body.String())
+}
+
+func TestHTTPChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health/live" {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := HTTPChecker(server.Client(), server.URL+"/health/live").Check(context.Background())
+	assert.NoError(t, err)
+
+	err = HTTPChecker(server.Client(), server.URL+"/unknown").Check(context.Background())
+	assert.ErrorIs(t, err, errUnhealthyResponse)
+}
@@ -0,0 +1,43 @@
+//go:build unit
+
+package logger
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelController(t *testing.T) {
+	t.Run("override reverts to the configured level", func(t *testing.T) {
+		controller := NewLevelController(slog.LevelInfo)
+
+		revertAt := controller.Override(slog.LevelDebug, 20*time.Millisecond)
+		assert.Equal(t, slog.LevelDebug, controller.Level())
+		assert.Equal(t, revertAt, controller.Status().RevertAt)
+
+		// the configured level changes under the override
+		controller.SetConfigured(slog.LevelWarn)
+		assert.Equal(t, slog.LevelDebug, controller.Level())
+
+		assert.Eventually(t, func() bool {
+			return controller.Level() == slog.LevelWarn
+		}, time.Second, 5*time.Millisecond)
+		assert.True(t, controller.Status().RevertAt.IsZero())
+	})
+
+	t.Run("new override replaces the previous one", func(t *testing.T) {
+		controller := NewLevelController(slog.LevelInfo)
+
+		controller.Override(slog.LevelDebug, 10*time.Millisecond)
+		controller.Override(slog.LevelError, time.Hour)
+
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, slog.LevelError, controller.Level())
+
+		controller.Reset()
+		assert.Equal(t, LevelStatus{Level: slog.LevelInfo, Configured: slog.LevelInfo}, controller.Status())
+	})
+}
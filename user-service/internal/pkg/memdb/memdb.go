@@ -0,0 +1,216 @@
+// Package memdb keeps the data of the in-memory repositories, used to run the
+// service without Postgres.
+//
+// Transactions are serialized: a transaction holds the write lock of the DB
+// until it commits or rolls back, so it never sees the changes of another one.
+// Its writes are staged and only applied on commit, the readers outside of it
+// see the committed rows and never wait for it, however long it takes, for
+// instance while it publishes an event. A rollback drops the staged writes.
+// The transaction is carried by the context, the repositories receive a nil
+// *sql.Tx.
+package memdb
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type DB struct {
+	// writeMu serializes the transactions and the writes outside of them.
+	writeMu sync.Mutex
+	// mu guards the committed rows, it is only held for writing to apply
+	// writes.
+	mu sync.RWMutex
+}
+
+func New() *DB {
+	return &DB{}
+}
+
+type txKey struct{}
+
+type tx struct {
+	db *DB
+	// staged holds the rows written by the transaction by table.
+	staged map[any]any
+	// commits apply the staged rows of each table.
+	commits []func()
+}
+
+func (t *tx) commit() {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	for _, commit := range t.commits {
+		commit()
+	}
+}
+
+// WithTransaction runs fn in a transaction committed when fn returns nil and
+// rolled back when it fails or panics. Within a transaction of ctx, fn joins
+// it.
+func (db *DB) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	if db.current(ctx) != nil {
+		return fn(ctx)
+	}
+
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+
+	t := &tx{db: db, staged: make(map[any]any)}
+
+	// a panic skips the commit like an error does
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		return err
+	}
+
+	t.commit()
+
+	return nil
+}
+
+func (db *DB) current(ctx context.Context) *tx {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok && t.db == db {
+		return t
+	}
+
+	return nil
+}
+
+// read runs fn with the committed rows locked for reading.
+func (db *DB) read(fn func()) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	fn()
+}
+
+// Table holds the rows of an entity by primary key.
+type Table[K comparable, V any] struct {
+	db   *DB
+	rows map[K]V
+	seq  atomic.Int64
+}
+
+func NewTable[K comparable, V any](db *DB) *Table[K, V] {
+	return &Table[K, V]{
+		db:   db,
+		rows: make(map[K]V),
+	}
+}
+
+// NextID returns the next value of the sequence of the table, like a
+// Postgres sequence it isn't rolled back.
+func (t *Table[K, V]) NextID() int64 {
+	return t.seq.Add(1)
+}
+
+// staged returns the rows written to the table by the transaction of ctx, nil
+// outside of a transaction.
+func (t *Table[K, V]) staged(ctx context.Context) map[K]V {
+	current := t.db.current(ctx)
+	if current == nil {
+		return nil
+	}
+
+	if rows, ok := current.staged[t].(map[K]V); ok {
+		return rows
+	}
+
+	rows := make(map[K]V)
+	current.staged[t] = rows
+	current.commits = append(current.commits, func() {
+		for key, row := range rows {
+			t.rows[key] = row
+		}
+	})
+
+	return rows
+}
+
+func (t *Table[K, V]) Get(ctx context.Context, key K) (V, bool) {
+	if row, ok := t.staged(ctx)[key]; ok {
+		return row, true
+	}
+
+	var (
+		row V
+		ok  bool
+	)
+
+	t.db.read(func() {
+		row, ok = t.rows[key]
+	})
+
+	return row, ok
+}
+
+// Put inserts or replaces the row of key.
+func (t *Table[K, V]) Put(ctx context.Context, key K, row V) {
+	t.Upsert(ctx, key, func(V, bool) V { return row })
+}
+
+// Upsert stores the row fn returns from the current row of key, exists is
+// false when there is none.
+func (t *Table[K, V]) Upsert(ctx context.Context, key K, fn func(current V, exists bool) V) {
+	if staged := t.staged(ctx); staged != nil {
+		staged[key] = fn(t.Get(ctx, key))
+
+		return
+	}
+
+	t.db.writeMu.Lock()
+	defer t.db.writeMu.Unlock()
+
+	// the current row can't change, only writers update it
+	row := fn(t.Get(ctx, key))
+
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	t.rows[key] = row
+}
+
+// Select returns the rows matching keep, nil keeps them all, sorted with
+// less.
+func (t *Table[K, V]) Select(ctx context.Context, keep func(V) bool, less func(a, b V) bool) []V {
+	var rows []V
+
+	staged := t.staged(ctx)
+
+	t.db.read(func() {
+		rows = make([]V, 0, len(t.rows)+len(staged))
+
+		for key, row := range t.rows {
+			if _, ok := staged[key]; !ok && (keep == nil || keep(row)) {
+				rows = append(rows, row)
+			}
+		}
+	})
+
+	for _, row := range staged {
+		if keep == nil || keep(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+
+	return rows
+}
+
+// Page returns the rows of a LIMIT/OFFSET query.
+func Page[V any](rows []V, limit, offset int) []V {
+	if offset >= len(rows) {
+		return []V{}
+	}
+
+	rows = rows[offset:]
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
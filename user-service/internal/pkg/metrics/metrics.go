@@ -0,0 +1,110 @@
+// Package metrics registers the application metrics in a Prometheus registry
+// and serves them, the collectors are the ones of client_golang.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+)
+
+// DefBuckets are the default histogram buckets in seconds, tuned for request
+// latencies.
+var DefBuckets = prometheus.DefBuckets
+
+// Default is the registry the application metrics are registered in, it also
+// exposes the Go runtime and process metrics.
+var Default = newDefaultRegistry()
+
+// Registry holds metrics by name.
+type Registry struct {
+	*prometheus.Registry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{Registry: prometheus.NewRegistry()}
+}
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return registry
+}
+
+// NewCounterVec registers a counter partitioned by labels, registering the
+// same name twice returns the first counter.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	return register(r, prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels))
+}
+
+// NewHistogramVec registers a histogram partitioned by labels, buckets are
+// upper bounds in ascending order.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64,
+	labels ...string,
+) *prometheus.HistogramVec {
+	return register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    help,
+		Buckets: buckets,
+	}, labels))
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on every scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	register(r, prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, fn))
+}
+
+// NewCounterFunc registers a counter whose value is read from fn on every
+// scrape, fn must never decrease.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	register(r, prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, fn))
+}
+
+// Write renders every metric sorted by name in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	families, err := r.Gather()
+	if err != nil {
+		return fmt.Errorf("gather metrics: %w", err)
+	}
+
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+			return fmt.Errorf("write metrics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Handler serves the registry in the Prometheus exposition formats.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.Registry, promhttp.HandlerOpts{Registry: r.Registry})
+}
+
+// register returns the collector already registered under the same name
+// instead of c, for instance when two servers share a middleware.
+func register[T prometheus.Collector](r *Registry, c T) T {
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+
+	if err := r.Register(c); err != nil {
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+
+		panic(err)
+	}
+
+	return c
+}
@@ -0,0 +1,168 @@
+//go:build unit
+
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_create_user_table.up.sql":     {Data: []byte("CREATE TABLE users ();")},
+		"000001_create_user_table.down.sql":   {Data: []byte("DROP TABLE users;")},
+		"000002_create_listing_table.up.sql":  {Data: []byte("CREATE TABLE listings ();")},
+		"000003_add_listing_index.up.sql":     {Data: []byte("CREATE INDEX listings_idx ON listings (id);")},
+		"000003_add_listing_index.down.sql":   {Data: []byte("DROP INDEX listings_idx;")},
+		"README.md":                           {Data: []byte("not a migration")},
+		"000004_empty.up.sql":                 {Data: []byte("")},
+		"000004_empty.down.sql":               {Data: []byte("")},
+		"migrations.go":                       {Data: []byte("package migrations")},
+		"000002_create_listing_table.down.sq": {Data: []byte("ignored")},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFS())
+	assert.NoError(t, err)
+
+	var names []string
+	for _, migration := range migrations {
+		names = append(names, migration.Name)
+	}
+
+	assert.Equal(t, []string{"create_user_table", "create_listing_table", "add_listing_index", "empty"}, names)
+	assert.Equal(t, "DROP TABLE users;", migrations[0].Down)
+	assert.False(t, migrations[1].hasDown)
+	assert.True(t, migrations[3].hasDown)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "missing up file",
+			fsys:    fstest.MapFS{"000001_users.down.sql": {Data: []byte("DROP TABLE users;")}},
+			wantErr: "migration 1_users has no up file",
+		},
+		{
+			name: "version with two names",
+			fsys: fstest.MapFS{
+				"000001_users.up.sql":    {Data: []byte("CREATE TABLE users ();")},
+				"000001_accounts.up.sql": {Data: []byte("CREATE TABLE accounts ();")},
+			},
+			wantErr: "migration 1 is named both accounts and users",
+		},
+		{
+			name:    "version zero",
+			fsys:    fstest.MapFS{"000000_users.up.sql": {Data: []byte("CREATE TABLE users ();")}},
+			wantErr: `invalid migration version "000000"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMigrator_Plan(t *testing.T) {
+	m, err := New(nil, testFS())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), m.Latest())
+
+	type planned struct {
+		version uint64
+		up      bool
+		to      uint64
+	}
+
+	tests := []struct {
+		name    string
+		current uint64
+		target  uint64
+		want    []planned
+		wantErr string
+	}{
+		{
+			name:   "up from an empty database",
+			target: 4,
+			want:   []planned{{1, true, 1}, {2, true, 2}, {3, true, 3}, {4, true, 4}},
+		},
+		{
+			name:    "up to date",
+			current: 4,
+			target:  4,
+		},
+		{
+			name:    "down to an earlier version",
+			current: 4,
+			target:  2,
+			want:    []planned{{4, false, 3}, {3, false, 2}},
+		},
+		{
+			name:    "down through a migration without down file",
+			current: 3,
+			target:  0,
+			wantErr: "migration 2_create_listing_table can't be reverted, it has no down file",
+		},
+		{
+			name:    "unknown current version",
+			current: 7,
+			target:  4,
+			wantErr: "database is at version 7, which has no migration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := m.plan(tt.current, tt.target)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			var got []planned
+			for _, step := range steps {
+				got = append(got, planned{step.migration.Version, step.up, step.version})
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMigrator_VersionAt(t *testing.T) {
+	m, err := New(nil, testFS(), WithTable("user_schema_migrations"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user_schema_migrations", m.table)
+
+	assert.Equal(t, uint64(0), m.versionAt(-2))
+	assert.Equal(t, uint64(1), m.versionAt(0))
+	assert.Equal(t, uint64(4), m.versionAt(9))
+	assert.Equal(t, -1, m.index(0))
+	assert.Equal(t, 2, m.index(3))
+}
+
+func TestStatus_Pending(t *testing.T) {
+	status := Status{
+		Version: 1,
+		Migrations: []MigrationStatus{
+			{Version: 1, Applied: true},
+			{Version: 2},
+			{Version: 3},
+		},
+	}
+
+	assert.Equal(t, 2, status.Pending())
+}
@@ -0,0 +1,63 @@
+// Package timing adds up where the time of a request or a message goes, so a
+// slow one can be broken down in its log.
+package timing
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+type contextKey struct{}
+
+// Breakdown accumulates the time spent in the database and in downstream
+// services, it is safe for concurrent use.
+type Breakdown struct {
+	db              atomic.Int64
+	dbCalls         atomic.Int64
+	downstream      atomic.Int64
+	downstreamCalls atomic.Int64
+}
+
+// ContextWithBreakdown returns a copy of ctx collecting a new breakdown.
+func ContextWithBreakdown(ctx context.Context) (context.Context, *Breakdown) {
+	breakdown := &Breakdown{}
+
+	return context.WithValue(ctx, contextKey{}, breakdown), breakdown
+}
+
+// AddDB records a database call, it is ignored when ctx has no breakdown.
+func AddDB(ctx context.Context, duration time.Duration) {
+	if breakdown, ok := ctx.Value(contextKey{}).(*Breakdown); ok {
+		breakdown.db.Add(int64(duration))
+		breakdown.dbCalls.Add(1)
+	}
+}
+
+// AddDownstream records a call to another service, it is ignored when ctx has
+// no breakdown.
+func AddDownstream(ctx context.Context, duration time.Duration) {
+	if breakdown, ok := ctx.Value(contextKey{}).(*Breakdown); ok {
+		breakdown.downstream.Add(int64(duration))
+		breakdown.downstreamCalls.Add(1)
+	}
+}
+
+func (b *Breakdown) DB() time.Duration {
+	return time.Duration(b.db.Load())
+}
+
+func (b *Breakdown) Downstream() time.Duration {
+	return time.Duration(b.downstream.Load())
+}
+
+// Attrs returns the breakdown as log attributes.
+func (b *Breakdown) Attrs() []any {
+	return []any{
+		slog.Duration("db_duration_ns", b.DB()),
+		slog.Int64("db_calls", b.dbCalls.Load()),
+		slog.Duration("downstream_duration_ns", b.Downstream()),
+		slog.Int64("downstream_calls", b.downstreamCalls.Load()),
+	}
+}
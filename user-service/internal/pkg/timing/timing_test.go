@@ -0,0 +1,26 @@
+//go:build unit
+
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakdown(t *testing.T) {
+	ctx, breakdown := ContextWithBreakdown(context.Background())
+
+	AddDB(ctx, 10*time.Millisecond)
+	AddDB(ctx, 5*time.Millisecond)
+	AddDownstream(ctx, 100*time.Millisecond)
+
+	// without breakdown nothing is recorded, nor does it panic
+	AddDB(context.Background(), time.Second)
+
+	assert.Equal(t, 15*time.Millisecond, breakdown.DB())
+	assert.Equal(t, 100*time.Millisecond, breakdown.Downstream())
+	assert.Len(t, breakdown.Attrs(), 4)
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter = sdktrace.SpanExporter
+
+// NewWriterExporter writes spans as JSON lines.
+func NewWriterExporter(writer io.Writer) (Exporter, error) {
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+	if err != nil {
+		return nil, fmt.Errorf("create writer exporter: %w", err)
+	}
+
+	return exporter, nil
+}
+
+// NewFileExporter appends spans to the file at path, creating it if needed.
+func NewFileExporter(path string) (Exporter, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gosec,mnd
+	if err != nil {
+		return nil, fmt.Errorf("open span file: %w", err)
+	}
+
+	exporter, err := NewWriterExporter(file)
+	if err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	return &fileExporter{SpanExporter: exporter, file: file}, nil
+}
+
+// fileExporter closes the file once the exporter is shut down.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.file.Close()) //nolint:wrapcheck
+}
+
+// NewOTLPExporter sends spans to endpoint with OTLP/HTTP, endpoint is the full
+// URL of the traces receiver like http://otel-collector:4318/v1/traces.
+func NewOTLPExporter(ctx context.Context, endpoint string, timeout time.Duration) (Exporter, error) {
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithTimeout(timeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	return exporter, nil
+}
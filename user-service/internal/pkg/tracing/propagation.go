@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Carrier is the header collection of a transport, both http.Header and
+// nats.Header implement it.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// textMapCarrier adapts a Carrier to the propagator, the trace context
+// propagator never lists the keys.
+type textMapCarrier struct {
+	Carrier
+}
+
+func (textMapCarrier) Keys() []string {
+	return nil
+}
+
+// Inject writes the trace context of the current span of ctx into carrier,
+// nothing is written without a span.
+func Inject(ctx context.Context, carrier Carrier) {
+	propagator.Inject(ctx, textMapCarrier{Carrier: carrier})
+}
+
+// Extract returns a copy of ctx continuing the trace found in carrier, ctx is
+// returned unchanged when tracing is disabled or there is no valid
+// traceparent.
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	if global.Load() == nil {
+		return ctx
+	}
+
+	return propagator.Extract(ctx, textMapCarrier{Carrier: carrier})
+}
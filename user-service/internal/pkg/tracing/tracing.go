@@ -0,0 +1,131 @@
+// Package tracing records spans of the work done for a request with the
+// OpenTelemetry SDK and propagates the trace between services with the W3C
+// Trace Context headers. The services only use its small facade, Start,
+// Inject and Extract, so they don't depend on the SDK.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// instrumentationName is the scope of the spans started by Start.
+const instrumentationName = "github.com/ijalalfrz/event-driven-nats/tracing"
+
+// SpanKind values are the OpenTelemetry span kinds.
+type SpanKind = trace.SpanKind
+
+const (
+	SpanKindInternal = trace.SpanKindInternal
+	SpanKindServer   = trace.SpanKindServer
+	SpanKindClient   = trace.SpanKindClient
+	SpanKindProducer = trace.SpanKindProducer
+	SpanKindConsumer = trace.SpanKindConsumer
+)
+
+// Span is a unit of work of a trace. A span that is not recording, because
+// tracing is disabled or the trace is not sampled, ignores every call.
+type Span struct {
+	trace.Span
+}
+
+// SetAttribute sets a bool, integer, float or string attribute, other values
+// are recorded formatted with fmt.
+func (s *Span) SetAttribute(key string, value any) {
+	s.SetAttributes(attributeOf(key, value))
+}
+
+// RecordError marks the span as failed, a nil error is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.Span.RecordError(err)
+	s.SetStatus(codes.Error, err.Error())
+}
+
+func attributeOf(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case string:
+		return attribute.String(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+// SpanFromContext returns the current span of ctx, or a span that is not
+// recording if there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	return &Span{Span: trace.SpanFromContext(ctx)}
+}
+
+var global atomic.Pointer[Tracer]
+
+// SetTracer installs the tracer used by Start, nothing is recorded until a
+// tracer is set.
+func SetTracer(tracer *Tracer) {
+	global.Store(tracer)
+}
+
+// Start starts a span as a child of the current span of ctx and returns a
+// copy of ctx carrying it, the caller must End the span.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	tracer := global.Load()
+	if tracer == nil {
+		return ctx, &Span{Span: noop.Span{}}
+	}
+
+	return tracer.Start(ctx, name, kind)
+}
+
+// Tracer exports the ended spans of a service in batches from a background
+// goroutine, so requests never wait for the exporter. The traces continued
+// from an unsampled parent are propagated but not recorded.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+func NewTracer(service string, exporter Exporter) *Tracer {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	)
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer(instrumentationName),
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+
+	return ctx, &Span{Span: span}
+}
+
+// Shutdown exports the spans still queued and releases the exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if err := t.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("shutdown tracer provider: %w", err)
+	}
+
+	return nil
+}
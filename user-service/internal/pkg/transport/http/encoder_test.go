@@ -60,3 +60,20 @@ func TestCreatedResponse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusCreated, resp.Code)
 }
+
+type headerResponse struct {
+	Foo string `json:"foo"`
+}
+
+func (headerResponse) Headers() http.Header {
+	return http.Header{"X-Consistency-Token": []string{"user.created:1"}}
+}
+
+func TestEncodeJSONResponseWithHeaders(t *testing.T) {
+	resp := httptest.NewRecorder()
+	err := ResponseWithBody(context.Background(), resp, headerResponse{Foo: "bar"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "user.created:1", resp.Result().Header.Get("X-Consistency-Token"))
+	assert.JSONEq(t, `{"foo": "bar"}`, resp.Body.String())
+}
@@ -0,0 +1,126 @@
+//go:build unit
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithLogging(t *testing.T, req *http.Request, handler http.HandlerFunc, opts ...LoggingOption) map[string]any {
+	t.Helper()
+
+	out := new(bytes.Buffer)
+	logger := slog.New(slog.NewJSONHandler(out, nil))
+
+	LoggingMiddleware(logger, opts...)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	if out.Len() == 0 {
+		return nil
+	}
+
+	var log map[string]any
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &log))
+
+	return log
+}
+
+func TestLoggingMiddlewareBody(t *testing.T) {
+	t.Run("response written in chunks", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"users":`))
+			w.Write([]byte(`[{"id":1}]}`))
+		})
+
+		assert.Equal(t, `{"users":[{"id":1}]}`, log["response"])
+	})
+
+	t.Run("bodies over the limit are truncated", func(t *testing.T) {
+		var handlerBody []byte
+
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"john doe"}`))
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, r *http.Request) {
+			handlerBody, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"result":true}`))
+		}, WithBodyLimit(8))
+
+		assert.Equal(t, `{"name":"john doe"}`, string(handlerBody))
+		assert.Equal(t, `{"name":`+truncatedMarker, log["request"])
+		assert.Equal(t, `{"result`+truncatedMarker, log["response"])
+	})
+
+	t.Run("truncated bodies are omitted when redacting", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"john doe"}`))
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {},
+			WithBodyLimit(8), WithRedactedFields("name"))
+
+		assert.NotContains(t, log["request"], "john")
+	})
+
+	t.Run("JSON fields are redacted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/listings", nil)
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"listings":[{"id":1,"user":{"id":2,"name":"john"}},{"id":3,"user":{"id":4,"name":"jane"}}]}`))
+		}, WithRedactedFields("listings.*.user.name", "missing.field"))
+
+		assert.Equal(t,
+			`{"listings":[{"id":1,"user":{"id":2,"name":"[REDACTED]"}},{"id":3,"user":{"id":4,"name":"[REDACTED]"}}]}`,
+			log["response"])
+	})
+
+	t.Run("form fields are redacted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("name=john&language=en"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {},
+			WithRedactedFields("name"))
+
+		assert.Equal(t, "language=en&name=%5BREDACTED%5D", log["request"])
+	})
+
+	t.Run("other content types are omitted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("id,name\n1,john\n"))
+		}, WithContentTypes("application/json"))
+
+		assert.Equal(t, "[text/csv body omitted, 15 bytes]", log["response"])
+	})
+}
+
+func TestLoggingMiddlewareSampling(t *testing.T) {
+	t.Run("successful requests are skipped", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {},
+			WithSuccessSampleRate(0))
+
+		assert.Nil(t, log)
+	})
+
+	t.Run("error responses are always logged", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+		log := serveWithLogging(t, req, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, WithSuccessSampleRate(0))
+
+		assert.Equal(t, "ERROR", log["level"])
+	})
+}
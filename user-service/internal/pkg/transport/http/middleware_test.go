@@ -11,8 +11,18 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/metrics"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/timing"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/tracing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type structuredLog struct {
@@ -148,3 +158,255 @@ func unescapeUnquote(s string) string {
 	s = strings.Trim(s, "\"")
 	return strings.ReplaceAll(s, "\\", "")
 }
+
+func TestHeaderMiddleware(t *testing.T) {
+	var (
+		gotRequestID string
+		handler      = func(_ http.ResponseWriter, req *http.Request) {
+			reqContext, _ := dto.RequestFromContext(req.Context())
+			gotRequestID = reqContext.RequestID
+		}
+		respRecorder = httptest.NewRecorder()
+		req, _       = http.NewRequest(http.MethodGet, "http://example.com/api/v1/users", nil)
+	)
+
+	req.Header.Set(dto.RequestIDHeader, "abc-123")
+
+	HeaderMiddleware()(http.HandlerFunc(handler)).ServeHTTP(respRecorder, req)
+
+	assert.Equal(t, "abc-123", gotRequestID)
+	assert.Equal(t, "abc-123", respRecorder.Header().Get(dto.RequestIDHeader))
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	router := chi.NewRouter()
+	router.Use(MetricsMiddleware(registry))
+	router.Get("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/users/1", "/users/2"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, registry.Write(&buf))
+	assert.Contains(t, buf.String(), `http_requests_total{method="GET",route="/users/{id}",status="404"} 2`)
+	assert.Contains(t, buf.String(), `http_request_duration_seconds_count{method="GET",route="/users/{id}",status="404"} 2`)
+}
+
+// spanRecorder keeps the exported spans after the tracer is shut down, the
+// in-memory exporter of the SDK forgets them.
+type spanRecorder struct {
+	*tracetest.InMemoryExporter
+}
+
+func (spanRecorder) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	spans := spanRecorder{InMemoryExporter: tracetest.NewInMemoryExporter()}
+
+	tracer := tracing.NewTracer("test", spans)
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	var handlerSpan trace.SpanContext
+
+	router := chi.NewRouter()
+	router.Use(TracingMiddleware())
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = tracing.SpanFromContext(r.Context()).SpanContext()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NoError(t, tracer.Shutdown(context.Background()))
+
+	if !assert.Len(t, spans.GetSpans(), 1) {
+		return
+	}
+
+	span := spans.GetSpans()[0]
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handlerSpan.TraceID().String())
+	assert.Equal(t, handlerSpan.SpanID(), span.SpanContext.SpanID())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, "GET /users/{id}", span.Name)
+	assert.Contains(t, span.Attributes, attribute.Int("http.status_code", http.StatusInternalServerError))
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, "status code: 500", span.Status.Description)
+}
+
+func TestSlowRequestMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		logged    bool
+	}{
+		{"slower than threshold", time.Nanosecond, true},
+		{"faster than threshold", time.Hour, false},
+		{"disabled", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			logger := slog.New(slog.NewJSONHandler(out, nil))
+
+			router := chi.NewRouter()
+			router.Use(SlowRequestMiddleware(logger, tt.threshold))
+			router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				timing.AddDB(r.Context(), 2*time.Millisecond)
+				timing.AddDownstream(r.Context(), 3*time.Millisecond)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+			if !tt.logged {
+				assert.Empty(t, out.String())
+
+				return
+			}
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal(out.Bytes(), &record))
+
+			assert.Equal(t, "WARN", record["level"])
+			assert.Equal(t, "slow request", record["msg"])
+			assert.Equal(t, "/users/{id}", record["route"])
+			assert.Equal(t, float64(2*time.Millisecond), record["db_duration_ns"])
+			assert.Equal(t, float64(1), record["db_calls"])
+			assert.Equal(t, float64(3*time.Millisecond), record["downstream_duration_ns"])
+		})
+	}
+}
+
+func TestCORS_SetAllowedOrigins(t *testing.T) {
+	corsMiddleware := NewCORS([]string{"http://localhost:3000"})
+	handler := corsMiddleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	allowedOrigin := func(origin string) string {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Origin", origin)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	assert.Equal(t, "http://localhost:3000", allowedOrigin("http://localhost:3000"))
+	assert.Empty(t, allowedOrigin("http://localhost:8003"))
+
+	corsMiddleware.SetAllowedOrigins([]string{"http://localhost:8003"})
+
+	assert.Empty(t, allowedOrigin("http://localhost:3000"))
+	assert.Equal(t, "http://localhost:8003", allowedOrigin("http://localhost:8003"))
+}
+
+func TestServiceTokenMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		serviceTokens string
+		authorization string
+		wantCode      int
+	}{
+		{"valid token", "first, second", "Bearer second", http.StatusOK},
+		{"unknown token", "first, second", "Bearer third", http.StatusUnauthorized},
+		{"not a bearer token", "first", "first", http.StatusUnauthorized},
+		{"no token", "first", "", http.StatusUnauthorized},
+		{"no configured token", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := ServiceTokenMiddleware(tt.serviceTokens)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			req.Header.Set("Authorization", tt.authorization)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestServiceTokens_ActorHandler(t *testing.T) {
+	var actor string
+
+	handler := NewServiceTokens("gateway:first").ActorHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqContext, _ := dto.RequestFromContext(r.Context())
+		actor = reqContext.Actor
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		actorHeader   string
+		wantActor     string
+	}{
+		{"forwarded actor", "Bearer first", "alice", "alice"},
+		{"token name without actor", "Bearer first", "", "gateway"},
+		{"actor without token", "", "alice", ""},
+		{"actor with unknown token", "Bearer guess", "alice", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor = ""
+
+			req := httptest.NewRequest(http.MethodPost, "/users", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			req.Header.Set(dto.ActorHeader, tt.actorHeader)
+
+			req, err := dto.RequestWithContext(req)
+			assert.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantActor, actor)
+		})
+	}
+}
+
+func TestServiceTokens_SetTokens(t *testing.T) {
+	tokens := NewServiceTokens("first")
+	handler := tokens.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	code := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/log-level", nil)
+		req.Header.Set("Authorization", authorization)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, code("Bearer first"))
+
+	tokens.SetTokens("rotated")
+
+	assert.Equal(t, http.StatusUnauthorized, code("Bearer first"))
+	assert.Equal(t, http.StatusOK, code("Bearer rotated"))
+}
@@ -0,0 +1,375 @@
+//go:build unit
+
+package jetstreamtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStream(t *testing.T, js *JetStream) jetstream.Stream {
+	t.Helper()
+
+	stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "events",
+		Subjects: []string{"user.*", "audit.>"},
+	})
+	assert.NoError(t, err)
+
+	return stream
+}
+
+func publish(t *testing.T, js *JetStream, subjects ...string) {
+	t.Helper()
+
+	for _, subject := range subjects {
+		_, err := js.Publish(context.Background(), subject, []byte(subject))
+		assert.NoError(t, err)
+	}
+}
+
+// record consumes with a handler recording the subjects and settling the
+// messages with settle.
+func record(t *testing.T, cons jetstream.Consumer, settle func(jetstream.Msg) error) *[]string {
+	t.Helper()
+
+	var subjects []string
+
+	_, err := cons.Consume(func(msg jetstream.Msg) {
+		subjects = append(subjects, msg.Subject())
+		assert.NoError(t, settle(msg))
+	})
+	assert.NoError(t, err)
+
+	return &subjects
+}
+
+func TestJetStream_Publish(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	newStream(t, js)
+
+	ack, err := js.Publish(ctx, "user.created", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, &jetstream.PubAck{Stream: "events", Sequence: 1}, ack)
+
+	ack, err = js.Publish(ctx, "audit.user.create", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), ack.Sequence)
+
+	_, err = js.Publish(ctx, "listing.created", []byte("{}"))
+	assert.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+
+	js.FailPublish(errors.New("nats: connection closed"))
+	_, err = js.Publish(ctx, "user.created", []byte("{}"))
+	assert.EqualError(t, err, "nats: connection closed")
+
+	assert.Len(t, js.Messages("events"), 2)
+
+	_, err = js.Stream(ctx, "unknown")
+	assert.ErrorIs(t, err, jetstream.ErrStreamNotFound)
+}
+
+func TestJetStream_PublishAsync(t *testing.T) {
+	js := New()
+	newStream(t, js)
+
+	future, err := js.PublishAsync("user.created", []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, &jetstream.PubAck{Stream: "events", Sequence: 1}, <-future.Ok())
+
+	future, err = js.PublishAsync("listing.created", []byte("{}"))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, <-future.Err(), jetstream.ErrNoStreamResponse)
+
+	js.HoldAcks(true)
+
+	future, err = js.PublishAsync("user.created", []byte("{}"))
+	assert.NoError(t, err)
+
+	select {
+	case <-future.Ok():
+		t.Error("held ack resolved")
+	case <-future.Err():
+		t.Error("held ack failed")
+	default:
+	}
+
+	assert.Len(t, js.Messages("events"), 1)
+}
+
+func TestJetStream_PublishDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	newStream(t, js)
+
+	msg := nats.NewMsg("user.created")
+	msg.Header.Set(jetstream.MsgIDHeader, "msg-1")
+
+	// the message is stored but its ack never comes
+	js.LoseAcks(1)
+
+	future, err := js.PublishMsgAsync(msg)
+	assert.NoError(t, err)
+
+	select {
+	case <-future.Ok():
+		t.Error("lost ack resolved")
+	default:
+	}
+
+	ack, err := js.PublishMsg(ctx, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, &jetstream.PubAck{Stream: "events", Sequence: 1, Duplicate: true}, ack)
+
+	msg.Header.Set(jetstream.MsgIDHeader, "msg-2")
+
+	future, err = js.PublishMsgAsync(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, &jetstream.PubAck{Stream: "events", Sequence: 2}, <-future.Ok())
+
+	assert.Len(t, js.Messages("events"), 2)
+}
+
+func TestConsumer_FilterSubjects(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	stream := newStream(t, js)
+
+	publish(t, js, "user.created", "audit.user.create", "user.deleted")
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:        "users",
+		FilterSubjects: []string{"user.created", "user.deleted"},
+	})
+	assert.NoError(t, err)
+
+	subjects := record(t, cons, jetstream.Msg.Ack)
+
+	assert.Equal(t, 2, js.Flush())
+	assert.Equal(t, []string{"user.created", "user.deleted"}, *subjects)
+
+	info, err := cons.Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), info.NumPending)
+	assert.Equal(t, 0, info.NumAckPending)
+	assert.Equal(t, uint64(3), info.Delivered.Stream)
+}
+
+func TestConsumer_NakRedeliversUntilMaxDeliver(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	stream := newStream(t, js)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:    "users",
+		MaxDeliver: 3,
+	})
+	assert.NoError(t, err)
+
+	var deliveries []uint64
+
+	_, err = cons.Consume(func(msg jetstream.Msg) {
+		meta, err := msg.Metadata()
+		assert.NoError(t, err)
+
+		deliveries = append(deliveries, meta.NumDelivered)
+		assert.NoError(t, msg.Nak())
+		assert.ErrorIs(t, msg.Ack(), jetstream.ErrMsgAlreadyAckd)
+	})
+	assert.NoError(t, err)
+
+	publish(t, js, "user.created")
+
+	assert.Equal(t, 3, js.Flush())
+	assert.Equal(t, []uint64{1, 2, 3}, deliveries)
+}
+
+func TestConsumer_ExpireAckWait(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	stream := newStream(t, js)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{Durable: "users"})
+	assert.NoError(t, err)
+
+	// the first delivery is neither acked nor naked
+	settled := false
+	subjects := record(t, cons, func(msg jetstream.Msg) error {
+		if !settled {
+			settled = true
+
+			return nil
+		}
+
+		return msg.Ack()
+	})
+
+	publish(t, js, "user.created")
+	js.Flush()
+
+	info, _ := cons.Info(ctx)
+	assert.Equal(t, 1, info.NumAckPending)
+
+	js.ExpireAckWait()
+	js.Flush()
+
+	info, _ = cons.Info(ctx)
+	assert.Equal(t, 0, info.NumAckPending)
+	assert.Equal(t, []string{"user.created", "user.created"}, *subjects)
+}
+
+func TestConsumer_StopAndDeliverPolicy(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	stream := newStream(t, js)
+
+	publish(t, js, "user.created")
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       "users",
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+	})
+	assert.NoError(t, err)
+
+	var subjects []string
+
+	consumeCtx, err := cons.Consume(func(msg jetstream.Msg) {
+		subjects = append(subjects, msg.Subject())
+		msg.Ack() //nolint:errcheck
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, js.Flush())
+
+	publish(t, js, "user.updated")
+	js.Flush()
+	assert.Equal(t, []string{"user.updated"}, subjects)
+
+	consumeCtx.Stop()
+	<-consumeCtx.Closed()
+
+	publish(t, js, "user.deleted")
+	assert.Equal(t, 0, js.Flush())
+
+	// the durable consumer resumes where it stopped
+	record(t, cons, jetstream.Msg.Ack)
+	assert.Equal(t, 1, js.Flush())
+
+	assert.NoError(t, stream.DeleteConsumer(ctx, "users"))
+	assert.ErrorIs(t, stream.DeleteConsumer(ctx, "users"), jetstream.ErrConsumerNotFound)
+}
+
+func TestConsumer_PrefetchStopAndDrain(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	stream := newStream(t, js)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:    "users",
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		MaxDeliver: 1,
+	})
+	assert.NoError(t, err)
+
+	publish(t, js, "user.created", "user.updated", "user.deleted", "user.banned")
+
+	var subjects []string
+
+	handler := func(msg jetstream.Msg) {
+		subjects = append(subjects, msg.Subject())
+		msg.Ack() //nolint:errcheck
+	}
+
+	consumeCtx, err := cons.Consume(handler)
+	assert.NoError(t, err)
+
+	// drain hands the buffered messages over before closing
+	assert.Equal(t, 2, js.Prefetch(2))
+	consumeCtx.Drain()
+	<-consumeCtx.Closed()
+	assert.Equal(t, []string{"user.created", "user.updated"}, subjects)
+
+	// stop drops them, they are never redelivered past MaxDeliver
+	consumeCtx, err = cons.Consume(handler)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, js.Prefetch(2))
+	consumeCtx.Stop()
+
+	info, _ := cons.Info(ctx)
+	assert.Equal(t, 2, info.NumAckPending)
+
+	js.ExpireAckWait()
+
+	_, err = cons.Consume(handler)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, js.Flush())
+	assert.Equal(t, []string{"user.created", "user.updated"}, subjects)
+}
+
+func TestStream_PauseConsumer(t *testing.T) {
+	ctx := context.Background()
+	js := New()
+	stream := newStream(t, js)
+
+	cfg := jetstream.ConsumerConfig{
+		Durable:   "users",
+		AckPolicy: jetstream.AckExplicitPolicy,
+	}
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, cfg)
+	assert.NoError(t, err)
+
+	subjects := record(t, cons, jetstream.Msg.Ack)
+
+	publish(t, js, "user.created")
+
+	response, err := stream.PauseConsumer(ctx, "users", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, response.Paused)
+	assert.Equal(t, 0, js.Flush())
+
+	info, _ := cons.Info(ctx)
+	assert.True(t, info.Paused)
+	assert.Positive(t, info.PauseRemaining)
+	assert.Equal(t, uint64(1), info.NumPending)
+
+	response, err = stream.ResumeConsumer(ctx, "users")
+	assert.NoError(t, err)
+	assert.False(t, response.Paused)
+	assert.Equal(t, 1, js.Flush())
+	assert.Equal(t, []string{"user.created"}, *subjects)
+
+	// updating the consumer without PauseUntil keeps the pause, like the
+	// server
+	_, err = stream.PauseConsumer(ctx, "users", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = stream.CreateOrUpdateConsumer(ctx, cfg)
+	assert.NoError(t, err)
+
+	info, _ = cons.Info(ctx)
+	assert.True(t, info.Paused)
+
+	_, err = stream.PauseConsumer(ctx, "unknown", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, jetstream.ErrConsumerNotFound)
+}
+
+func TestStream_Info(t *testing.T) {
+	js := New()
+	stream := newStream(t, js)
+
+	publish(t, js, "user.created", "user.created", "audit.user.create")
+
+	info, err := stream.Info(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), info.State.Msgs)
+	assert.Equal(t, uint64(1), info.State.FirstSeq)
+	assert.Equal(t, uint64(3), info.State.LastSeq)
+	assert.Equal(t, map[string]uint64{"user.created": 2, "audit.user.create": 1}, info.State.Subjects)
+}
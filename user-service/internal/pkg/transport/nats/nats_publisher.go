@@ -2,28 +2,159 @@ package nats
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/tracing"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+// errPublishTimeout is returned when the ack of a publication takes longer
+// than the publish timeout.
+var errPublishTimeout = errors.New("timeout waiting for the ack")
+
+// Publisher publishes asynchronously and waits for the ack of each message,
+// the number of messages waiting for an ack is bounded by the
+// jetstream.WithPublishAsyncMaxPending option of js. Every message has a
+// Nats-Msg-Id, so a message sent again after its ack timed out is stored once
+// when it is within the duplicate window of the stream.
 type Publisher struct {
-	js  jetstream.JetStream
-	enc Encoder
+	js      jetstream.JetStream
+	enc     Encoder
+	timeout time.Duration
+	retries int
+}
+
+type PublisherOption func(*Publisher)
+
+// WithPublishTimeout bounds the wait for the ack of a message, zero waits as
+// long as the context of the publication.
+func WithPublishTimeout(timeout time.Duration) PublisherOption {
+	return func(p *Publisher) {
+		p.timeout = timeout
+	}
 }
 
-func NewPublisher(js jetstream.JetStream, enc Encoder) *Publisher {
-	return &Publisher{
+// WithPublishRetries sends a message again, with the same ID, up to retries
+// times when its ack times out.
+func WithPublishRetries(retries int) PublisherOption {
+	return func(p *Publisher) {
+		p.retries = retries
+	}
+}
+
+func NewPublisher(js jetstream.JetStream, enc Encoder, opts ...PublisherOption) *Publisher {
+	publisher := &Publisher{
 		js:  js,
 		enc: enc,
 	}
+
+	for _, opt := range opts {
+		opt(publisher)
+	}
+
+	return publisher
 }
 
-// Publish encodes and sends a message to JetStream.
+// Publish encodes and sends a message to JetStream, the request ID and the
+// trace of ctx are copied into the message headers so consumers can keep the
+// correlation. When NATS can't take the message, because the connection is
+// closed, too many messages wait for their ack or the ack doesn't come in time
+// after the retries, the error is exception.ErrEventBusUnavailable. A message
+// whose ack never came may still have been stored by the server, the caller
+// can't tell.
 func (p *Publisher) Publish(ctx context.Context, subject string, request interface{}) (*jetstream.PubAck, error) {
+	ctx, span := tracing.Start(ctx, subject+" publish", tracing.SpanKindProducer)
+	defer span.End()
+
+	span.SetAttribute("messaging.system", "nats")
+	span.SetAttribute("messaging.destination", subject)
+
 	data, err := p.enc(ctx, request)
 	if err != nil {
+		span.RecordError(err)
+
 		return nil, err
 	}
 
-	return p.js.Publish(ctx, subject, data)
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(jetstream.MsgIDHeader, dto.NewRequestID())
+
+	if reqContext, ok := dto.RequestFromContext(ctx); ok && reqContext.RequestID != "" {
+		msg.Header.Set(dto.RequestIDHeader, reqContext.RequestID)
+	}
+
+	tracing.Inject(ctx, msg.Header)
+
+	ack, err := p.publishWithRetries(ctx, msg)
+	span.RecordError(err)
+
+	return ack, err
+}
+
+// publishWithRetries sends msg again when its ack times out, the server
+// acks the copies of a stored message as duplicates with its sequence.
+func (p *Publisher) publishWithRetries(ctx context.Context, msg *nats.Msg) (*jetstream.PubAck, error) {
+	for attempt := 0; ; attempt++ {
+		ack, err := p.publish(ctx, msg)
+		if attempt == p.retries || !timedOut(err) {
+			return ack, err
+		}
+
+		slog.WarnContext(ctx, "publication ack timed out, retrying",
+			slog.String("subject", msg.Subject), slog.Int("attempt", attempt+1))
+	}
+}
+
+func timedOut(err error) bool {
+	return errors.Is(err, errPublishTimeout) || errors.Is(err, jetstream.ErrAsyncPublishTimeout)
+}
+
+func (p *Publisher) publish(ctx context.Context, msg *nats.Msg) (*jetstream.PubAck, error) {
+	future, err := p.js.PublishMsgAsync(msg)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	var timeout <-chan time.Time
+
+	if p.timeout > 0 {
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case ack := <-future.Ok():
+		return ack, nil
+	case err := <-future.Err():
+		return nil, unavailable(err)
+	case <-timeout:
+		return nil, unavailable(errPublishTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// unavailable marks err as exception.ErrEventBusUnavailable when it means
+// NATS can't take messages for now, the other errors are returned as is.
+func unavailable(err error) error {
+	switch {
+	case errors.Is(err, errPublishTimeout),
+		errors.Is(err, jetstream.ErrAsyncPublishTimeout),
+		errors.Is(err, jetstream.ErrTooManyStalledMsgs),
+		errors.Is(err, nats.ErrConnectionClosed),
+		errors.Is(err, nats.ErrConnectionDraining),
+		errors.Is(err, nats.ErrReconnectBufExceeded):
+		return fmt.Errorf("%w: %w", exception.ErrEventBusUnavailable, err)
+	default:
+		return err
+	}
 }
@@ -0,0 +1,134 @@
+//go:build unit
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/app/dto"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/exception"
+	"github.com/ijalalfrz/event-driven-nats/user-service/internal/pkg/transport/nats/jetstreamtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+	assert.NoError(t, err)
+
+	publisher := NewPublisher(js, JSONEncoder)
+
+	ack, err := publisher.Publish(dto.ContextWithRequestID(ctx, "req-1"), "user.created", map[string]int{"id": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &jetstream.PubAck{Stream: "events", Sequence: 1}, ack)
+
+	ack, err = publisher.Publish(ctx, "user.created", map[string]int{"id": 2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), ack.Sequence)
+
+	msgs := js.Messages("events")
+	assert.Len(t, msgs, 2)
+	assert.JSONEq(t, `{"id": 1}`, string(msgs[0].Data))
+	assert.Equal(t, "req-1", msgs[0].Header.Get(dto.RequestIDHeader))
+	assert.Empty(t, msgs[1].Header.Get(dto.RequestIDHeader))
+}
+
+func TestPublisher_PublishErrors(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+	publisher := NewPublisher(js, JSONEncoder)
+
+	_, err := publisher.Publish(ctx, "user.created", struct{}{})
+	assert.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+	assert.NoError(t, err)
+
+	js.FailPublish(errors.New("nats: connection closed"))
+
+	_, err = publisher.Publish(ctx, "user.created", struct{}{})
+	assert.EqualError(t, err, "nats: connection closed")
+
+	_, err = publisher.Publish(ctx, "user.created", make(chan int))
+	assert.Error(t, err)
+	assert.Empty(t, js.Messages("events"))
+}
+
+func TestPublisher_PublishUnavailable(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+	assert.NoError(t, err)
+
+	publisher := NewPublisher(js, JSONEncoder, WithPublishTimeout(10*time.Millisecond))
+
+	js.HoldAcks(true)
+
+	_, err = publisher.Publish(ctx, "user.created", struct{}{})
+	assert.ErrorIs(t, err, exception.ErrEventBusUnavailable)
+	assert.Equal(t, http.StatusServiceUnavailable, exception.GetHTTPStatusCodeByErr(err))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = publisher.Publish(cancelled, "user.created", struct{}{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, exception.ErrEventBusUnavailable)
+
+	js.HoldAcks(false)
+	js.FailPublish(nats.ErrConnectionClosed)
+
+	_, err = publisher.Publish(ctx, "user.created", struct{}{})
+	assert.ErrorIs(t, err, exception.ErrEventBusUnavailable)
+	assert.ErrorIs(t, err, nats.ErrConnectionClosed)
+
+	js.FailPublish(jetstream.ErrTooManyStalledMsgs)
+
+	_, err = publisher.Publish(ctx, "user.created", struct{}{})
+	assert.ErrorIs(t, err, exception.ErrEventBusUnavailable)
+}
+
+func TestPublisher_PublishRetriesWithTheSameID(t *testing.T) {
+	ctx := context.Background()
+	js := jetstreamtest.New()
+
+	_, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "events", Subjects: []string{"user.>"}})
+	assert.NoError(t, err)
+
+	publisher := NewPublisher(js, JSONEncoder, WithPublishTimeout(10*time.Millisecond), WithPublishRetries(2))
+
+	// the first ack is lost, the retry is acked as a duplicate of the stored
+	// message
+	js.LoseAcks(1)
+
+	ack, err := publisher.Publish(ctx, "user.created", map[string]int{"id": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), ack.Sequence)
+	assert.True(t, ack.Duplicate)
+
+	msgs := js.Messages("events")
+	assert.Len(t, msgs, 1)
+	assert.NotEmpty(t, msgs[0].Header.Get(jetstream.MsgIDHeader))
+
+	// every ack is lost, the publication fails after the retries
+	js.LoseAcks(3)
+
+	_, err = publisher.Publish(ctx, "user.created", map[string]int{"id": 2})
+	assert.ErrorIs(t, err, exception.ErrEventBusUnavailable)
+	assert.Len(t, js.Messages("events"), 2)
+
+	// each publication has its own ID
+	ack, err = publisher.Publish(ctx, "user.created", map[string]int{"id": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), ack.Sequence)
+	assert.False(t, ack.Duplicate)
+}